@@ -0,0 +1,67 @@
+// Package cf 实现了 CF 约定中与格式无关的部分：时间单位、经纬度坐标识别、由坐标创建网格
+// 供 NetCDF、Zarr 等格式的读写共用
+package cf
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+)
+
+// ErrUnsupported 不支持的坐标或日历
+var ErrUnsupported = errors.New("unsupported CF convention")
+
+// 坐标轴
+const (
+	Latitude  = "latitude"
+	Longitude = "longitude"
+)
+
+var coordinateNames = map[string][]string{
+	Latitude:  {"latitude", "lat", "nav_lat", "y"},
+	Longitude: {"longitude", "lon", "nav_lon", "x"},
+}
+
+var coordinateUnits = map[string][]string{
+	Latitude:  {"degrees_north", "degree_north", "degree_n", "degrees_n", "degreen", "degreesn"},
+	Longitude: {"degrees_east", "degree_east", "degree_e", "degrees_e", "degreee", "degreese"},
+}
+
+// IsCoordinate 判断变量是否为 axis（Latitude 或 Longitude）坐标，依据变量名、standard_name 或 units
+func IsCoordinate(axis, name, standardName, units string) bool {
+	return slices.Contains(coordinateNames[axis], strings.ToLower(name)) ||
+		standardName == axis ||
+		slices.Contains(coordinateUnits[axis], strings.ToLower(units))
+}
+
+// dimensionAliases 常见的维度名，对应到 walg 的通用维度名
+var dimensionAliases = map[string]string{
+	"time":           grids.DimTime,
+	"valid_time":     grids.DimTime,
+	"level":          grids.DimLevel,
+	"lev":            grids.DimLevel,
+	"plev":           grids.DimLevel,
+	"pressure_level": grids.DimLevel,
+	"isobaricinhpa":  grids.DimLevel,
+	"member":         grids.DimMember,
+	"number":         grids.DimMember,
+	"realization":    grids.DimMember,
+	"ensemble":       grids.DimMember,
+}
+
+// DimensionName 将维度名对应到 grids.DimTime、grids.DimLevel、grids.DimMember
+// axis 为坐标变量的 axis 属性（T、Z），优先于维度名；无法识别时返回原名
+func DimensionName(name, axis string) string {
+	switch strings.ToUpper(axis) {
+	case "T":
+		return grids.DimTime
+	case "Z":
+		return grids.DimLevel
+	}
+	if alias, ok := dimensionAliases[strings.ToLower(name)]; ok {
+		return alias
+	}
+	return name
+}
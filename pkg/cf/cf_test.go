@@ -0,0 +1,64 @@
+package cf_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/scorix/walg/pkg/cf"
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGridFromCoordinates(t *testing.T) {
+	grid, mode, err := cf.GridFromCoordinates([]float64{-1, -0.5, 0}, []float64{359.5, 359, 358.5})
+	require.NoError(t, err)
+	assert.Equal(t, grids.ScanModeNegativeI|grids.ScanModePositiveJ, mode)
+	assert.Equal(t, []float64{0, -0.5, -1}, grid.Latitudes())
+	assert.Equal(t, []float64{358.5, 359, 359.5}, grid.Longitudes())
+
+	_, _, err = cf.GridFromCoordinates([]float64{0, 1, 3}, []float64{0, 1})
+	assert.ErrorIs(t, err, cf.ErrUnsupported)
+
+	_, _, err = cf.GridFromCoordinates([]float64{0, 1}, []float64{0, 2, 1})
+	assert.ErrorIs(t, err, cf.ErrUnsupported)
+}
+
+func TestParseTimeUnits(t *testing.T) {
+	tests := []struct {
+		units string
+		ref   time.Time
+		unit  time.Duration
+	}{
+		{"hours since 1900-01-01 00:00:00.0", time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC), time.Hour},
+		{"days since 2000-1-1", time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), 24 * time.Hour},
+		{"seconds since 1970-01-01T00:00:00Z", time.Unix(0, 0).UTC(), time.Second},
+		{"minutes since 2024-07-01 06:00 UTC", time.Date(2024, 7, 1, 6, 0, 0, 0, time.UTC), time.Minute},
+	}
+
+	for _, tt := range tests {
+		got, unit, err := cf.ParseTimeUnits(tt.units)
+		require.NoError(t, err, tt.units)
+		assert.Equal(t, tt.ref, got, tt.units)
+		assert.Equal(t, tt.unit, unit, tt.units)
+	}
+
+	_, _, err := cf.ParseTimeUnits("K")
+	assert.Error(t, err)
+}
+
+func TestTimes(t *testing.T) {
+	times, err := cf.Times([]float64{0, 1.5}, "days since 2024-07-01", "proleptic_gregorian")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 7, 2, 12, 0, 0, 0, time.UTC), times[1])
+
+	_, err = cf.Times([]float64{0}, "days since 2024-07-01", "noleap")
+	assert.ErrorIs(t, err, cf.ErrUnsupported)
+}
+
+func TestDimensionName(t *testing.T) {
+	assert.Equal(t, grids.DimLevel, cf.DimensionName("isobaricInhPa", ""))
+	assert.Equal(t, grids.DimMember, cf.DimensionName("number", ""))
+	assert.Equal(t, grids.DimTime, cf.DimensionName("t", "T"))
+	assert.Equal(t, "step", cf.DimensionName("step", ""))
+}
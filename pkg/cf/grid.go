@@ -0,0 +1,99 @@
+package cf
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/gaussian"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+)
+
+// GridFromCoordinates 根据一维纬度、经度坐标创建网格，返回 i 方向连续时的扫描方式
+// 坐标必须单调；等间距时为经纬度网格，纬度与规则高斯网格一致时为高斯网格；
+// 坐标先取整到 1e-4 度，以消除单精度存储带来的误差
+func GridFromCoordinates(lats, lons []float64) (grids.Grid, grids.ScanMode, error) {
+	if len(lats) < 2 || len(lons) < 2 {
+		return nil, 0, fmt.Errorf("%w: grid with fewer than 2 points in a direction", ErrUnsupported)
+	}
+
+	round := func(x float64) float64 { return math.Round(x*1e4) / 1e4 }
+	lats = slices.Clone(lats)
+	lons = slices.Clone(lons)
+	for i := range lats {
+		lats[i] = round(lats[i])
+	}
+	for i := range lons {
+		lons[i] = round(lons[i])
+	}
+
+	var mode grids.ScanMode
+	latStep, latRegular, ok := coordinateStep(lats)
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: latitudes are not monotonic", ErrUnsupported)
+	}
+	if lats[1] > lats[0] {
+		mode |= grids.ScanModePositiveJ
+	}
+
+	lonStep, lonRegular, ok := coordinateStep(lons)
+	if !ok || !lonRegular {
+		return nil, 0, fmt.Errorf("%w: longitudes are not regularly spaced", ErrUnsupported)
+	}
+	if lons[1] < lons[0] {
+		mode |= grids.ScanModeNegativeI
+	}
+
+	// 规则高斯网格的纬度间距接近相等，需要先于等间距判断
+	west, east := slices.Min(lons), slices.Max(lons)
+	if g, ok := gaussianGrid(lats, lons, west, lonStep); ok {
+		return g, mode, nil
+	}
+	if latRegular {
+		return latlon.NewLatLonGrid(slices.Min(lats), slices.Max(lats), west, east, latStep, lonStep), mode, nil
+	}
+
+	return nil, 0, fmt.Errorf("%w: latitudes are neither regular nor gaussian", ErrUnsupported)
+}
+
+// gaussianGrid 纬度与规则高斯网格一致时返回对应的网格
+func gaussianGrid(lats, lons []float64, west, lonStep float64) (grids.Grid, bool) {
+	n := len(lats) / 2
+	if len(lats) != 2*n || len(lons) != 4*n || west != 0 || math.Abs(lonStep-90/float64(n)) > 1e-4 {
+		return nil, false
+	}
+
+	sorted := slices.Clone(lats)
+	slices.Sort(sorted)
+	slices.Reverse(sorted)
+
+	g := gaussian.NewRegular(n)
+	for i, lat := range g.Latitudes() {
+		if math.Abs(lat-sorted[i]) > 1e-3 {
+			return nil, false
+		}
+	}
+	return g, true
+}
+
+// coordinateStep 返回坐标的平均间距（正数），以及是否等间距、是否严格单调
+func coordinateStep(values []float64) (float64, bool, bool) {
+	n := len(values)
+	step := (values[n-1] - values[0]) / float64(n-1)
+	if step == 0 {
+		return 0, false, false
+	}
+
+	regular := true
+	for i := 1; i < n; i++ {
+		d := values[i] - values[i-1]
+		if d*step <= 0 {
+			return 0, false, false
+		}
+		if math.Abs(d-step) > math.Max(1e-3*math.Abs(step), 2e-4) {
+			regular = false
+		}
+	}
+	return math.Round(math.Abs(step)*1e6) / 1e6, regular, true
+}
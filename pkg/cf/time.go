@@ -0,0 +1,100 @@
+package cf
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// timeLayouts CF 时间单位中参考时间的常见写法
+var timeLayouts = []string{
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"2006-1-2 15:04:05",
+	"2006-1-2",
+}
+
+// ParseTimeUnits 解析 CF 时间单位，例如 "hours since 1900-01-01 00:00:00"
+// 返回参考时间（UTC）和一个单位对应的时长
+func ParseTimeUnits(units string) (time.Time, time.Duration, error) {
+	unit, since, ok := strings.Cut(strings.TrimSpace(units), " since ")
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("not a time unit: %q", units)
+	}
+
+	var d time.Duration
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "days", "day", "d":
+		d = 24 * time.Hour
+	case "hours", "hour", "hrs", "hr", "h":
+		d = time.Hour
+	case "minutes", "minute", "mins", "min":
+		d = time.Minute
+	case "seconds", "second", "secs", "sec", "s":
+		d = time.Second
+	case "milliseconds", "millisecond", "msec", "ms":
+		d = time.Millisecond
+	default:
+		return time.Time{}, 0, fmt.Errorf("unsupported time unit %q", unit)
+	}
+
+	since = strings.TrimSpace(since)
+	// 去掉 " UTC" 和小数秒 ".0"
+	since = strings.TrimSuffix(since, " UTC")
+	if i := strings.LastIndex(since, "."); i > strings.LastIndex(since, ":") && i > 0 {
+		since = since[:i]
+	}
+
+	for _, layout := range timeLayouts {
+		if ref, err := time.Parse(layout, since); err == nil {
+			return ref.UTC(), d, nil
+		}
+	}
+	return time.Time{}, 0, fmt.Errorf("unsupported reference time %q", since)
+}
+
+// FormatTimeUnits 生成 CF 时间单位，例如 FormatTimeUnits(time.Hour, ref) 返回 "hours since 2024-07-01 00:00:00"
+func FormatTimeUnits(unit time.Duration, ref time.Time) (string, error) {
+	names := map[time.Duration]string{
+		24 * time.Hour: "days",
+		time.Hour:      "hours",
+		time.Minute:    "minutes",
+		time.Second:    "seconds",
+	}
+	name, ok := names[unit]
+	if !ok {
+		return "", fmt.Errorf("unsupported time unit %s", unit)
+	}
+	return name + " since " + ref.UTC().Format("2006-01-02 15:04:05"), nil
+}
+
+// Times 将时间坐标值按 CF 时间单位转换为时间
+// 只支持公历（standard、gregorian、proleptic_gregorian，calendar 为空时视为 standard），值四舍五入到毫秒
+func Times(values []float64, units, calendar string) ([]time.Time, error) {
+	ref, unit, err := ParseTimeUnits(units)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(calendar) {
+	case "", "standard", "gregorian", "proleptic_gregorian":
+	default:
+		return nil, fmt.Errorf("%w: calendar %s", ErrUnsupported, calendar)
+	}
+
+	times := make([]time.Time, len(values))
+	for i, x := range values {
+		if math.IsNaN(x) {
+			return nil, fmt.Errorf("missing time at %d", i)
+		}
+		ms := math.Round(x * float64(unit/time.Millisecond))
+		times[i] = ref.Add(time.Duration(ms) * time.Millisecond)
+	}
+	return times, nil
+}
@@ -0,0 +1,200 @@
+// Package flatstore 实现了 walg 自有的平面二进制场存储
+// 文件由定长前导、JSON 头和连续的数据组成：前导为 8 字节标识、2 字节版本、2 字节保留和 4 字节 JSON 头长度，
+// 数据从 64 字节对齐的位置开始，按小端序存放；读取时优先使用 mmap，也可以通过 io.ReaderAt 读取
+package flatstore
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/gaussian"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+)
+
+const (
+	magic   = "WALGFLAT"
+	version = 1
+
+	// preambleSize 前导的字节数
+	preambleSize = 16
+	// dataAlignment 数据起始位置的对齐字节数
+	dataAlignment = 64
+)
+
+var (
+	// ErrNotFlatStore 数据不是平面二进制场存储
+	ErrNotFlatStore = errors.New("not a walg flat store")
+	// ErrUnsupported 不支持的网格、数据类型或布局
+	ErrUnsupported = errors.New("unsupported flat store feature")
+	// ErrClosed 读取器已经关闭
+	ErrClosed = errors.New("flat store is closed")
+)
+
+// DType 数据的存储类型
+type DType string
+
+const (
+	Float32 DType = "float32"
+	Float64 DType = "float64"
+	// Int16 必须设置 Scale，-32768 表示缺测
+	Int16 DType = "int16"
+)
+
+// int16Missing Int16 的缺测值
+const int16Missing = math.MinInt16
+
+func (d DType) size() int {
+	switch d {
+	case Float32:
+		return 4
+	case Float64:
+		return 8
+	case Int16:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Layout 数据的排列方式
+type Layout string
+
+const (
+	// TimeMajor 按 [时间步][网格索引] 排列，读取整个场时是连续的
+	TimeMajor Layout = "time-major"
+	// PointMajor 按 [网格索引][时间步] 排列，读取一个点的时间序列时是连续的
+	PointMajor Layout = "point-major"
+)
+
+// GridSpec 网格的描述，Type 为 "latlon" 或 "gaussian"
+type GridSpec struct {
+	Type    string  `json:"type"`
+	N       int     `json:"n,omitempty"`
+	MinLat  float64 `json:"minLat,omitempty"`
+	MaxLat  float64 `json:"maxLat,omitempty"`
+	MinLon  float64 `json:"minLon,omitempty"`
+	MaxLon  float64 `json:"maxLon,omitempty"`
+	LatStep float64 `json:"latStep,omitempty"`
+	LonStep float64 `json:"lonStep,omitempty"`
+}
+
+// NewGridSpec 描述网格，只支持经纬度网格和规则高斯网格
+func NewGridSpec(grid grids.Grid) (GridSpec, error) {
+	if n, ok := gaussian.RegularNumber(grid); ok {
+		return GridSpec{Type: "gaussian", N: n}, nil
+	}
+	// 旋转网格等坐标不是地理经纬度的网格不能由经纬度描述重建
+	if _, ok := grid.(grids.GeographicLocator); ok {
+		return GridSpec{}, fmt.Errorf("%w: grid %T", ErrUnsupported, grid)
+	}
+
+	lats, lons := grid.Latitudes(), grid.Longitudes()
+	nj, ni := len(lats), len(lons)
+	if nj == 0 || ni == 0 {
+		return GridSpec{}, fmt.Errorf("%w: empty grid", ErrUnsupported)
+	}
+
+	spec := GridSpec{
+		Type:   "latlon",
+		MinLat: lats[nj-1],
+		MaxLat: lats[0],
+		MinLon: lons[0],
+		MaxLon: lons[ni-1],
+	}
+	if nj > 1 {
+		spec.LatStep = round((spec.MaxLat - spec.MinLat) / float64(nj-1))
+	}
+	if ni > 1 {
+		spec.LonStep = round((spec.MaxLon - spec.MinLon) / float64(ni-1))
+	}
+
+	// 只接受能由描述重建出相同坐标和类型的经纬度网格
+	g, err := spec.Grid()
+	if err != nil || reflect.TypeOf(g) != reflect.TypeOf(grid) || !sameCoordinates(g.Latitudes(), lats) || !sameCoordinates(g.Longitudes(), lons) {
+		return GridSpec{}, fmt.Errorf("%w: grid %T", ErrUnsupported, grid)
+	}
+	return spec, nil
+}
+
+func sameCoordinates(a, b []float64) bool {
+	return slices.EqualFunc(a, b, func(x, y float64) bool { return math.Abs(x-y) < 1e-6 })
+}
+
+func round(x float64) float64 {
+	return math.Round(x*1e6) / 1e6
+}
+
+// Grid 创建描述对应的网格
+func (s GridSpec) Grid() (grids.Grid, error) {
+	switch s.Type {
+	case "gaussian":
+		if s.N < 1 {
+			return nil, fmt.Errorf("invalid gaussian N %d", s.N)
+		}
+		return gaussian.NewRegular(s.N), nil
+	case "latlon":
+		if s.LatStep <= 0 || s.LonStep <= 0 {
+			return nil, fmt.Errorf("%w: latlon grid with a single row or column", ErrUnsupported)
+		}
+		return latlon.NewLatLonGrid(s.MinLat, s.MaxLat, s.MinLon, s.MaxLon, s.LatStep, s.LonStep), nil
+	default:
+		return nil, fmt.Errorf("%w: grid type %q", ErrUnsupported, s.Type)
+	}
+}
+
+// Header 存储的元数据，以 JSON 保存在文件头中
+// 物理值为 raw*Scale + Offset，Scale 为 0 时按 1 处理；浮点类型以 NaN 表示缺测
+type Header struct {
+	Grid     GridSpec       `json:"grid"`
+	ScanMode grids.ScanMode `json:"scanMode"`
+	Times    []time.Time    `json:"times"`
+	DType    DType          `json:"dtype"`
+	Scale    float64        `json:"scale,omitempty"`
+	Offset   float64        `json:"offset,omitempty"`
+	Layout   Layout         `json:"layout"`
+	Points   int            `json:"points"`
+}
+
+// validate 检查头部并返回数据的字节数
+func (h *Header) validate() (int64, error) {
+	if h.DType.size() == 0 {
+		return 0, fmt.Errorf("%w: dtype %q", ErrUnsupported, h.DType)
+	}
+	if h.Layout != TimeMajor && h.Layout != PointMajor {
+		return 0, fmt.Errorf("%w: layout %q", ErrUnsupported, h.Layout)
+	}
+	if h.DType == Int16 && h.Scale == 0 {
+		return 0, errors.New("int16 requires a non-zero scale")
+	}
+	if len(h.Times) == 0 || h.Points <= 0 {
+		return 0, errors.New("empty store")
+	}
+	return int64(len(h.Times)) * int64(h.Points) * int64(h.DType.size()), nil
+}
+
+// scale 返回有效的比例因子
+func (h *Header) scale() float64 {
+	if h.Scale == 0 {
+		return 1
+	}
+	return h.Scale
+}
+
+// offsetOf 返回 (timeStep, gridIndex) 在数据中的元素位置
+func (h *Header) offsetOf(timeStep, gridIndex int) int64 {
+	if h.Layout == PointMajor {
+		return int64(gridIndex)*int64(len(h.Times)) + int64(timeStep)
+	}
+	return int64(timeStep)*int64(h.Points) + int64(gridIndex)
+}
+
+// dataOffset 返回 JSON 头长度为 n 时数据的起始位置
+func dataOffset(n int) int64 {
+	end := int64(preambleSize + n)
+	return (end + dataAlignment - 1) / dataAlignment * dataAlignment
+}
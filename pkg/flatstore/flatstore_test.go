@@ -0,0 +1,175 @@
+package flatstore_test
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scorix/walg/pkg/flatstore"
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/gaussian"
+	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+	"github.com/scorix/walg/pkg/geo/grids/rotated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var ref = time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+// sourceReader 值为 timeStep*1000 + gridIndex/10，网格索引 3 缺测
+type sourceReader struct{}
+
+func (sourceReader) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	if gridIndex == 3 {
+		return math.NaN(), nil
+	}
+	return float64(timeStep*1000) + float64(gridIndex)/10, nil
+}
+
+func hourly(n int) []time.Time {
+	times := make([]time.Time, n)
+	for i := range times {
+		times[i] = ref.Add(time.Duration(i) * time.Hour)
+	}
+	return times
+}
+
+func TestWriteRead(t *testing.T) {
+	grid := latlon.NewLatLonGrid(-10, 10, 100, 120, 0.5, 0.5)
+	mode := grids.ScanModePositiveJ
+
+	tests := []struct {
+		opts      flatstore.Options
+		tolerance float64
+	}{
+		{flatstore.Options{}, 1e-3},
+		{flatstore.Options{DType: flatstore.Float64, Layout: flatstore.PointMajor}, 0},
+		{flatstore.Options{DType: flatstore.Int16, Scale: 0.1, Offset: 1000, Layout: flatstore.PointMajor}, 0.05},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%s", tt.opts.DType, tt.opts.Layout), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, flatstore.Write(&buf, sourceReader{}, grid, mode, hourly(3), tt.opts))
+
+			r, err := flatstore.NewReader(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+			assert.Same(t, grid, r.Grid())
+			assert.Equal(t, mode, r.ScanMode())
+			assert.Equal(t, 3, r.Len())
+			assert.Equal(t, ref.Add(2*time.Hour), r.TimeAxis().Time(2))
+
+			v, err := r.ReadValueAt(2, 1234)
+			require.NoError(t, err)
+			assert.InDelta(t, 2123.4, v, tt.tolerance)
+
+			v, err = r.ReadValueAt(1, 3)
+			require.NoError(t, err)
+			assert.True(t, math.IsNaN(v))
+
+			field := make([]float64, grid.Size())
+			require.NoError(t, r.ReadField(1, field))
+			assert.InDelta(t, 1000+float64(grid.Size()-1)/10, field[grid.Size()-1], tt.tolerance)
+
+			series := make([]float64, 3)
+			require.NoError(t, r.ReadSeries(50, series))
+			for step, v := range series {
+				assert.InDelta(t, float64(step*1000)+5, v, tt.tolerance)
+			}
+
+			dst := make([]float64, 2)
+			require.NoError(t, r.ReadValuesAt(0, []int{10, 20}, dst))
+			assert.InDeltaSlice(t, []float64{1, 2}, dst, tt.tolerance)
+
+			_, err = r.ReadValueAt(3, 0)
+			assert.Error(t, err)
+			_, err = r.ReadValueAt(0, grid.Size())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	grid := gaussian.NewRegular(8)
+	path := filepath.Join(t.TempDir(), "t2m.walg")
+
+	// 时间轴来自数据源
+	src := grids.WithTimeAxis(sourceReader{}, grids.TimeSteps(hourly(4)))
+
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, flatstore.Write(f, src, grid, 0, nil, flatstore.Options{Layout: flatstore.PointMajor}))
+	require.NoError(t, f.Close())
+
+	r, err := flatstore.Open(path)
+	require.NoError(t, err)
+	defer r.Close()
+
+	assert.Same(t, grid, r.Grid())
+	assert.Equal(t, "gaussian", r.Header().Grid.Type)
+	assert.Equal(t, 4, r.Len())
+
+	interp := grids.NewGridInterpolator(r, r.Grid(), r.ScanMode(), &interpolators.NearestInterpolator{})
+	lat, lon, _ := grids.GridPoint(grid, 100, 0)
+	v, err := interp.InterpolateAt(3, lat, lon)
+	require.NoError(t, err)
+	assert.InDelta(t, 3010, v, 1e-3)
+
+	// 关闭时等待正在进行的读取完成，之后的读取返回 ErrClosed
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			series := make([]float64, r.Len())
+			for i := range grid.Size() {
+				if err := r.ReadSeries(i, series); err != nil {
+					assert.ErrorIs(t, err, flatstore.ErrClosed)
+					return
+				}
+			}
+		}()
+	}
+	require.NoError(t, r.Close())
+	wg.Wait()
+
+	_, err = r.ReadValueAt(0, 0)
+	assert.ErrorIs(t, err, flatstore.ErrClosed)
+	assert.ErrorIs(t, r.ReadField(0, make([]float64, grid.Size())), flatstore.ErrClosed)
+	require.NoError(t, r.Close())
+}
+
+func TestErrors(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 0, 10, 1, 1)
+	var buf bytes.Buffer
+
+	err := flatstore.Write(&buf, sourceReader{}, rotated.NewRotated(grid, -40, 10, 0), 0, hourly(1), flatstore.Options{})
+	assert.ErrorIs(t, err, flatstore.ErrUnsupported)
+
+	err = flatstore.Write(&buf, sourceReader{}, grid, 0, hourly(1), flatstore.Options{DType: flatstore.Int16})
+	assert.Error(t, err)
+
+	err = flatstore.Write(&buf, sourceReader{}, grid, 0, hourly(2), flatstore.Options{DType: flatstore.Int16, Scale: 0.01})
+	assert.Error(t, err, "values out of int16 range")
+
+	err = flatstore.Write(&buf, sourceReader{}, grid, 0, nil, flatstore.Options{})
+	assert.Error(t, err, "no time axis")
+
+	_, err = flatstore.NewReader(bytes.NewReader([]byte("GRIB0000000000000000")))
+	assert.ErrorIs(t, err, flatstore.ErrNotFlatStore)
+
+	// 目标切片长度不足
+	buf.Reset()
+	require.NoError(t, flatstore.Write(&buf, sourceReader{}, grid, 0, hourly(2), flatstore.Options{}))
+	r, err := flatstore.NewReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Error(t, r.ReadField(0, make([]float64, grid.Size()-1)))
+	assert.Error(t, r.ReadSeries(0, make([]float64, 1)))
+	assert.Error(t, r.ReadValuesAt(0, []int{0, 1}, make([]float64, 1)))
+}
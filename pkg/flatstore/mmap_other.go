@@ -0,0 +1,13 @@
+//go:build !unix
+
+package flatstore
+
+import (
+	"errors"
+	"os"
+)
+
+// mmap 在不支持的平台上返回错误，Open 回退到通过文件读取
+func mmap(f *os.File, size int64) ([]byte, func() error, error) {
+	return nil, nil, errors.ErrUnsupported
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package flatstore
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmap 将整个文件映射到内存，返回的 unmap 释放映射
+func mmap(f *os.File, size int64) ([]byte, func() error, error) {
+	if size == 0 {
+		return nil, nil, syscall.EINVAL
+	}
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
@@ -0,0 +1,311 @@
+package flatstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+)
+
+// Reader 读取平面二进制场存储，实现 ValueReader、BatchValueReader 和 TimeAxisProvider
+// 数据通过 mmap 时读取不需要系统调用；可以被多个 goroutine 同时使用，Close 等待正在进行的读取完成
+type Reader struct {
+	header Header
+	grid   grids.Grid
+	offset int64 // 数据的起始位置
+
+	data []byte      // mmap 的整个文件，没有时为 nil
+	r    io.ReaderAt // data 为 nil 时使用
+	size int         // 每个值的字节数
+
+	mu     sync.RWMutex // 读取持有读锁，Close 持有写锁
+	closed bool
+	close  func() error
+}
+
+var (
+	_ grids.ValueReader      = (*Reader)(nil)
+	_ grids.BatchValueReader = (*Reader)(nil)
+)
+
+// Open 打开文件，优先使用 mmap，不支持时通过文件读取；使用完毕后需要调用 Close
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	data, unmap, err := mmap(f, info.Size())
+	if err != nil {
+		r, err := NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		r.close = f.Close
+		return r, nil
+	}
+	// 映射建立后文件可以关闭
+	f.Close()
+
+	r, err := newReader(bytes.NewReader(data), data)
+	if err != nil {
+		unmap()
+		return nil, err
+	}
+	r.close = unmap
+	return r, nil
+}
+
+// NewReader 通过 io.ReaderAt 读取，每次读取对应一次 ReadAt 调用
+func NewReader(r io.ReaderAt) (*Reader, error) {
+	return newReader(r, nil)
+}
+
+func newReader(r io.ReaderAt, data []byte) (*Reader, error) {
+	preamble := make([]byte, preambleSize)
+	if _, err := r.ReadAt(preamble, 0); err != nil {
+		return nil, fmt.Errorf("%w: read preamble: %w", ErrNotFlatStore, err)
+	}
+	if string(preamble[:len(magic)]) != magic {
+		return nil, ErrNotFlatStore
+	}
+	if v := binary.LittleEndian.Uint16(preamble[8:]); v != version {
+		return nil, fmt.Errorf("%w: version %d", ErrUnsupported, v)
+	}
+
+	n := binary.LittleEndian.Uint32(preamble[12:])
+	if n > 1<<30 {
+		return nil, fmt.Errorf("%w: header length %d", ErrNotFlatStore, n)
+	}
+	raw := make([]byte, n)
+	if _, err := r.ReadAt(raw, preambleSize); err != nil {
+		return nil, fmt.Errorf("%w: read header: %w", ErrNotFlatStore, err)
+	}
+
+	s := &Reader{r: r, data: data, offset: dataOffset(int(n))}
+	if err := json.Unmarshal(raw, &s.header); err != nil {
+		return nil, fmt.Errorf("%w: parse header: %w", ErrNotFlatStore, err)
+	}
+
+	length, err := s.header.validate()
+	if err != nil {
+		return nil, err
+	}
+	if s.grid, err = s.header.Grid.Grid(); err != nil {
+		return nil, err
+	}
+	if s.grid.Size() != s.header.Points {
+		return nil, fmt.Errorf("header has %d points for a grid of %d", s.header.Points, s.grid.Size())
+	}
+	if data != nil && int64(len(data)) < s.offset+length {
+		return nil, fmt.Errorf("%w: file has %d bytes, want %d", ErrNotFlatStore, len(data), s.offset+length)
+	}
+	s.size = s.header.DType.size()
+
+	return s, nil
+}
+
+// Close 释放 mmap 或关闭文件，NewReader 创建的读取器不需要关闭；关闭后读取返回 ErrClosed
+func (s *Reader) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.close == nil {
+		return nil
+	}
+	err := s.close()
+	s.close, s.data, s.r = nil, nil, nil
+	return err
+}
+
+// acquire 持有读锁直到返回的函数被调用，读取器已关闭时返回 ErrClosed
+func (s *Reader) acquire() (func(), error) {
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return nil, ErrClosed
+	}
+	return s.mu.RUnlock, nil
+}
+
+// Header 返回存储的元数据
+func (s *Reader) Header() Header {
+	return s.header
+}
+
+// Grid 返回存储的网格
+func (s *Reader) Grid() grids.Grid {
+	return s.grid
+}
+
+// ScanMode 返回网格索引的扫描方式
+func (s *Reader) ScanMode() grids.ScanMode {
+	return s.header.ScanMode
+}
+
+// Len 返回时间步个数
+func (s *Reader) Len() int {
+	return len(s.header.Times)
+}
+
+// TimeAxis 返回存储的时间轴
+func (s *Reader) TimeAxis() grids.TimeAxis {
+	return grids.TimeSteps(s.header.Times)
+}
+
+// read 读取从元素位置 off 开始的 len(dst) 个连续值，调用时需持有读锁
+func (s *Reader) read(off int64, dst []float64) error {
+	start := s.offset + off*int64(s.size)
+	length := len(dst) * s.size
+
+	var buf []byte
+	if s.data != nil {
+		buf = s.data[start : start+int64(length)]
+	} else {
+		buf = make([]byte, length)
+		if _, err := s.r.ReadAt(buf, start); err != nil {
+			return fmt.Errorf("read at %d: %w", start, err)
+		}
+	}
+
+	scale, offset := s.header.scale(), s.header.Offset
+	for i := range dst {
+		b := buf[i*s.size:]
+		switch s.header.DType {
+		case Float32:
+			dst[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))*scale + offset
+		case Float64:
+			dst[i] = math.Float64frombits(binary.LittleEndian.Uint64(b))*scale + offset
+		case Int16:
+			raw := int16(binary.LittleEndian.Uint16(b))
+			if raw == int16Missing {
+				dst[i] = math.NaN()
+			} else {
+				dst[i] = float64(raw)*scale + offset
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Reader) check(timeStep int) error {
+	if timeStep < 0 || timeStep >= len(s.header.Times) {
+		return fmt.Errorf("invalid time step: %d", timeStep)
+	}
+	return nil
+}
+
+func (s *Reader) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	if err := s.check(timeStep); err != nil {
+		return 0, err
+	}
+	if gridIndex < 0 || gridIndex >= s.header.Points {
+		return 0, fmt.Errorf("invalid grid index: %d", gridIndex)
+	}
+
+	release, err := s.acquire()
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	var dst [1]float64
+	if err := s.read(s.header.offsetOf(timeStep, gridIndex), dst[:]); err != nil {
+		return 0, err
+	}
+	return dst[0], nil
+}
+
+func (s *Reader) ReadValuesAt(timeStep int, indices []int, dst []float64) error {
+	if err := s.check(timeStep); err != nil {
+		return err
+	}
+	if len(dst) < len(indices) {
+		return fmt.Errorf("destination too short: %d < %d", len(dst), len(indices))
+	}
+
+	release, err := s.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	for i, idx := range indices {
+		if idx < 0 || idx >= s.header.Points {
+			return fmt.Errorf("invalid grid index: %d", idx)
+		}
+		if err := s.read(s.header.offsetOf(timeStep, idx), dst[i:i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadField 读取整个场，TimeMajor 布局时为一次连续读取
+func (s *Reader) ReadField(timeStep int, dst []float64) error {
+	if err := s.check(timeStep); err != nil {
+		return err
+	}
+	if len(dst) < s.header.Points {
+		return fmt.Errorf("destination too short: %d < %d", len(dst), s.header.Points)
+	}
+
+	release, err := s.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if s.header.Layout == TimeMajor {
+		return s.read(s.header.offsetOf(timeStep, 0), dst[:s.header.Points])
+	}
+	for i := range s.header.Points {
+		if err := s.read(s.header.offsetOf(timeStep, i), dst[i:i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadSeries 读取一个网格点在所有时间步的值，PointMajor 布局时为一次连续读取
+// dst 的长度不能小于时间步个数
+func (s *Reader) ReadSeries(gridIndex int, dst []float64) error {
+	if gridIndex < 0 || gridIndex >= s.header.Points {
+		return fmt.Errorf("invalid grid index: %d", gridIndex)
+	}
+
+	steps := len(s.header.Times)
+	if len(dst) < steps {
+		return fmt.Errorf("destination too short: %d < %d", len(dst), steps)
+	}
+
+	release, err := s.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if s.header.Layout == PointMajor {
+		return s.read(s.header.offsetOf(0, gridIndex), dst[:steps])
+	}
+	for t := range steps {
+		if err := s.read(s.header.offsetOf(t, gridIndex), dst[t:t+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
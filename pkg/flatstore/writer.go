@@ -0,0 +1,153 @@
+package flatstore
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+)
+
+// Options 写入选项，零值为 TimeMajor 布局的 Float32
+type Options struct {
+	DType  DType
+	Scale  float64
+	Offset float64
+	Layout Layout
+}
+
+// Write 将 src 中各个时间步的场写入 w
+// times 为 nil 时使用 src 提供的时间轴（见 grids.TimeAxisProvider）；
+// TimeMajor 布局逐个时间步写出，PointMajor 布局需要先在内存中读取全部数据
+func Write(w io.Writer, src grids.ValueReader, grid grids.Grid, mode grids.ScanMode, times []time.Time, opts Options) error {
+	if times == nil {
+		axis := grids.ReaderTimeAxis(src)
+		if axis == nil {
+			return errors.New("no time axis")
+		}
+		times = make([]time.Time, axis.Len())
+		for i := range times {
+			times[i] = axis.Time(i)
+		}
+	}
+
+	spec, err := NewGridSpec(grid)
+	if err != nil {
+		return err
+	}
+
+	h := Header{
+		Grid:     spec,
+		ScanMode: mode,
+		Times:    times,
+		DType:    opts.DType,
+		Scale:    opts.Scale,
+		Offset:   opts.Offset,
+		Layout:   opts.Layout,
+		Points:   grid.Size(),
+	}
+	if h.DType == "" {
+		h.DType = Float32
+	}
+	if h.Layout == "" {
+		h.Layout = TimeMajor
+	}
+	if _, err := h.validate(); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(&h)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	preamble := make([]byte, preambleSize)
+	copy(preamble, magic)
+	binary.LittleEndian.PutUint16(preamble[8:], version)
+	binary.LittleEndian.PutUint32(preamble[12:], uint32(len(raw)))
+	bw.Write(preamble)
+	bw.Write(raw)
+	bw.Write(make([]byte, dataOffset(len(raw))-int64(preambleSize+len(raw))))
+
+	batch := grids.AsBatchValueReader(src, h.Points)
+	buf := make([]byte, 0, h.Points*h.DType.size())
+
+	if h.Layout == TimeMajor {
+		field := make([]float64, h.Points)
+		for step := range times {
+			if err := readField(batch, step, field); err != nil {
+				return fmt.Errorf("read time step %d: %w", step, err)
+			}
+			if buf, err = h.encode(buf[:0], field); err != nil {
+				return fmt.Errorf("time step %d: %w", step, err)
+			}
+			bw.Write(buf)
+		}
+		return bw.Flush()
+	}
+
+	fields := make([][]float64, len(times))
+	for step := range fields {
+		fields[step] = make([]float64, h.Points)
+		if err := readField(batch, step, fields[step]); err != nil {
+			return fmt.Errorf("read time step %d: %w", step, err)
+		}
+	}
+
+	series := make([]float64, len(times))
+	for i := range h.Points {
+		for step := range series {
+			series[step] = fields[step][i]
+		}
+		if buf, err = h.encode(buf[:0], series); err != nil {
+			return fmt.Errorf("grid index %d: %w", i, err)
+		}
+		bw.Write(buf)
+	}
+	return bw.Flush()
+}
+
+// encode 将物理值按数据类型编码为小端序
+func (h *Header) encode(b []byte, values []float64) ([]byte, error) {
+	scale := h.scale()
+	for _, x := range values {
+		raw := (x - h.Offset) / scale
+		switch h.DType {
+		case Float32:
+			b = binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(raw)))
+		case Float64:
+			b = binary.LittleEndian.AppendUint64(b, math.Float64bits(raw))
+		case Int16:
+			v := int16(int16Missing)
+			if !math.IsNaN(raw) {
+				r := math.Round(raw)
+				if r <= int16Missing || r > math.MaxInt16 {
+					return nil, fmt.Errorf("value %g out of int16 range", x)
+				}
+				v = int16(r)
+			}
+			b = binary.LittleEndian.AppendUint16(b, uint16(v))
+		}
+	}
+	return b, nil
+}
+
+// readField 读取整个场，读取器不知道网格大小而不支持 ReadField 时按全部网格索引批量读取
+func readField(batch grids.BatchValueReader, timeStep int, dst []float64) error {
+	err := batch.ReadField(timeStep, dst)
+	if !errors.Is(err, grids.ErrFieldUnsupported) {
+		return err
+	}
+
+	indices := make([]int, len(dst))
+	for i := range indices {
+		indices[i] = i
+	}
+	return batch.ReadValuesAt(timeStep, indices, dst)
+}
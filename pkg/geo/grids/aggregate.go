@@ -0,0 +1,302 @@
+package grids
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// DeaccumulateReader 将自起报时刻起的累积量转换为相邻时间步之间的时段量
+// 第 0 个时间步保持原值，之后为当前时间步与前一时间步之差，负值（打包误差等）截断为 0
+type DeaccumulateReader interface {
+	ValueReader
+	TimeAxisProvider
+}
+
+// NewDeaccumulateReader 创建去累积读取器
+// r 实现了 BatchValueReader 时返回的读取器也实现 BatchValueReader
+func NewDeaccumulateReader(r ValueReader) DeaccumulateReader {
+	d := &deaccumulateReader{reader: r}
+	if batch, ok := r.(BatchValueReader); ok {
+		return &deaccumulateBatchReader{deaccumulateReader: d, batch: batch}
+	}
+	return d
+}
+
+// deaccumulateReader 逐点读取的去累积读取器
+type deaccumulateReader struct {
+	reader ValueReader
+}
+
+func (d *deaccumulateReader) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	v, err := d.reader.ReadValueAt(timeStep, gridIndex)
+	if err != nil || timeStep == 0 {
+		return v, err
+	}
+
+	prev, err := d.reader.ReadValueAt(timeStep-1, gridIndex)
+	if err != nil {
+		return 0, err
+	}
+
+	return math.Max(v-prev, 0), nil
+}
+
+// TimeAxis 返回底层读取器的时间轴，时段量的有效时间为时段结束时刻
+func (d *deaccumulateReader) TimeAxis() TimeAxis {
+	return ReaderTimeAxis(d.reader)
+}
+
+// deaccumulateBatchReader 底层读取器实现了 BatchValueReader 的去累积读取器
+type deaccumulateBatchReader struct {
+	*deaccumulateReader
+	batch BatchValueReader
+}
+
+// ReadValuesAt 批量读取相邻两个时间步后求差
+func (d *deaccumulateBatchReader) ReadValuesAt(timeStep int, indices []int, dst []float64) error {
+	if len(dst) < len(indices) {
+		return fmt.Errorf("destination too short: %d < %d", len(dst), len(indices))
+	}
+	if err := d.batch.ReadValuesAt(timeStep, indices, dst); err != nil || timeStep == 0 {
+		return err
+	}
+
+	prev := make([]float64, len(indices))
+	if err := d.batch.ReadValuesAt(timeStep-1, indices, prev); err != nil {
+		return err
+	}
+
+	for i := range indices {
+		dst[i] = math.Max(dst[i]-prev[i], 0)
+	}
+	return nil
+}
+
+// ReadField 读取相邻两个时间步的整个场后求差
+func (d *deaccumulateBatchReader) ReadField(timeStep int, dst []float64) error {
+	if err := d.batch.ReadField(timeStep, dst); err != nil || timeStep == 0 {
+		return err
+	}
+
+	prev := make([]float64, len(dst))
+	if err := d.batch.ReadField(timeStep-1, prev); err != nil {
+		return err
+	}
+
+	for i := range dst {
+		dst[i] = math.Max(dst[i]-prev[i], 0)
+	}
+	return nil
+}
+
+// Aggregation 时间聚合方法
+type Aggregation int
+
+const (
+	AggregateSum Aggregation = iota
+	AggregateMean
+	AggregateMin
+	AggregateMax
+)
+
+func (a Aggregation) String() string {
+	switch a {
+	case AggregateSum:
+		return "sum"
+	case AggregateMean:
+		return "mean"
+	case AggregateMin:
+		return "min"
+	case AggregateMax:
+		return "max"
+	default:
+		return fmt.Sprintf("Aggregation(%d)", int(a))
+	}
+}
+
+// reduce 对一组值聚合，NaN 被忽略，全部为 NaN 时返回 NaN
+func (a Aggregation) reduce(values []float64) float64 {
+	result, n := math.NaN(), 0
+	for _, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+
+		switch {
+		case n == 0:
+			result = v
+		case a == AggregateMin:
+			result = math.Min(result, v)
+		case a == AggregateMax:
+			result = math.Max(result, v)
+		default:
+			result += v
+		}
+		n++
+	}
+
+	if a == AggregateMean && n > 0 {
+		result /= float64(n)
+	}
+	return result
+}
+
+// AggregateReader 将若干输入时间步聚合为一个输出时间步的读取器
+// 第 i 个输出时间步由 Groups()[i] 中的输入时间步聚合而成
+type AggregateReader interface {
+	ValueReader
+	TimeAxisProvider
+	// Len 返回输出时间步个数
+	Len() int
+	// Groups 返回每个输出时间步对应的输入时间步
+	Groups() [][]int
+}
+
+// aggregateReader 逐点读取的聚合读取器
+type aggregateReader struct {
+	reader ValueReader
+	groups [][]int
+	agg    Aggregation
+	axis   TimeAxis
+}
+
+// NewAggregateReader 使用给定的时间步分组创建聚合读取器
+// r 实现了 BatchValueReader 时返回的读取器也实现 BatchValueReader
+func NewAggregateReader(r ValueReader, groups [][]int, agg Aggregation) AggregateReader {
+	return newAggregateReader(r, groups, agg, nil)
+}
+
+func newAggregateReader(r ValueReader, groups [][]int, agg Aggregation, axis TimeAxis) AggregateReader {
+	a := &aggregateReader{reader: r, groups: groups, agg: agg, axis: axis}
+	if batch, ok := r.(BatchValueReader); ok {
+		return &aggregateBatchReader{aggregateReader: a, batch: batch}
+	}
+	return a
+}
+
+// NewWindowReader 每 window 个连续时间步聚合为一个时间步，steps 为输入时间步个数
+// 不足 window 的最后一组被丢弃；底层读取器提供时间轴时，输出时间步的时间为每组最后一个时间步的时间
+func NewWindowReader(r ValueReader, steps, window int, agg Aggregation) AggregateReader {
+	var groups [][]int
+	for start := 0; window > 0 && start+window <= steps; start += window {
+		group := make([]int, window)
+		for i := range group {
+			group[i] = start + i
+		}
+		groups = append(groups, group)
+	}
+
+	var times TimeAxis
+	if axis := ReaderTimeAxis(r); axis != nil {
+		steps := make(TimeSteps, len(groups))
+		for i, g := range groups {
+			steps[i] = axis.Time(g[len(g)-1])
+		}
+		times = steps
+	}
+
+	return newAggregateReader(r, groups, agg, times)
+}
+
+// NewDailyReader 按 loc 时区的自然日聚合，输出时间步的时间为当日零点
+// periodEnding 为 true 时时间步表示截至该时刻的时段量（例如去累积后的降水），
+// 恰好位于零点的时间步归入前一天
+func NewDailyReader(r ValueReader, axis TimeAxis, loc *time.Location, agg Aggregation, periodEnding bool) AggregateReader {
+	var groups [][]int
+	var days TimeSteps
+
+	for step := 0; step < axis.Len(); step++ {
+		t := axis.Time(step).In(loc)
+		if periodEnding {
+			t = t.Add(-time.Nanosecond)
+		}
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+
+		if n := len(days); n > 0 && days[n-1].Equal(day) {
+			groups[n-1] = append(groups[n-1], step)
+			continue
+		}
+		days = append(days, day)
+		groups = append(groups, []int{step})
+	}
+
+	return newAggregateReader(r, groups, agg, days)
+}
+
+func (a *aggregateReader) Len() int {
+	return len(a.groups)
+}
+
+func (a *aggregateReader) Groups() [][]int {
+	return a.groups
+}
+
+// TimeAxis 返回输出时间步的时间轴，未知时返回 nil
+func (a *aggregateReader) TimeAxis() TimeAxis {
+	return a.axis
+}
+
+func (a *aggregateReader) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	if timeStep < 0 || timeStep >= len(a.groups) {
+		return 0, fmt.Errorf("invalid time step: %d", timeStep)
+	}
+
+	group := a.groups[timeStep]
+	values := make([]float64, len(group))
+	for i, step := range group {
+		v, err := a.reader.ReadValueAt(step, gridIndex)
+		if err != nil {
+			return 0, err
+		}
+		values[i] = v
+	}
+
+	return a.agg.reduce(values), nil
+}
+
+// aggregateBatchReader 底层读取器实现了 BatchValueReader 的聚合读取器
+type aggregateBatchReader struct {
+	*aggregateReader
+	batch BatchValueReader
+}
+
+// ReadValuesAt 对每个输入时间步批量读取后聚合
+func (a *aggregateBatchReader) ReadValuesAt(timeStep int, indices []int, dst []float64) error {
+	if len(dst) < len(indices) {
+		return fmt.Errorf("destination too short: %d < %d", len(dst), len(indices))
+	}
+	return a.read(timeStep, len(indices), dst, func(step int, values []float64) error {
+		return a.batch.ReadValuesAt(step, indices, values)
+	})
+}
+
+// ReadField 对每个输入时间步读取整个场后聚合
+func (a *aggregateBatchReader) ReadField(timeStep int, dst []float64) error {
+	return a.read(timeStep, len(dst), dst, a.batch.ReadField)
+}
+
+// read 用 read 读取输出时间步对应的每个输入时间步的 n 个值，聚合后写入 dst
+func (a *aggregateBatchReader) read(timeStep, n int, dst []float64, read func(step int, values []float64) error) error {
+	if timeStep < 0 || timeStep >= len(a.groups) {
+		return fmt.Errorf("invalid time step: %d", timeStep)
+	}
+
+	group := a.groups[timeStep]
+	values := make([][]float64, len(group))
+	for i, step := range group {
+		values[i] = make([]float64, n)
+		if err := read(step, values[i]); err != nil {
+			return err
+		}
+	}
+
+	buf := make([]float64, len(group))
+	for k := range n {
+		for i := range group {
+			buf[i] = values[i][k]
+		}
+		dst[k] = a.agg.reduce(buf)
+	}
+	return nil
+}
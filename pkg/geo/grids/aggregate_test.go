@@ -0,0 +1,115 @@
+package grids_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readSteps(t *testing.T, r grids.ValueReader, n int) []float64 {
+	t.Helper()
+	values := make([]float64, n)
+	for i := range values {
+		v, err := r.ReadValueAt(i, 0)
+		require.NoError(t, err)
+		values[i] = v
+	}
+	return values
+}
+
+func TestDeaccumulateReader(t *testing.T) {
+	acc := []float64{0, 1, 3, 3, 2.9, 5}
+	d := grids.NewDeaccumulateReader(&stepFuncReader{f: func(step int) float64 { return acc[step] }})
+
+	assert.InDeltaSlice(t, []float64{0, 1, 2, 0, 0, 2.1}, readSteps(t, d, len(acc)), 1e-9)
+
+	// 只有底层读取器支持批量读取时，去累积读取器才实现 BatchValueReader
+	_, ok := d.(grids.BatchValueReader)
+	assert.False(t, ok)
+
+	fr := &fieldReader{fields: [][]float64{{1, 2}, {4, 1}}}
+	batch, ok := grids.NewDeaccumulateReader(grids.AsValueReader(fr)).(grids.BatchValueReader)
+	require.True(t, ok)
+
+	dst := make([]float64, 2)
+	require.NoError(t, batch.ReadField(1, dst))
+	assert.Equal(t, []float64{3, 0}, dst)
+	require.NoError(t, batch.ReadValuesAt(1, []int{1, 0}, dst))
+	assert.Equal(t, []float64{0, 3}, dst)
+	assert.Error(t, batch.ReadValuesAt(1, []int{1, 0}, dst[:1]))
+}
+
+func TestWindowReader(t *testing.T) {
+	values := []float64{1, 4, 2, math.NaN(), 3, 5, 7}
+	r := grids.WithTimeAxis(&stepFuncReader{f: func(step int) float64 { return values[step] }}, threeHourly(len(values)))
+
+	tests := []struct {
+		agg  grids.Aggregation
+		want []float64
+	}{
+		{agg: grids.AggregateSum, want: []float64{7, 8}},
+		{agg: grids.AggregateMean, want: []float64{7.0 / 3, 4}},
+		{agg: grids.AggregateMin, want: []float64{1, 3}},
+		{agg: grids.AggregateMax, want: []float64{4, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.agg.String(), func(t *testing.T) {
+			w := grids.NewWindowReader(r, len(values), 3, tt.agg)
+			require.Equal(t, 2, w.Len())
+			assert.InDeltaSlice(t, tt.want, readSteps(t, w, w.Len()), 1e-9)
+
+			axis := w.TimeAxis()
+			require.NotNil(t, axis)
+			assert.Equal(t, threeHourly(len(values))[5], axis.Time(1))
+		})
+	}
+
+	_, err := grids.NewWindowReader(r, len(values), 3, grids.AggregateSum).ReadValueAt(2, 0)
+	assert.Error(t, err)
+
+	_, ok := grids.NewWindowReader(r, len(values), 3, grids.AggregateSum).(grids.BatchValueReader)
+	assert.False(t, ok)
+
+	fr := &fieldReader{fields: [][]float64{{1, 2}, {4, 1}}}
+	batch, ok := grids.NewWindowReader(grids.AsValueReader(fr), 2, 2, grids.AggregateMax).(grids.BatchValueReader)
+	require.True(t, ok)
+
+	dst := make([]float64, 2)
+	require.NoError(t, batch.ReadField(0, dst))
+	assert.Equal(t, []float64{4, 2}, dst)
+	require.NoError(t, batch.ReadValuesAt(0, []int{1}, dst))
+	assert.Equal(t, 2.0, dst[0])
+	assert.Error(t, batch.ReadField(1, dst))
+	assert.Error(t, batch.ReadValuesAt(0, []int{0, 1}, dst[:1]))
+}
+
+func TestDailyReader(t *testing.T) {
+	// 2024-07-01 00:00 UTC 起逐 3 小时，共 3 天
+	axis := threeHourly(25)
+	hourly := &stepFuncReader{f: func(step int) float64 { return float64(step) }}
+	loc := time.FixedZone("UTC+8", 8*3600)
+
+	d := grids.NewDailyReader(hourly, axis, loc, grids.AggregateMax, false)
+	// 北京时间 07-01 08:00 开始，07-01 只有 16 点前的 6 个时间步
+	require.Equal(t, 4, d.Len())
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5}, d.Groups()[0])
+	assert.Equal(t, time.Date(2024, 7, 2, 0, 0, 0, 0, loc), d.TimeAxis().Time(1))
+	assert.Equal(t, []float64{5, 13, 21, 24}, readSteps(t, d, d.Len()))
+
+	// UTC+9 的零点为 15:00 UTC，对应第 5 个时间步
+	loc = time.FixedZone("UTC+9", 9*3600)
+	d = grids.NewDailyReader(hourly, axis, loc, grids.AggregateSum, false)
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, d.Groups()[0])
+	assert.Equal(t, 5, d.Groups()[1][0])
+
+	// 时段量：恰好位于零点的时间步归入前一天
+	d = grids.NewDailyReader(hourly, axis, loc, grids.AggregateSum, true)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5}, d.Groups()[0])
+	assert.Equal(t, []int{6, 7, 8, 9, 10, 11, 12, 13}, d.Groups()[1])
+	assert.Equal(t, 15.0, readSteps(t, d, 1)[0])
+}
@@ -0,0 +1,142 @@
+package grids
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BatchValueReader 定义了批量获取网格数据的接口
+// 按点读取时每个网格点往往对应一次系统调用或解码，批量接口允许实现一次性读取或解码
+// GridInterpolator、InterpolationPlan 等会优先使用该接口
+type BatchValueReader interface {
+	// ReadValuesAt 读取指定时间步一组网格索引的值，结果按顺序写入 dst
+	// dst 的长度不能小于 indices 的长度
+	ReadValuesAt(timeStep int, indices []int, dst []float64) error
+	// ReadField 读取指定时间步整个场的值，按网格索引顺序写入 dst
+	// dst 的长度不能小于网格点数
+	ReadField(timeStep int, dst []float64) error
+}
+
+// ErrFieldUnsupported 读取器无法读取整个场：底层读取器未实现 BatchValueReader，且网格点数未知
+var ErrFieldUnsupported = errors.New("reader does not support reading whole fields")
+
+// AsBatchValueReader 将 ValueReader 转换为 BatchValueReader
+// r 本身已实现批量接口时直接返回，否则逐点读取；gridSize 为 ReadField 读取的网格点数，
+// 不大于 0 时 ReadField 返回 ErrFieldUnsupported
+func AsBatchValueReader(r ValueReader, gridSize int) BatchValueReader {
+	if a, ok := r.(*valueAdapter); ok {
+		return a.reader
+	}
+	if b, ok := r.(BatchValueReader); ok {
+		return b
+	}
+	return &batchAdapter{reader: r, size: gridSize}
+}
+
+// AsValueReader 将 BatchValueReader 转换为 ValueReader
+// b 本身已实现 ValueReader 时直接返回，否则每次读取一个点
+func AsValueReader(b BatchValueReader) ValueReader {
+	if a, ok := b.(*batchAdapter); ok {
+		return a.reader
+	}
+	if r, ok := b.(ValueReader); ok {
+		return r
+	}
+	return &valueAdapter{reader: b}
+}
+
+// batchAdapter 逐点读取实现批量接口
+type batchAdapter struct {
+	reader ValueReader
+	size   int
+}
+
+func (a *batchAdapter) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	return a.reader.ReadValueAt(timeStep, gridIndex)
+}
+
+func (a *batchAdapter) ReadValuesAt(timeStep int, indices []int, dst []float64) error {
+	if len(dst) < len(indices) {
+		return fmt.Errorf("destination too short: %d < %d", len(dst), len(indices))
+	}
+
+	for i, idx := range indices {
+		v, err := a.reader.ReadValueAt(timeStep, idx)
+		if err != nil {
+			return err
+		}
+		dst[i] = v
+	}
+
+	return nil
+}
+
+func (a *batchAdapter) ReadField(timeStep int, dst []float64) error {
+	return readField(a.reader, timeStep, a.size, dst)
+}
+
+// valueAdapter 用批量接口实现逐点读取
+type valueAdapter struct {
+	reader BatchValueReader
+}
+
+func (a *valueAdapter) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	var dst [1]float64
+	if err := a.reader.ReadValuesAt(timeStep, []int{gridIndex}, dst[:]); err != nil {
+		return 0, err
+	}
+	return dst[0], nil
+}
+
+func (a *valueAdapter) ReadValuesAt(timeStep int, indices []int, dst []float64) error {
+	return a.reader.ReadValuesAt(timeStep, indices, dst)
+}
+
+func (a *valueAdapter) ReadField(timeStep int, dst []float64) error {
+	return a.reader.ReadField(timeStep, dst)
+}
+
+// readValues 读取一组网格索引的值，reader 实现了 BatchValueReader 时一次性读取
+func readValues(reader ValueReader, timeStep int, indices []int, dst []float64) error {
+	if len(dst) < len(indices) {
+		return fmt.Errorf("destination too short: %d < %d", len(dst), len(indices))
+	}
+
+	if b, ok := reader.(BatchValueReader); ok {
+		return b.ReadValuesAt(timeStep, indices, dst)
+	}
+
+	for i, idx := range indices {
+		v, err := reader.ReadValueAt(timeStep, idx)
+		if err != nil {
+			return err
+		}
+		dst[i] = v
+	}
+
+	return nil
+}
+
+// readField 读取指定时间步整个场的值，reader 实现了 BatchValueReader 时直接读取，
+// 否则逐点读取 size 个网格点；size 不大于 0 表示网格点数未知，返回 ErrFieldUnsupported
+func readField(reader ValueReader, timeStep, size int, dst []float64) error {
+	if b, ok := reader.(BatchValueReader); ok {
+		return b.ReadField(timeStep, dst)
+	}
+	if size <= 0 {
+		return ErrFieldUnsupported
+	}
+	if len(dst) < size {
+		return fmt.Errorf("destination too short: %d < %d", len(dst), size)
+	}
+
+	for i := 0; i < size; i++ {
+		v, err := reader.ReadValueAt(timeStep, i)
+		if err != nil {
+			return err
+		}
+		dst[i] = v
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package grids_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fieldReader 以切片保存整个场，只实现批量接口，并统计调用次数
+type fieldReader struct {
+	fields     [][]float64
+	batchCalls int
+}
+
+func (r *fieldReader) ReadValuesAt(timeStep int, indices []int, dst []float64) error {
+	r.batchCalls++
+	if timeStep < 0 || timeStep >= len(r.fields) {
+		return fmt.Errorf("invalid time step: %d", timeStep)
+	}
+	for i, idx := range indices {
+		if idx < 0 || idx >= len(r.fields[timeStep]) {
+			return fmt.Errorf("invalid grid index: %d", idx)
+		}
+		dst[i] = r.fields[timeStep][idx]
+	}
+	return nil
+}
+
+func (r *fieldReader) ReadField(timeStep int, dst []float64) error {
+	r.batchCalls++
+	if timeStep < 0 || timeStep >= len(r.fields) {
+		return fmt.Errorf("invalid time step: %d", timeStep)
+	}
+	copy(dst, r.fields[timeStep])
+	return nil
+}
+
+func newFieldReader(grid grids.Grid, mode grids.ScanMode, steps int, f func(step int, lat, lon float64) float64) *fieldReader {
+	r := &fieldReader{}
+	for step := 0; step < steps; step++ {
+		field := make([]float64, grid.Size())
+		for idx := range field {
+			lat, lon, _ := grids.GridPoint(grid, idx, mode)
+			field[idx] = f(step, lat, lon)
+		}
+		r.fields = append(r.fields, field)
+	}
+	return r
+}
+
+func TestBatchValueReader_Adapters(t *testing.T) {
+	reader := &mockReader{values: map[int]float64{0: 10, 1: 20, 2: 15, 3: 25}}
+
+	batch := grids.AsBatchValueReader(reader, 4)
+	dst := make([]float64, 4)
+	require.NoError(t, batch.ReadValuesAt(0, []int{3, 0}, dst))
+	assert.Equal(t, []float64{25, 10}, dst[:2])
+
+	require.NoError(t, batch.ReadField(0, dst))
+	assert.Equal(t, []float64{10, 20, 15, 25}, dst)
+
+	assert.Error(t, batch.ReadField(0, make([]float64, 3)))
+	assert.Error(t, batch.ReadValuesAt(0, []int{0, 1}, make([]float64, 1)))
+	assert.Error(t, batch.ReadValuesAt(0, []int{9}, dst))
+
+	// 网格点数未知时无法读取整个场
+	assert.ErrorIs(t, grids.AsBatchValueReader(reader, 0).ReadField(0, dst), grids.ErrFieldUnsupported)
+
+	// 已经实现批量接口的读取器原样返回
+	fr := &fieldReader{fields: [][]float64{{1, 2, 3}}}
+	assert.Same(t, fr, grids.AsBatchValueReader(grids.AsValueReader(fr), 3))
+
+	single := grids.AsValueReader(fr)
+	v, err := single.ReadValueAt(0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, v)
+
+	_, err = single.ReadValueAt(0, 5)
+	assert.Error(t, err)
+}
+
+func TestGridInterpolator_PrefersBatchReader(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	fr := newFieldReader(grid, 0, 2, func(step int, lat, lon float64) float64 {
+		return lat + lon + float64(step)
+	})
+
+	gi := grids.NewGridInterpolator(grids.AsValueReader(fr), grid, 0, nil)
+	got, err := gi.InterpolateAt(1, 5.5, 105.5)
+	require.NoError(t, err)
+	assert.InDelta(t, 112.0, got, 1e-9)
+	assert.Equal(t, 1, fr.batchCalls)
+}
+
+func TestInterpolationPlan_PrefersBatchReader(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	fr := newFieldReader(grid, 0, 1, func(step int, lat, lon float64) float64 {
+		return lat * lon
+	})
+
+	points := []grids.Point{{Lat: 1.5, Lon: 101.5}, {Lat: 2.25, Lon: 108}, {Lat: 9, Lon: 100.1}}
+	plan := grids.NewInterpolationPlan(grid, 0, nil, points)
+
+	dst := make([]float64, len(points))
+	require.NoError(t, plan.Apply(grids.AsValueReader(fr), 0, dst))
+	assert.Equal(t, 1, fr.batchCalls)
+
+	for i, pt := range points {
+		assert.InDelta(t, pt.Lat*pt.Lon, dst[i], 1e-9)
+	}
+}
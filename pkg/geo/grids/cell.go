@@ -0,0 +1,112 @@
+package grids
+
+import "math"
+
+// Cell 包含目标点的网格单元格
+// 四个角点为 (LatIdx, LonIdx)、(LatIdx, NextLonIdx)、(NextLatIdx, LonIdx)、(NextLatIdx, NextLonIdx)，
+// 与双线性插值中 points[0..3] 的位置一致
+type Cell struct {
+	LatIdx     int // 单元格第一个角点的纬度下标
+	LonIdx     int // 单元格第一个角点的经度下标
+	NextLatIdx int // 纬度方向相邻的下标，总是 LatIdx+1
+	NextLonIdx int // 经度方向相邻的下标，全球网格的最后一列之后循环为 0
+
+	LatFraction float64 // 目标点在纬度方向的相对位置，取值 [0, 1]
+	LonFraction float64 // 目标点在经度方向的相对位置，取值 [0, 1]
+}
+
+// CellLocator 由能够自行计算单元格的网格实现，例如坐标需要投影变换的网格
+// 未实现该接口的网格按 Latitudes 和 Longitudes 数组计算
+type CellLocator interface {
+	EnclosingCell(lat, lon float64) (Cell, bool)
+}
+
+// GeographicLocator 由坐标不是地理经纬度的网格实现，例如旋转经纬度网格
+// 这类网格的 Latitudes 和 Longitudes 为网格自身坐标系中的坐标，Geographic 返回网格点的地理坐标
+type GeographicLocator interface {
+	Geographic(latIdx, lonIdx int) (lat, lon float64)
+}
+
+// geographicPoint 返回网格点的地理坐标
+func geographicPoint(g Grid, latIdx, lonIdx int) (lat, lon float64) {
+	if l, ok := g.(GeographicLocator); ok {
+		return l.Geographic(latIdx, lonIdx)
+	}
+	return g.Latitudes()[latIdx], g.Longitudes()[lonIdx]
+}
+
+// EnclosingCell 返回包含目标点的网格单元格
+// 纬度、经度数组可以是升序或降序；全球网格在经度方向循环，最后一列与第一列构成一个单元格
+// 目标点位于网格范围之外时返回 false
+func EnclosingCell(g Grid, lat, lon float64) (Cell, bool) {
+	if l, ok := g.(CellLocator); ok {
+		return l.EnclosingCell(lat, lon)
+	}
+
+	lats := g.Latitudes()
+	lons := g.Longitudes()
+
+	latIdx, latFraction, ok := enclosingInterval(lat, lats)
+	if !ok {
+		return Cell{}, false
+	}
+
+	cell := Cell{
+		LatIdx:      latIdx,
+		NextLatIdx:  latIdx + 1,
+		LatFraction: latFraction,
+	}
+
+	if isSphere(g) {
+		// 经度规范到 [lons[0], lons[0]+360)，最后一列之后的部分属于跨越首尾的单元格
+		lon = lons[0] + math.Mod(math.Mod(lon-lons[0], 360)+360, 360)
+		last := len(lons) - 1
+		if lon >= lons[last] {
+			cell.LonIdx = last
+			cell.NextLonIdx = 0
+			cell.LonFraction = cellFraction(lon, lons[last], lons[0]+360)
+			return cell, true
+		}
+	}
+
+	for _, shift := range []float64{0, 360, -360} {
+		if lonIdx, lonFraction, ok := enclosingInterval(lon+shift, lons); ok {
+			cell.LonIdx = lonIdx
+			cell.NextLonIdx = lonIdx + 1
+			cell.LonFraction = lonFraction
+			return cell, true
+		}
+	}
+
+	return Cell{}, false
+}
+
+// enclosingInterval 在单调数组中查找包含 v 的区间 [axis[i], axis[i+1]]，返回 i 以及 v 在区间内的相对位置
+func enclosingInterval(v float64, axis []float64) (int, float64, bool) {
+	n := len(axis)
+	if n < 2 {
+		return 0, 0, false
+	}
+
+	ascending := axis[0] < axis[n-1]
+	lo, hi := axis[0], axis[n-1]
+	if !ascending {
+		lo, hi = hi, lo
+	}
+	if math.IsNaN(v) || v < lo || v > hi {
+		return 0, 0, false
+	}
+
+	indices := FindNearestIndices(v, axis)
+	i := indices[0]
+	if i >= n-1 {
+		i = n - 2
+	}
+
+	return i, cellFraction(v, axis[i], axis[i+1]), true
+}
+
+// cellFraction 计算 v 在 [v0, v1] 区间内的相对位置
+func cellFraction(v, v0, v1 float64) float64 {
+	return (v - v0) / (v1 - v0)
+}
@@ -0,0 +1,109 @@
+package grids_test
+
+import (
+	"testing"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/gaussian"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnclosingCell(t *testing.T) {
+	tests := []struct {
+		name string
+		grid grids.Grid
+		lat  float64
+		lon  float64
+		want grids.Cell
+		ok   bool
+	}{
+		{
+			// 纬度降序：第 4 行为 6°，第 5 行为 5°
+			name: "descending latitudes",
+			grid: latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1),
+			lat:  5.4,
+			lon:  105.6,
+			want: grids.Cell{LatIdx: 4, NextLatIdx: 5, LonIdx: 5, NextLonIdx: 6, LatFraction: 0.6, LonFraction: 0.6},
+			ok:   true,
+		},
+		{
+			name: "ascending latitudes",
+			grid: &mockGrid{lats: []float64{30, 31, 32}, lons: []float64{120, 121}},
+			lat:  31.25,
+			lon:  120.5,
+			want: grids.Cell{LatIdx: 1, NextLatIdx: 2, LonIdx: 0, NextLonIdx: 1, LatFraction: 0.25, LonFraction: 0.5},
+			ok:   true,
+		},
+		{
+			name: "exact grid point",
+			grid: latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1),
+			lat:  5,
+			lon:  105,
+			want: grids.Cell{LatIdx: 5, NextLatIdx: 6, LonIdx: 5, NextLonIdx: 6},
+			ok:   true,
+		},
+		{
+			name: "last row and column",
+			grid: latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1),
+			lat:  0,
+			lon:  110,
+			want: grids.Cell{LatIdx: 9, NextLatIdx: 10, LonIdx: 9, NextLonIdx: 10, LatFraction: 1, LonFraction: 1},
+			ok:   true,
+		},
+		{
+			name: "across dateline on global grid",
+			grid: latlon.NewLatLonGrid(-90, 90, 0, 359, 1, 1),
+			lat:  0,
+			lon:  -0.25,
+			want: grids.Cell{LatIdx: 90, NextLatIdx: 91, LonIdx: 359, NextLonIdx: 0, LatFraction: 0, LonFraction: 0.75},
+			ok:   true,
+		},
+		{
+			name: "regional grid with shifted longitude",
+			grid: latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1),
+			lat:  5,
+			lon:  -254.5,
+			want: grids.Cell{LatIdx: 5, NextLatIdx: 6, LonIdx: 5, NextLonIdx: 6, LonFraction: 0.5},
+			ok:   true,
+		},
+		{name: "outside latitude", grid: latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1), lat: 10.5, lon: 105},
+		{name: "outside longitude", grid: latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1), lat: 5, lon: 111},
+		{name: "poleward of gaussian rows", grid: gaussian.NewRegular(48), lat: 89, lon: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := grids.EnclosingCell(tt.grid, tt.lat, tt.lon)
+			require.Equal(t, tt.ok, ok)
+			if !ok {
+				return
+			}
+			assert.Equal(t, tt.want.LatIdx, got.LatIdx)
+			assert.Equal(t, tt.want.NextLatIdx, got.NextLatIdx)
+			assert.Equal(t, tt.want.LonIdx, got.LonIdx)
+			assert.Equal(t, tt.want.NextLonIdx, got.NextLonIdx)
+			assert.InDelta(t, tt.want.LatFraction, got.LatFraction, 1e-9)
+			assert.InDelta(t, tt.want.LonFraction, got.LonFraction, 1e-9)
+		})
+	}
+}
+
+func TestGridInterpolator_NoExtrapolation(t *testing.T) {
+	// 目标点最近的网格点位于其东南方，旧实现会以该点为锚点向外推
+	field := func(lat, lon float64) float64 { return lat*lat + lon*lon }
+
+	for _, mode := range []grids.ScanMode{0, grids.ScanModePositiveJ, grids.ScanModeNegativeI, grids.ScanModeConsecutiveJ} {
+		grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+		reader := &funcReader{grid: grid, mode: mode, f: field}
+		gi := grids.NewGridInterpolator(reader, grid, mode, nil)
+
+		got, err := gi.InterpolateAt(0, 5.4, 105.6)
+		require.NoError(t, err)
+
+		// 单元格 [5, 6] × [105, 106] 内的双线性插值
+		want := 25 + 0.4*(36-25) + 105*105 + 0.6*(106*106-105*105)
+		assert.InDelta(t, want, got, 1e-9, "mode %s", mode)
+	}
+}
@@ -0,0 +1,114 @@
+package grids
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+)
+
+// CoarsenReader 将细网格上的数据按块归并到粗网格上
+// 粗网格每个点对应细网格中落在其单元格范围内（相邻粗网格点的中点之间）的所有点，
+// 由 reducer 归并为一个值，例如分类数据使用 ModeInterpolator，连续量使用 AverageInterpolator
+type CoarsenReader struct {
+	reader  ValueReader
+	src     Grid
+	srcMode ScanMode
+	dst     Grid
+	dstMode ScanMode
+	reducer interpolators.Reducer
+
+	latBlocks [][]int // 粗网格每个纬度对应的细网格纬度下标
+	lonBlocks [][]int // 粗网格每个经度对应的细网格经度下标
+}
+
+// NewCoarsenReader 创建网格降采样读取器
+// reader 读取细网格 src 上的数据，返回的读取器按粗网格 dst 的网格索引提供数据
+func NewCoarsenReader(reader ValueReader, src Grid, srcMode ScanMode, dst Grid, dstMode ScanMode, reducer interpolators.Reducer) *CoarsenReader {
+	wrap := isSphere(dst)
+
+	return &CoarsenReader{
+		reader:    reader,
+		src:       src,
+		srcMode:   srcMode,
+		dst:       dst,
+		dstMode:   dstMode,
+		reducer:   reducer,
+		latBlocks: coarsenBlocks(src.Latitudes(), dst.Latitudes(), false),
+		lonBlocks: coarsenBlocks(src.Longitudes(), dst.Longitudes(), wrap),
+	}
+}
+
+// ReadValueAt 读取粗网格指定时间步和网格索引的值
+func (r *CoarsenReader) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	latIdx, lonIdx, ok := GridIndicesFromIndex(r.dst, gridIndex, r.dstMode)
+	if !ok {
+		return 0, fmt.Errorf("invalid grid index: %d", gridIndex)
+	}
+
+	indices := make([]int, 0, len(r.latBlocks[latIdx])*len(r.lonBlocks[lonIdx]))
+	for _, i := range r.latBlocks[latIdx] {
+		for _, j := range r.lonBlocks[lonIdx] {
+			indices = append(indices, GridIndexFromIndices(r.src, i, j, r.srcMode))
+		}
+	}
+
+	if len(indices) == 0 {
+		return math.NaN(), nil
+	}
+
+	values := make([]float64, len(indices))
+	if err := readValues(r.reader, timeStep, indices, values); err != nil {
+		return 0, err
+	}
+
+	return r.reducer.Reduce(values), nil
+}
+
+// coarsenBlocks 计算粗网格每个坐标对应的细网格坐标下标
+// 细网格坐标到最近的粗网格坐标归为一块；wrap 为 true 时按 360° 循环计算经度距离
+func coarsenBlocks(src, dst []float64, wrap bool) [][]int {
+	diff := func(a, b float64) float64 {
+		d := math.Abs(a - b)
+		if wrap {
+			d = math.Mod(d, 360)
+			d = math.Min(d, 360-d)
+		}
+		return d
+	}
+
+	// 粗网格单元格半宽，取与相邻点间距的一半
+	halfWidths := make([]float64, len(dst))
+	for i := range dst {
+		w := math.Inf(1)
+		if i > 0 {
+			w = math.Min(w, diff(dst[i], dst[i-1])/2)
+		}
+		if i < len(dst)-1 {
+			w = math.Min(w, diff(dst[i], dst[i+1])/2)
+		}
+		if math.IsInf(w, 1) {
+			w = 0
+		}
+		halfWidths[i] = w
+	}
+
+	blocks := make([][]int, len(dst))
+	for j, v := range src {
+		best := -1
+		for i := range dst {
+			d := diff(v, dst[i])
+			if d > halfWidths[i] {
+				continue
+			}
+			if best < 0 || d < diff(v, dst[best]) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			blocks[best] = append(blocks[best], j)
+		}
+	}
+
+	return blocks
+}
@@ -0,0 +1,65 @@
+package grids_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoarsenReader_Mode(t *testing.T) {
+	// 细网格 0.5°，粗网格 1°；恰好位于两个粗网格点中间的细网格点不共用，归入下标较小的一侧，
+	// 因此每个粗网格点在每个方向覆盖自身和下一个细网格点（最后一个只覆盖自身）
+	// 例如粗网格经度 102 覆盖 102 和 102.5，101.5 归入 101
+	src := latlon.NewLatLonGrid(0, 4, 100, 104, 0.5, 0.5)
+	dst := latlon.NewLatLonGrid(0, 4, 100, 104, 1, 1)
+
+	// 经度 >= 102 为类别 2，其余为类别 1
+	reader := &funcReader{grid: src, f: func(lat, lon float64) float64 {
+		if lon >= 102 {
+			return 2
+		}
+		return 1
+	}}
+
+	coarse := grids.NewCoarsenReader(reader, src, 0, dst, 0, &interpolators.ModeInterpolator{})
+
+	for idx := 0; idx < dst.Size(); idx++ {
+		lat, lon, ok := grids.GridPoint(dst, idx, 0)
+		require.True(t, ok)
+
+		got, err := coarse.ReadValueAt(0, idx)
+		require.NoError(t, err)
+
+		want := 1.0
+		if lon >= 102 {
+			want = 2.0
+		}
+		assert.Equal(t, want, got, "point (%f, %f)", lat, lon)
+	}
+
+	_, err := coarse.ReadValueAt(0, dst.Size())
+	assert.Error(t, err)
+}
+
+func TestCoarsenReader_AverageGlobal(t *testing.T) {
+	src := latlon.NewLatLonGrid(-90, 90, 0, 359, 1, 1)
+	dst := latlon.NewLatLonGrid(-90, 90, 0, 350, 10, 10)
+	mode := grids.ScanModePositiveJ
+
+	reader := &funcReader{grid: src, mode: mode, f: func(lat, lon float64) float64 {
+		// 359° 与 1° 对称，粗网格 0° 处的平均值应为 0
+		return math.Sin(lon * math.Pi / 180)
+	}}
+
+	coarse := grids.NewCoarsenReader(reader, src, mode, dst, mode, &interpolators.AverageInterpolator{})
+
+	idx := grids.GridIndexFromIndices(dst, 9, 0, mode)
+	got, err := coarse.ReadValueAt(0, idx)
+	require.NoError(t, err)
+	assert.InDelta(t, 0, got, 1e-10)
+}
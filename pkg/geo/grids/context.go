@@ -0,0 +1,177 @@
+package grids
+
+import (
+	"context"
+	"fmt"
+)
+
+// ContextValueReader 定义了可取消的网格数据读取接口
+type ContextValueReader interface {
+	// ReadValueAtContext 读取指定时间步和网格索引的值，ctx 取消后应尽快返回 ctx.Err()
+	ReadValueAtContext(ctx context.Context, timeStep, gridIndex int) (float64, error)
+}
+
+// AsContextValueReader 将 ValueReader 转换为 ContextValueReader
+// r 本身已实现 ContextValueReader 时直接返回，否则每次读取前检查 ctx 是否已取消
+func AsContextValueReader(r ValueReader) ContextValueReader {
+	if c, ok := r.(ContextValueReader); ok {
+		return c
+	}
+	return &contextAdapter{reader: r}
+}
+
+// BindContext 将 ContextValueReader 绑定到 ctx 上，作为 ValueReader 使用
+// 对不需要取消的场景可以传入 context.Background()；r 实现了 BatchValueReader 时返回的读取器保留批量读取
+func BindContext(ctx context.Context, r ContextValueReader) ValueReader {
+	if a, ok := r.(*contextAdapter); ok {
+		return bindContext(ctx, a.reader)
+	}
+	return (&boundReader{ctx: ctx, reader: r}).withBatch(r)
+}
+
+// contextAdapter 为普通 ValueReader 增加取消检查
+type contextAdapter struct {
+	reader ValueReader
+}
+
+func (a *contextAdapter) ReadValueAtContext(ctx context.Context, timeStep, gridIndex int) (float64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return a.reader.ReadValueAt(timeStep, gridIndex)
+}
+
+func (a *contextAdapter) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	return a.reader.ReadValueAt(timeStep, gridIndex)
+}
+
+// boundReader 绑定了 ctx 的读取器
+// 底层读取器实现了 ContextValueReader 时把 ctx 传下去，否则每次读取前检查 ctx；
+// 底层读取器实现了 BatchValueReader 时返回 boundBatchReader，保留批量读取
+type boundReader struct {
+	ctx    context.Context
+	reader ContextValueReader
+	plain  ValueReader
+}
+
+// bindContext 将任意 ValueReader 绑定到 ctx 上
+func bindContext(ctx context.Context, r ValueReader) ValueReader {
+	if r == nil {
+		return nil
+	}
+	b := &boundReader{ctx: ctx, plain: r}
+	if c, ok := r.(ContextValueReader); ok {
+		b.reader = c
+	}
+	return b.withBatch(r)
+}
+
+// withBatch r 实现了 BatchValueReader 时返回保留批量读取的 boundBatchReader
+func (b *boundReader) withBatch(r any) ValueReader {
+	if batch, ok := r.(BatchValueReader); ok {
+		return &boundBatchReader{boundReader: b, batch: batch}
+	}
+	return b
+}
+
+func (b *boundReader) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	if err := b.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if b.reader != nil {
+		return b.reader.ReadValueAtContext(b.ctx, timeStep, gridIndex)
+	}
+	return b.plain.ReadValueAt(timeStep, gridIndex)
+}
+
+// boundBatchReader 底层读取器实现了 BatchValueReader 的 boundReader
+type boundBatchReader struct {
+	*boundReader
+	batch BatchValueReader
+}
+
+func (b *boundBatchReader) ReadValuesAt(timeStep int, indices []int, dst []float64) error {
+	if err := b.ctx.Err(); err != nil {
+		return err
+	}
+	return b.batch.ReadValuesAt(timeStep, indices, dst)
+}
+
+func (b *boundBatchReader) ReadField(timeStep int, dst []float64) error {
+	if err := b.ctx.Err(); err != nil {
+		return err
+	}
+	return b.batch.ReadField(timeStep, dst)
+}
+
+// ContextInterpolator 定义了可取消的插值接口
+type ContextInterpolator interface {
+	InterpolateAtContext(ctx context.Context, timeStep int, lat, lon float64) (float64, error)
+}
+
+// withContext 返回读取器都绑定到 ctx 上的插值器副本
+func (g *GridInterpolator) withContext(ctx context.Context) *GridInterpolator {
+	gc := *g
+	gc.reader = bindContext(ctx, g.reader)
+	gc.magnitude = bindContext(ctx, g.magnitude)
+	if g.mask != nil {
+		gc.mask = &surfaceMask{reader: bindContext(ctx, g.mask.reader), radius: g.mask.radius}
+	}
+	return &gc
+}
+
+// InterpolateAtContext 在指定时间步和位置进行插值，ctx 取消后返回 ctx.Err()
+func (g *GridInterpolator) InterpolateAtContext(ctx context.Context, timeStep int, lat, lon float64) (float64, error) {
+	return g.withContext(ctx).InterpolateAt(timeStep, lat, lon)
+}
+
+// InterpolatePoints 在指定时间步对一组点插值，结果按顺序写入 dst
+// 每个点插值前检查 ctx，取消后返回 ctx.Err()
+func (g *GridInterpolator) InterpolatePoints(ctx context.Context, timeStep int, points []Point, dst []float64) error {
+	if len(dst) < len(points) {
+		return fmt.Errorf("destination too short: %d < %d", len(dst), len(points))
+	}
+
+	gc := g.withContext(ctx)
+	for i, pt := range points {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		v, err := gc.InterpolateAt(timeStep, pt.Lat, pt.Lon)
+		if err != nil {
+			return err
+		}
+		dst[i] = v
+	}
+
+	return nil
+}
+
+// InterpolateTimeSeries 对同一位置的一组时间步插值，结果按顺序写入 dst
+// 每个时间步插值前检查 ctx，取消后返回 ctx.Err()
+func (g *GridInterpolator) InterpolateTimeSeries(ctx context.Context, timeSteps []int, lat, lon float64, dst []float64) error {
+	if len(dst) < len(timeSteps) {
+		return fmt.Errorf("destination too short: %d < %d", len(dst), len(timeSteps))
+	}
+
+	indices, weights, err := g.neighbours(lat, lon)
+	if err != nil {
+		return err
+	}
+
+	gc := g.withContext(ctx)
+	for i, step := range timeSteps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		v, err := gc.interpolate(step, lat, lon, indices, weights, nil)
+		if err != nil {
+			return err
+		}
+		dst[i] = v
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package grids_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ctxReader 只实现 ContextValueReader 的读取器，读取到第 cancelAfter 次时取消 ctx
+type ctxReader struct {
+	funcReader
+	reads       int
+	cancelAfter int
+	cancel      context.CancelFunc
+}
+
+func (r *ctxReader) ReadValueAtContext(ctx context.Context, timeStep, gridIndex int) (float64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.reads++
+	if r.cancel != nil && r.reads == r.cancelAfter {
+		r.cancel()
+	}
+	return r.funcReader.ReadValueAt(timeStep, gridIndex)
+}
+
+// ctxFieldReader 同时实现 ContextValueReader 和 BatchValueReader 的读取器
+type ctxFieldReader struct {
+	*fieldReader
+}
+
+func (r ctxFieldReader) ReadValueAtContext(ctx context.Context, timeStep, gridIndex int) (float64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.fields[timeStep][gridIndex], nil
+}
+
+func TestContextValueReader_Adapters(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	plain := &funcReader{grid: grid, f: func(lat, lon float64) float64 { return lat + lon }}
+
+	c := grids.AsContextValueReader(plain)
+	v, err := c.ReadValueAtContext(context.Background(), 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 110.0, v)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.ReadValueAtContext(ctx, 0, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// 绑定后可以作为普通 ValueReader 使用
+	v, err = grids.BindContext(context.Background(), c).ReadValueAt(0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 110.0, v)
+
+	_, err = grids.BindContext(ctx, c).ReadValueAt(0, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// 只有底层读取器支持批量读取时，绑定后的读取器才实现 BatchValueReader
+	_, ok := grids.BindContext(context.Background(), c).(grids.BatchValueReader)
+	assert.False(t, ok)
+
+	fr := &fieldReader{fields: [][]float64{{1, 2, 3}}}
+	batch, ok := grids.BindContext(context.Background(), grids.AsContextValueReader(grids.AsValueReader(fr))).(grids.BatchValueReader)
+	require.True(t, ok)
+	dst := make([]float64, 3)
+	require.NoError(t, batch.ReadField(0, dst))
+	assert.Equal(t, []float64{1, 2, 3}, dst)
+	assert.ErrorIs(t, grids.BindContext(ctx, grids.AsContextValueReader(grids.AsValueReader(fr))).(grids.BatchValueReader).ReadField(0, dst), context.Canceled)
+
+	// 本身实现了 ContextValueReader 的批量读取器
+	cfr := ctxFieldReader{fieldReader: &fieldReader{fields: [][]float64{{4, 5, 6}}}}
+	batch, ok = grids.BindContext(context.Background(), cfr).(grids.BatchValueReader)
+	require.True(t, ok)
+	require.NoError(t, batch.ReadValuesAt(0, []int{2, 0}, dst))
+	assert.Equal(t, []float64{6, 4}, dst[:2])
+	assert.Equal(t, 1, cfr.batchCalls)
+	v, err = grids.BindContext(context.Background(), cfr).ReadValueAt(0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, v)
+	assert.ErrorIs(t, grids.BindContext(ctx, cfr).(grids.BatchValueReader).ReadField(0, dst), context.Canceled)
+}
+
+func TestGridInterpolator_InterpolateAtContext(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	reader := &ctxReader{funcReader: funcReader{grid: grid, f: func(lat, lon float64) float64 { return lat + lon }}}
+	gi := grids.NewGridInterpolator(reader, grid, 0, &interpolators.BilinearInterpolator{})
+
+	v, err := gi.InterpolateAtContext(context.Background(), 0, 5.5, 105.5)
+	require.NoError(t, err)
+	assert.InDelta(t, 111.0, v, 1e-9)
+	assert.Equal(t, 4, reader.reads, "context-aware reader should be used")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = gi.InterpolateAtContext(ctx, 0, 5.5, 105.5)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestGridInterpolator_InterpolatePointsCancel(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reader := &ctxReader{
+		funcReader:  funcReader{grid: grid, f: func(lat, lon float64) float64 { return lat + lon }},
+		cancelAfter: 6,
+		cancel:      cancel,
+	}
+	gi := grids.NewGridInterpolator(reader, grid, 0, &interpolators.BilinearInterpolator{})
+
+	points := []grids.Point{{Lat: 1.5, Lon: 101.5}, {Lat: 2.5, Lon: 102.5}, {Lat: 3.5, Lon: 103.5}}
+	dst := make([]float64, len(points))
+	err := gi.InterpolatePoints(ctx, 0, points, dst)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.InDelta(t, 103.0, dst[0], 1e-9)
+	assert.Equal(t, 6, reader.reads, "no reads after cancellation")
+
+	gi = grids.NewGridInterpolator(&funcReader{grid: grid, f: func(lat, lon float64) float64 { return lat + lon }}, grid, 0, &interpolators.BilinearInterpolator{})
+	require.NoError(t, gi.InterpolatePoints(context.Background(), 0, points, dst))
+	assert.InDeltaSlice(t, []float64{103, 105, 107}, dst, 1e-9)
+}
+
+func TestGridInterpolator_InterpolateTimeSeries(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	reader := newFieldReader(grid, 0, 3, func(step int, lat, lon float64) float64 { return float64(step)*10 + lat })
+	gi := grids.NewGridInterpolator(grids.AsValueReader(reader), grid, 0, &interpolators.BilinearInterpolator{})
+
+	dst := make([]float64, 3)
+	require.NoError(t, gi.InterpolateTimeSeries(context.Background(), []int{0, 1, 2}, 2.5, 105, dst))
+	assert.InDeltaSlice(t, []float64{2.5, 12.5, 22.5}, dst, 1e-9)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, gi.InterpolateTimeSeries(ctx, []int{0, 1, 2}, 2.5, 105, dst), context.Canceled)
+}
+
+func TestInterpolationPlan_ApplyContext(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	reader := newFieldReader(grid, 0, 1, func(step int, lat, lon float64) float64 { return lat })
+	plan := grids.NewInterpolationPlan(grid, 0, &interpolators.BilinearInterpolator{}, []grids.Point{{Lat: 2.5, Lon: 105}})
+
+	dst := make([]float64, 1)
+	require.NoError(t, plan.ApplyContext(context.Background(), grids.AsValueReader(reader), 0, dst))
+	assert.InDelta(t, 2.5, dst[0], 1e-9)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, plan.ApplyContext(ctx, grids.AsValueReader(reader), 0, dst), context.Canceled)
+}
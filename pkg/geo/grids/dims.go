@@ -0,0 +1,200 @@
+package grids
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+)
+
+// 常用维度名
+const (
+	DimTime   = "time"
+	DimLevel  = "level"
+	DimMember = "member"
+)
+
+// Dimension 命名维度
+// Coords 为坐标值（例如等压面 hPa、集合成员号），长度为维度大小；没有坐标时为 nil
+type Dimension struct {
+	Name   string
+	Size   int
+	Coords []float64
+	Units  string
+}
+
+// Index 返回坐标值为 coord 的索引，不存在时返回 false
+func (d Dimension) Index(coord float64) (int, bool) {
+	i := slices.Index(d.Coords, coord)
+	return i, i >= 0
+}
+
+// MultiValueReader 定义了按维度获取网格数据的接口
+// 数据按 (维度..., 网格点) 组织，网格点维度总是最后一维且不在 Dimensions 中
+type MultiValueReader interface {
+	// Dimensions 返回网格点以外的各个维度，顺序与 ReadValue 的 index 一致
+	Dimensions() []Dimension
+	// ReadValue 读取指定维度索引和网格索引的值
+	ReadValue(index []int, gridIndex int) (float64, error)
+}
+
+// Selection 按维度名选定索引，未选定的维度取 0
+type Selection map[string]int
+
+// indexOf 按 sel 生成维度索引
+func indexOf(dims []Dimension, sel Selection) ([]int, error) {
+	index := make([]int, len(dims))
+	for name, i := range sel {
+		k := slices.IndexFunc(dims, func(d Dimension) bool { return d.Name == name })
+		if k < 0 {
+			return nil, fmt.Errorf("unknown dimension: %s", name)
+		}
+		if i < 0 || i >= dims[k].Size {
+			return nil, fmt.Errorf("index %d out of range for dimension %s of size %d", i, name, dims[k].Size)
+		}
+		index[k] = i
+	}
+	return index, nil
+}
+
+// SliceReader 将多维读取器的一个二维切片作为 ValueReader 使用
+// along 维度对应 ReadValueAt 的 timeStep 参数，其余维度固定为 fixed 中的索引；
+// along 为 DimTime 且 r 实现了 TimeAxisProvider 时，返回的读取器同样提供时间轴
+func SliceReader(r MultiValueReader, along string, fixed Selection) (ValueReader, error) {
+	dims := r.Dimensions()
+	axis := slices.IndexFunc(dims, func(d Dimension) bool { return d.Name == along })
+	if axis < 0 {
+		return nil, fmt.Errorf("unknown dimension: %s", along)
+	}
+	if _, ok := fixed[along]; ok {
+		return nil, fmt.Errorf("dimension %s cannot be both fixed and sliced", along)
+	}
+
+	index, err := indexOf(dims, fixed)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &sliceReader{reader: r, index: index, axis: axis, size: dims[axis].Size}
+	if p, ok := r.(TimeAxisProvider); ok && along == DimTime {
+		return WithTimeAxis(s, p.TimeAxis()), nil
+	}
+	return s, nil
+}
+
+// sliceReader 多维读取器的二维切片
+type sliceReader struct {
+	reader MultiValueReader
+	index  []int
+	axis   int
+	size   int
+}
+
+func (s *sliceReader) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	if timeStep < 0 || timeStep >= s.size {
+		return 0, fmt.Errorf("invalid time step: %d", timeStep)
+	}
+
+	index := slices.Clone(s.index)
+	index[s.axis] = timeStep
+	return s.reader.ReadValue(index, gridIndex)
+}
+
+// AsMultiValueReader 将 ValueReader 作为只有时间维度的多维读取器使用
+func AsMultiValueReader(r ValueReader, timeSteps int) MultiValueReader {
+	if s, ok := r.(*sliceReader); ok && len(s.index) == 1 {
+		return s.reader
+	}
+	return &singleDimReader{reader: r, size: timeSteps}
+}
+
+// singleDimReader 只有时间维度的多维读取器
+type singleDimReader struct {
+	reader ValueReader
+	size   int
+}
+
+func (r *singleDimReader) Dimensions() []Dimension {
+	return []Dimension{{Name: DimTime, Size: r.size}}
+}
+
+func (r *singleDimReader) ReadValue(index []int, gridIndex int) (float64, error) {
+	if len(index) != 1 {
+		return 0, fmt.Errorf("expected 1 dimension index, got %d", len(index))
+	}
+	return r.reader.ReadValueAt(index[0], gridIndex)
+}
+
+// MultiInterpolator 按 (时间, 层次, 集合成员, 位置) 插值的多维插值器
+// 读取器必须有时间维度；读取器中不存在的层次、集合成员维度被忽略，对应参数必须为 0
+type MultiInterpolator struct {
+	reader       MultiValueReader
+	grid         Grid
+	scanningMode ScanMode
+	interpolator interpolators.Interpolator
+}
+
+// NewMultiInterpolator 创建多维插值器
+func NewMultiInterpolator(reader MultiValueReader, grid Grid, mode ScanMode, interp interpolators.Interpolator) *MultiInterpolator {
+	return &MultiInterpolator{reader: reader, grid: grid, scanningMode: mode, interpolator: interp}
+}
+
+// Interpolator 返回固定层次和集合成员、沿时间维度的二维插值器
+// 可以在返回的插值器上继续配置缺测值、海陆掩膜等
+func (m *MultiInterpolator) Interpolator(level, member int) (*GridInterpolator, error) {
+	sel := Selection{}
+	for _, d := range m.reader.Dimensions() {
+		switch d.Name {
+		case DimLevel:
+			sel[DimLevel] = level
+		case DimMember:
+			sel[DimMember] = member
+		}
+	}
+	if level != 0 {
+		if _, ok := sel[DimLevel]; !ok {
+			return nil, fmt.Errorf("reader has no %s dimension", DimLevel)
+		}
+	}
+	if member != 0 {
+		if _, ok := sel[DimMember]; !ok {
+			return nil, fmt.Errorf("reader has no %s dimension", DimMember)
+		}
+	}
+
+	r, err := SliceReader(m.reader, DimTime, sel)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewGridInterpolator(r, m.grid, m.scanningMode, m.interpolator), nil
+}
+
+// InterpolateAt 在指定时间步、层次、集合成员和位置进行插值
+func (m *MultiInterpolator) InterpolateAt(timeStep, level, member int, lat, lon float64) (float64, error) {
+	g, err := m.Interpolator(level, member)
+	if err != nil {
+		return 0, err
+	}
+	return g.InterpolateAt(timeStep, lat, lon)
+}
+
+// Members 返回指定时间步和层次上所有集合成员的插值结果
+func (m *MultiInterpolator) Members(timeStep, level int, lat, lon float64) ([]float64, error) {
+	dims := m.reader.Dimensions()
+	k := slices.IndexFunc(dims, func(d Dimension) bool { return d.Name == DimMember })
+	if k < 0 {
+		v, err := m.InterpolateAt(timeStep, level, 0, lat, lon)
+		return []float64{v}, err
+	}
+
+	values := make([]float64, dims[k].Size)
+	for i := range values {
+		v, err := m.InterpolateAt(timeStep, level, i, lat, lon)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
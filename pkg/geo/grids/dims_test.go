@@ -0,0 +1,121 @@
+package grids_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ensembleReader 维度为 (time, level, member) 的读取器，取值 time*100 + level*10 + member + lat
+type ensembleReader struct {
+	grid grids.Grid
+	dims []grids.Dimension
+	axis grids.TimeAxis
+}
+
+func newEnsembleReader(grid grids.Grid) *ensembleReader {
+	return &ensembleReader{
+		grid: grid,
+		dims: []grids.Dimension{
+			{Name: grids.DimTime, Size: 3},
+			{Name: grids.DimLevel, Size: 2, Coords: []float64{850, 500}, Units: "hPa"},
+			{Name: grids.DimMember, Size: 4},
+		},
+		axis: threeHourly(3),
+	}
+}
+
+func (r *ensembleReader) Dimensions() []grids.Dimension {
+	return r.dims
+}
+
+func (r *ensembleReader) TimeAxis() grids.TimeAxis {
+	return r.axis
+}
+
+func (r *ensembleReader) ReadValue(index []int, gridIndex int) (float64, error) {
+	for i, d := range r.dims {
+		if index[i] < 0 || index[i] >= d.Size {
+			return 0, fmt.Errorf("invalid %s index: %d", d.Name, index[i])
+		}
+	}
+	lat, _, ok := grids.GridPoint(r.grid, gridIndex, 0)
+	if !ok {
+		return 0, fmt.Errorf("invalid grid index: %d", gridIndex)
+	}
+	return float64(index[0]*100+index[1]*10+index[2]) + lat, nil
+}
+
+func TestSliceReader(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	reader := newEnsembleReader(grid)
+
+	level, ok := reader.dims[1].Index(500)
+	require.True(t, ok)
+
+	r, err := grids.SliceReader(reader, grids.DimTime, grids.Selection{grids.DimLevel: level, grids.DimMember: 3})
+	require.NoError(t, err)
+
+	idx := grids.GridIndexFromIndices(grid, 0, 0, 0)
+	lat, _, _ := grids.GridPoint(grid, idx, 0)
+	v, err := r.ReadValueAt(2, idx)
+	require.NoError(t, err)
+	assert.Equal(t, 213+lat, v)
+
+	_, err = r.ReadValueAt(3, idx)
+	assert.Error(t, err)
+
+	// 沿时间维度切片时保留时间轴
+	assert.Equal(t, reader.axis, grids.ReaderTimeAxis(r))
+
+	// 沿集合成员维度切片
+	r, err = grids.SliceReader(reader, grids.DimMember, grids.Selection{grids.DimTime: 1})
+	require.NoError(t, err)
+	v, err = r.ReadValueAt(2, idx)
+	require.NoError(t, err)
+	assert.Equal(t, 102+lat, v)
+	assert.Nil(t, grids.ReaderTimeAxis(r))
+
+	_, err = grids.SliceReader(reader, "height", nil)
+	assert.Error(t, err)
+	_, err = grids.SliceReader(reader, grids.DimTime, grids.Selection{grids.DimLevel: 2})
+	assert.Error(t, err)
+	_, err = grids.SliceReader(reader, grids.DimTime, grids.Selection{grids.DimTime: 0})
+	assert.Error(t, err)
+}
+
+func TestMultiInterpolator(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	m := grids.NewMultiInterpolator(newEnsembleReader(grid), grid, 0, &interpolators.BilinearInterpolator{})
+
+	v, err := m.InterpolateAt(1, 1, 2, 5.5, 105.5)
+	require.NoError(t, err)
+	assert.InDelta(t, 112+5.5, v, 1e-9)
+
+	members, err := m.Members(2, 0, 5.5, 105.5)
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{205.5, 206.5, 207.5, 208.5}, members, 1e-9)
+
+	// 时间插值沿用读取器提供的时间轴
+	g, err := m.Interpolator(0, 1)
+	require.NoError(t, err)
+	ti := grids.NewTemporalInterpolator(g, nil, grids.TemporalLinear)
+	v, err = ti.InterpolateAtTime(time.Date(2024, 7, 1, 4, 30, 0, 0, time.UTC), 5.5, 105.5)
+	require.NoError(t, err)
+	assert.InDelta(t, 150+1+5.5, v, 1e-9)
+
+	// 普通 ValueReader 只有时间维度
+	plain := grids.NewMultiInterpolator(grids.AsMultiValueReader(&funcReader{grid: grid, f: func(lat, lon float64) float64 { return lat }}, 1), grid, 0, nil)
+	v, err = plain.InterpolateAt(0, 0, 0, 5.5, 105.5)
+	require.NoError(t, err)
+	assert.InDelta(t, 5.5, v, 1e-9)
+
+	_, err = plain.InterpolateAt(0, 1, 0, 5.5, 105.5)
+	assert.Error(t, err)
+}
@@ -0,0 +1,345 @@
+package grids
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+	"golang.org/x/sync/errgroup"
+)
+
+// Engine 并行插值引擎，对一组点和一组时间步插值，结果写入稠密矩阵
+// 每个点的插值邻居只计算一次，每个时间步上一组点的相邻网格点一次批量读取；
+// 并发读取时读取器必须是并发安全的，否则应使用 WithSerializedReads 或 NewSyncReader 串行化访问
+type Engine struct {
+	interpolator *GridInterpolator
+	workers      int
+	serialize    bool
+}
+
+// NewEngine 创建并行插值引擎，workers 不大于 0 时使用 GOMAXPROCS
+func NewEngine(interp *GridInterpolator, workers int) *Engine {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	return &Engine{interpolator: interp, workers: workers}
+}
+
+// WithSerializedReads 用互斥锁串行化插值器所有读取器的访问，适用于非并发安全的读取器
+func (e *Engine) WithSerializedReads() *Engine {
+	e.serialize = true
+	return e
+}
+
+// CellError 记录结果矩阵中某个单元插值失败的原因
+type CellError struct {
+	Point    int
+	TimeStep int
+	Err      error
+}
+
+func (e *CellError) Error() string {
+	return fmt.Sprintf("point %d, time step %d: %v", e.Point, e.TimeStep, e.Err)
+}
+
+func (e *CellError) Unwrap() error {
+	return e.Err
+}
+
+// Result 并行插值结果
+// Values 按点优先排列，第 i 个点第 j 个时间步的值位于 i*len(TimeSteps)+j，失败的单元为 NaN
+type Result struct {
+	Points    []Point
+	TimeSteps []int
+	Values    []float64
+	Errors    []*CellError
+}
+
+// At 返回第 point 个点、第 step 个时间步的插值结果
+func (r *Result) At(point, step int) float64 {
+	return r.Values[point*len(r.TimeSteps)+step]
+}
+
+// Err 将所有单元错误合并为一个错误，没有错误时返回 nil
+func (r *Result) Err() error {
+	errs := make([]error, len(r.Errors))
+	for i, e := range r.Errors {
+		errs[i] = e
+	}
+	return errors.Join(errs...)
+}
+
+// Run 对 points × timeSteps 并行插值
+// 每个任务处理一个时间步上的一组点，这些点的相邻网格点通过 BatchValueReader 一次读取；
+// 单元插值失败不会中止其他单元，错误按点和时间步顺序记录在 Result.Errors 中；
+// 只有 ctx 被取消时才返回错误，此时结果中未完成的单元为 NaN
+func (e *Engine) Run(ctx context.Context, points []Point, timeSteps []int) (*Result, error) {
+	res := &Result{
+		Points:    points,
+		TimeSteps: timeSteps,
+		Values:    make([]float64, len(points)*len(timeSteps)),
+	}
+	for i := range res.Values {
+		res.Values[i] = math.NaN()
+	}
+
+	g := e.interpolator
+	if e.serialize {
+		g = g.serialized()
+	}
+	g = g.withContext(ctx)
+
+	var mu sync.Mutex
+	record := func(point, step int, err error) {
+		mu.Lock()
+		res.Errors = append(res.Errors, &CellError{Point: point, TimeStep: timeSteps[step], Err: err})
+		mu.Unlock()
+	}
+
+	// 每个点的插值邻居只计算一次
+	cells := make([]engineCell, len(points))
+	for i, pt := range points {
+		c := &cells[i]
+		c.indices, c.weights, c.err = g.neighbours(pt.Lat, pt.Lon)
+		if c.err != nil {
+			for j := range timeSteps {
+				record(i, j, c.err)
+			}
+		}
+	}
+
+	// 时间步少于工作协程时把点分成多组，保持并行度
+	chunk := len(points)
+	if n := len(points) * len(timeSteps); n > 0 {
+		chunk = min(len(points), max(1, (n+e.workers-1)/e.workers))
+	}
+
+	var eg errgroup.Group
+	eg.SetLimit(e.workers)
+
+schedule:
+	for j, step := range timeSteps {
+		for lo := 0; lo < len(points); lo += chunk {
+			if ctx.Err() != nil {
+				break schedule
+			}
+
+			hi := min(lo+chunk, len(points))
+			eg.Go(func() error {
+				return e.runChunk(ctx, g, res, cells, lo, hi, j, step, record)
+			})
+		}
+	}
+
+	err := eg.Wait()
+	slices.SortFunc(res.Errors, func(a, b *CellError) int {
+		if a.Point != b.Point {
+			return a.Point - b.Point
+		}
+		return a.TimeStep - b.TimeStep
+	})
+
+	if err != nil {
+		return res, err
+	}
+
+	return res, ctx.Err()
+}
+
+// engineCell 一个点的插值邻居，err 不为空表示该点无法插值
+type engineCell struct {
+	indices []int
+	weights []float64
+	err     error
+}
+
+// runChunk 对第 j 个时间步上第 lo 到 hi 个点插值
+// 先批量读取所有点的相邻网格点，批量读取失败时逐点插值，使错误只记录在出错的单元上
+func (e *Engine) runChunk(ctx context.Context, g *GridInterpolator, res *Result, cells []engineCell, lo, hi, j, step int, record func(point, step int, err error)) error {
+	var flat []int
+	for _, c := range cells[lo:hi] {
+		for _, idx := range c.indices {
+			if !isPoleIndex(idx) {
+				flat = append(flat, idx)
+			}
+		}
+	}
+
+	gc := *g
+	size := g.grid.Size()
+	if values, err := prefetch(g.reader, step, size, flat); err == nil {
+		gc.reader = values
+	} else if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if _, ok := g.interpolator.(interpolators.MagnitudeInterpolator); ok && g.magnitude != nil {
+		if values, err := prefetch(g.magnitude, step, size, flat); err == nil {
+			gc.magnitude = values
+		} else if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+	}
+
+	n := len(res.TimeSteps)
+	for i := lo; i < hi; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		c := cells[i]
+		if c.err != nil {
+			continue
+		}
+
+		pt := res.Points[i]
+		v, err := gc.interpolate(step, pt.Lat, pt.Lon, c.indices, c.weights, nil)
+		if err != nil {
+			record(i, j, err)
+			continue
+		}
+		res.Values[i*n+j] = v
+	}
+
+	return nil
+}
+
+// prefetchedReader 预先读取了一组网格索引的读取器，其他网格索引从底层读取器读取
+type prefetchedReader struct {
+	reader ValueReader
+	size   int // 网格点数
+	step   int
+	values map[int]float64
+}
+
+// prefetch 一次读取 step 时间步上 indices 的值
+func prefetch(reader ValueReader, step, size int, indices []int) (*prefetchedReader, error) {
+	values := make([]float64, len(indices))
+	if err := readValues(reader, step, indices, values); err != nil {
+		return nil, err
+	}
+
+	p := &prefetchedReader{reader: reader, size: size, step: step, values: make(map[int]float64, len(indices))}
+	for k, idx := range indices {
+		p.values[idx] = values[k]
+	}
+	return p, nil
+}
+
+func (p *prefetchedReader) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	if v, ok := p.values[gridIndex]; ok && timeStep == p.step {
+		return v, nil
+	}
+	return p.reader.ReadValueAt(timeStep, gridIndex)
+}
+
+func (p *prefetchedReader) ReadValuesAt(timeStep int, indices []int, dst []float64) error {
+	if len(dst) < len(indices) {
+		return fmt.Errorf("destination too short: %d < %d", len(dst), len(indices))
+	}
+
+	var rest, positions []int
+	for i, idx := range indices {
+		if v, ok := p.values[idx]; ok && timeStep == p.step {
+			dst[i] = v
+			continue
+		}
+		rest = append(rest, idx)
+		positions = append(positions, i)
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+
+	values := make([]float64, len(rest))
+	if err := readValues(p.reader, timeStep, rest, values); err != nil {
+		return err
+	}
+	for k, i := range positions {
+		dst[i] = values[k]
+	}
+	return nil
+}
+
+func (p *prefetchedReader) ReadField(timeStep int, dst []float64) error {
+	return readField(p.reader, timeStep, p.size, dst)
+}
+
+// serialized 返回所有读取器都被互斥锁保护的插值器副本
+func (g *GridInterpolator) serialized() *GridInterpolator {
+	gc := *g
+	gc.reader = NewSyncReader(g.reader)
+	if g.magnitude != nil {
+		gc.magnitude = NewSyncReader(g.magnitude)
+	}
+	if g.mask != nil {
+		gc.mask = &surfaceMask{reader: NewSyncReader(g.mask.reader), radius: g.mask.radius}
+	}
+	return &gc
+}
+
+// NewSyncReader 用互斥锁包装读取器，使非并发安全的读取器可以被多个 goroutine 同时使用
+// 底层读取器实现的 BatchValueReader 和 ContextValueReader 会被保留
+func NewSyncReader(r ValueReader) ValueReader {
+	switch r.(type) {
+	case *syncReader, *syncBatchReader:
+		return r
+	}
+
+	s := &syncReader{reader: r}
+	if batch, ok := r.(BatchValueReader); ok {
+		return &syncBatchReader{syncReader: s, batch: batch}
+	}
+	return s
+}
+
+// syncReader 串行化访问的读取器
+type syncReader struct {
+	mu     sync.Mutex
+	reader ValueReader
+}
+
+func (s *syncReader) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.reader.ReadValueAt(timeStep, gridIndex)
+}
+
+func (s *syncReader) ReadValueAtContext(ctx context.Context, timeStep, gridIndex int) (float64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.reader.(ContextValueReader); ok {
+		return c.ReadValueAtContext(ctx, timeStep, gridIndex)
+	}
+	return s.reader.ReadValueAt(timeStep, gridIndex)
+}
+
+// syncBatchReader 底层读取器实现了 BatchValueReader 的 syncReader
+type syncBatchReader struct {
+	*syncReader
+	batch BatchValueReader
+}
+
+func (s *syncBatchReader) ReadValuesAt(timeStep int, indices []int, dst []float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.batch.ReadValuesAt(timeStep, indices, dst)
+}
+
+func (s *syncBatchReader) ReadField(timeStep int, dst []float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.batch.ReadField(timeStep, dst)
+}
@@ -0,0 +1,170 @@
+package grids_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exclusiveReader 检测并发访问的读取器，模拟非并发安全的实现
+type exclusiveReader struct {
+	timeReader
+	inFlight   atomic.Int32
+	concurrent atomic.Bool
+}
+
+func (r *exclusiveReader) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	if r.inFlight.Add(1) > 1 {
+		r.concurrent.Store(true)
+	}
+	defer r.inFlight.Add(-1)
+
+	time.Sleep(time.Microsecond)
+	return r.timeReader.ReadValueAt(timeStep, gridIndex)
+}
+
+// stepReader 时间步为负时返回错误
+type stepReader struct {
+	timeReader
+}
+
+func (r *stepReader) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	if timeStep < 0 {
+		return 0, fmt.Errorf("invalid time step: %d", timeStep)
+	}
+	return r.timeReader.ReadValueAt(timeStep, gridIndex)
+}
+
+func TestEngine_Run(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	reader := &timeReader{grid: grid}
+	gi := grids.NewGridInterpolator(reader, grid, 0, &interpolators.BilinearInterpolator{})
+
+	var points []grids.Point
+	for i := 0; i < 20; i++ {
+		points = append(points, grids.Point{Lat: 0.3 + float64(i)*0.45, Lon: 100.2 + float64(i)*0.4})
+	}
+	steps := []int{0, 1, 2, 3}
+
+	res, err := grids.NewEngine(gi, 4).Run(context.Background(), points, steps)
+	require.NoError(t, err)
+	require.NoError(t, res.Err())
+	require.Len(t, res.Values, len(points)*len(steps))
+
+	for i, pt := range points {
+		for j, step := range steps {
+			want, err := gi.InterpolateAt(step, pt.Lat, pt.Lon)
+			require.NoError(t, err)
+			assert.InDelta(t, want, res.At(i, j), 1e-9, fmt.Sprintf("point %d, step %d", i, step))
+		}
+	}
+}
+
+func TestEngine_BatchReads(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	fields := make([][]float64, 2)
+	for step := range fields {
+		fields[step] = make([]float64, grid.Size())
+		for i := range fields[step] {
+			fields[step][i] = float64(step*1000 + i)
+		}
+	}
+	fr := &fieldReader{fields: fields}
+	gi := grids.NewGridInterpolator(grids.AsValueReader(fr), grid, 0, &interpolators.BilinearInterpolator{})
+
+	points := []grids.Point{{Lat: 1.5, Lon: 101.5}, {Lat: 5.2, Lon: 108.7}, {Lat: 9.9, Lon: 100.1}}
+	res, err := grids.NewEngine(gi, 1).Run(context.Background(), points, []int{0, 1})
+	require.NoError(t, err)
+	require.NoError(t, res.Err())
+
+	// 每个时间步一次批量读取
+	assert.Equal(t, 2, fr.batchCalls)
+
+	for i, pt := range points {
+		for j := range 2 {
+			want, err := gi.InterpolateAt(j, pt.Lat, pt.Lon)
+			require.NoError(t, err)
+			assert.InDelta(t, want, res.At(i, j), 1e-9)
+		}
+	}
+}
+
+func TestEngine_CellErrors(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	gi := grids.NewGridInterpolator(&stepReader{timeReader{grid: grid}}, grid, 0, &interpolators.BilinearInterpolator{})
+
+	points := []grids.Point{{Lat: 5, Lon: 105}, {Lat: 50, Lon: 105}}
+	res, err := grids.NewEngine(gi, 2).Run(context.Background(), points, []int{0, -1})
+	require.NoError(t, err)
+
+	assert.False(t, math.IsNaN(res.At(0, 0)))
+	assert.True(t, math.IsNaN(res.At(0, 1)))
+	assert.True(t, math.IsNaN(res.At(1, 0)))
+	assert.True(t, math.IsNaN(res.At(1, 1)))
+
+	require.Len(t, res.Errors, 3)
+	assert.Equal(t, 0, res.Errors[0].Point)
+	assert.Equal(t, -1, res.Errors[0].TimeStep)
+	assert.Equal(t, 1, res.Errors[1].Point)
+	assert.Equal(t, 1, res.Errors[2].Point)
+
+	var cellErr *grids.CellError
+	require.True(t, errors.As(res.Err(), &cellErr))
+}
+
+func TestEngine_SerializedReads(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	reader := &exclusiveReader{timeReader: timeReader{grid: grid}}
+	gi := grids.NewGridInterpolator(reader, grid, 0, &interpolators.BilinearInterpolator{})
+
+	points := make([]grids.Point, 16)
+	for i := range points {
+		points[i] = grids.Point{Lat: 1 + float64(i)/2, Lon: 101 + float64(i)/2}
+	}
+
+	res, err := grids.NewEngine(gi, 8).WithSerializedReads().Run(context.Background(), points, []int{0, 1})
+	require.NoError(t, err)
+	require.NoError(t, res.Err())
+	assert.False(t, reader.concurrent.Load(), "reads must be serialised")
+}
+
+func TestEngine_Cancel(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	gi := grids.NewGridInterpolator(&timeReader{grid: grid}, grid, 0, &interpolators.BilinearInterpolator{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	res, err := grids.NewEngine(gi, 2).Run(ctx, []grids.Point{{Lat: 5, Lon: 105}}, []int{0, 1})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, math.IsNaN(res.At(0, 0)))
+}
+
+func TestNewSyncReader_Batch(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	plain := &funcReader{grid: grid, f: func(lat, lon float64) float64 { return lat + lon }}
+
+	// 底层读取器不支持批量读取时，包装后的读取器也不实现 BatchValueReader
+	s := grids.NewSyncReader(plain)
+	_, ok := s.(grids.BatchValueReader)
+	assert.False(t, ok)
+	assert.Same(t, s, grids.NewSyncReader(s))
+
+	fr := &fieldReader{fields: [][]float64{{1, 2, 3}}}
+	batch, ok := grids.NewSyncReader(grids.AsValueReader(fr)).(grids.BatchValueReader)
+	require.True(t, ok)
+
+	dst := make([]float64, 3)
+	require.NoError(t, batch.ReadField(0, dst))
+	assert.Equal(t, []float64{1, 2, 3}, dst)
+}
@@ -40,6 +40,15 @@ func NewRegular(n int) *regular {
 	return r.(*regular)
 }
 
+// RegularNumber 判断 g 是否为规则高斯网格，是时返回高斯网格数 N（赤道到极点之间的纬圈个数）
+func RegularNumber(g grids.Grid) (int, bool) {
+	r, ok := g.(*regular)
+	if !ok {
+		return 0, false
+	}
+	return r.n, true
+}
+
 func newRegular(n int) *regular {
 	r := &regular{
 		n: n,
@@ -63,6 +72,11 @@ func (g *regular) longitudesSize() int {
 	return g.n * 4
 }
 
+// IsSphere 规则高斯网格总是覆盖全部经度
+func (g *regular) IsSphere() bool {
+	return true
+}
+
 func (g *regular) Latitudes() []float64 {
 	return g.latitudes
 }
@@ -88,12 +102,32 @@ func (g *regular) calcLongitudes() []float64 {
 	return longitudes
 }
 
+// nearestLonIndices 返回经度两侧的经度下标，经度先规范到 [0, 360)
+// 位于最后一列与 360° 之间时，两侧分别为最后一列和第一列
+func (g *regular) nearestLonIndices(lon float64) (float64, [2]int) {
+	lon = math.Mod(math.Mod(lon, 360)+360, 360)
+
+	longitudes := g.Longitudes()
+	indices := grids.FindNearestIndices(lon, longitudes)
+	if last := len(longitudes) - 1; indices[0] == last {
+		indices[1] = 0
+	}
+
+	return lon, indices
+}
+
+// lonDistance 计算两个经度之间沿纬圈的最小差值
+func lonDistance(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	return math.Min(d, 360-d)
+}
+
 func (g *regular) GetNearestIndex(lat, lon float64) (int, int) {
 	latitudes := g.Latitudes()
 	longitudes := g.Longitudes()
 
 	indicesLat := grids.FindNearestIndices(lat, latitudes)
-	indicesLon := grids.FindNearestIndices(lon, longitudes)
+	lon, indicesLon := g.nearestLonIndices(lon)
 
 	latIdx := indicesLat[0]
 	lonIdx := indicesLon[0]
@@ -121,7 +155,7 @@ func (g *regular) GuessNearestIndex(lat, lon float64) (int, int) {
 	longitudes := g.Longitudes()
 
 	indicesLat := grids.FindNearestIndices(lat, latitudes)
-	indicesLon := grids.FindNearestIndices(lon, longitudes)
+	lon, indicesLon := g.nearestLonIndices(lon)
 
 	latIdx := indicesLat[0]
 	lonIdx := indicesLon[0]
@@ -130,7 +164,7 @@ func (g *regular) GuessNearestIndex(lat, lon float64) (int, int) {
 		latIdx = indicesLat[1]
 	}
 
-	if math.Abs(longitudes[lonIdx]-lon) > math.Abs(longitudes[indicesLon[1]]-lon) {
+	if lonDistance(longitudes[lonIdx], lon) > lonDistance(longitudes[indicesLon[1]], lon) {
 		lonIdx = indicesLon[1]
 	}
 
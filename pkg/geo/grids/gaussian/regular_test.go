@@ -207,3 +207,30 @@ func BenchmarkNewRegular(b *testing.B) {
 		})
 	}
 }
+
+func TestRegular_NearestIndexWrap(t *testing.T) {
+	g := gaussian.NewRegular(48)
+
+	tests := []struct {
+		name   string
+		lon    float64
+		lonIdx int
+	}{
+		{name: "between last column and 360", lon: 359.5, lonIdx: 0},
+		{name: "negative longitude", lon: -10, lonIdx: 187},
+		{name: "beyond 360", lon: 361.9, lonIdx: 1},
+		{name: "last column", lon: 358.5, lonIdx: 191},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, lonIdx := g.GetNearestIndex(0, tt.lon)
+			assert.Equal(t, tt.lonIdx, lonIdx)
+
+			_, lonIdx = g.GuessNearestIndex(0, tt.lon)
+			assert.Equal(t, tt.lonIdx, lonIdx)
+		})
+	}
+
+	assert.True(t, g.IsSphere())
+}
@@ -25,14 +25,23 @@ func GridIndex(g Grid, lat, lon float64, mode ScanMode) int {
 }
 
 func GridPoint(g Grid, index int, mode ScanMode) (lat, lon float64, ok bool) {
-	if index < 0 || index >= g.Size() {
+	latIdx, lonIdx, ok := GridIndicesFromIndex(g, index, mode)
+	if !ok {
 		return math.NaN(), math.NaN(), false
 	}
 
+	return g.Latitudes()[latIdx], g.Longitudes()[lonIdx], true
+}
+
+// GridIndicesFromIndex 将网格索引转换为纬度、经度数组中的下标，是 GridIndexFromIndices 的逆运算
+func GridIndicesFromIndex(g Grid, index int, mode ScanMode) (latIdx, lonIdx int, ok bool) {
+	if index < 0 || index >= g.Size() {
+		return -1, -1, false
+	}
+
 	latitudesSize := len(g.Latitudes())
 	longitudesSize := len(g.Longitudes())
 
-	var latIdx, lonIdx int
 	if mode.IsConsecutiveJ() {
 		lonIdx = index / latitudesSize
 		latIdx = index % latitudesSize
@@ -56,7 +65,7 @@ func GridPoint(g Grid, index int, mode ScanMode) (lat, lon float64, ok bool) {
 		lonIdx = longitudesSize - 1 - lonIdx
 	}
 
-	return g.Latitudes()[latIdx], g.Longitudes()[lonIdx], true
+	return latIdx, lonIdx, true
 }
 
 func GridIndexFromIndices(g Grid, latIdx, lonIdx int, mode ScanMode) int {
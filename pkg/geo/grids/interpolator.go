@@ -1,6 +1,9 @@
 package grids
 
 import (
+	"fmt"
+	"math"
+
 	"github.com/scorix/walg/pkg/geo/grids/interpolators"
 )
 
@@ -16,9 +19,15 @@ type GridInterpolator struct {
 	grid         Grid
 	scanningMode ScanMode
 	interpolator interpolators.Interpolator
+	magnitude    ValueReader // 方向场插值时用于加权的模长场，可为空
+	missing      missingValues
+	mask         *surfaceMask
 }
 
 // NewGridInterpolator 创建新的网格插值器
+// 每个物理量可以选择不同的插值算法，例如：
+// - 温度、位势高度：BicubicInterpolator
+// - 降水、云量、相对湿度等有界量：MonotoneCubicInterpolator 或 ClippedInterpolator
 func NewGridInterpolator(reader ValueReader, grid Grid, scanningMode ScanMode, interpolator interpolators.Interpolator) *GridInterpolator {
 	if interpolator == nil {
 		interpolator = &interpolators.BilinearInterpolator{} // 默认使用双线性插值
@@ -31,37 +40,161 @@ func NewGridInterpolator(reader ValueReader, grid Grid, scanningMode ScanMode, i
 	}
 }
 
+// NewDirectionInterpolator 创建方向场（风向、浪向等，单位为度）插值器
+// 方向按单位向量插值后再转换回角度，避免 350° 与 10° 平均得到 180°
+// interpolator 为任意权重方案（最近邻、双线性、IDW 等），为空时使用双线性插值；
+// magnitude 为对应的风速、波高等模长场，不为空时按模长对向量加权
+func NewDirectionInterpolator(direction, magnitude ValueReader, grid Grid, scanningMode ScanMode, interpolator interpolators.Interpolator) *GridInterpolator {
+	if interpolator == nil {
+		interpolator = &interpolators.BilinearInterpolator{}
+	}
+	if _, ok := interpolator.(*interpolators.CircularInterpolator); !ok {
+		interpolator = interpolators.NewCircularInterpolator(interpolator)
+	}
+
+	g := NewGridInterpolator(direction, grid, scanningMode, interpolator)
+	g.magnitude = magnitude
+
+	return g
+}
+
 // InterpolateAt 在指定时间步和位置进行插值
 func (g *GridInterpolator) InterpolateAt(timeStep int, lat, lon float64) (float64, error) {
-	// 获取网格索引
-	latIdx, lonIdx := g.grid.GetNearestIndex(lat, lon)
+	indices, weights, err := g.neighbours(lat, lon)
+	if err != nil {
+		return 0, err
+	}
+
+	return g.interpolate(timeStep, lat, lon, indices, weights, nil)
+}
+
+// neighbours 返回参与插值的网格索引和插值权重
+func (g *GridInterpolator) neighbours(lat, lon float64) ([]int, []float64, error) {
+	// 全球网格上位于最外侧纬度行与极点之间的点，与虚拟极点一起插值
+	// 旋转网格等非地理坐标网格的最外侧纬度行不对应地理极点，不使用虚拟极点
+	if _, ok := g.grid.(GeographicLocator); !ok && isSphere(g.grid) {
+		if indices, weights, ok := g.poleNeighbours(lat, lon); ok {
+			return indices, weights, nil
+		}
+	}
+
+	// 以包含目标点的单元格为基准取点，保证权重总在 [0, 1] 之间，不会外推
+	cell, ok := EnclosingCell(g.grid, lat, lon)
+	if !ok {
+		return nil, nil, fmt.Errorf("point (%f, %f) is outside the grid", lat, lon)
+	}
+
+	weights := []float64{cell.LatFraction, cell.LonFraction}
+
+	// 需要更大邻域的算法在靠近网格边缘无法取得完整邻域时，
+	// 只传入四个相邻点，由算法自行退化为双线性插值
+	if s, ok := g.interpolator.(interpolators.StencilInterpolator); ok && s.StencilSize() > 2 {
+		if indices, ok := stencil(g.grid, g.scanningMode, cell, s.StencilSize()); ok {
+			return indices, weights, nil
+		}
+	}
 
 	// 获取四个相邻点的网格索引
 	indices := []int{
-		GridIndexFromIndices(g.grid, latIdx, lonIdx, g.scanningMode),
-		GridIndexFromIndices(g.grid, latIdx, lonIdx+1, g.scanningMode),
-		GridIndexFromIndices(g.grid, latIdx+1, lonIdx, g.scanningMode),
-		GridIndexFromIndices(g.grid, latIdx+1, lonIdx+1, g.scanningMode),
+		GridIndexFromIndices(g.grid, cell.LatIdx, cell.LonIdx, g.scanningMode),
+		GridIndexFromIndices(g.grid, cell.LatIdx, cell.NextLonIdx, g.scanningMode),
+		GridIndexFromIndices(g.grid, cell.NextLatIdx, cell.LonIdx, g.scanningMode),
+		GridIndexFromIndices(g.grid, cell.NextLatIdx, cell.NextLonIdx, g.scanningMode),
 	}
 
+	return indices, weights, nil
+}
+
+// interpolate 读取相邻点的值并使用选定的插值算法进行计算
+// exclude 不为空时，标记为 true 的点不参与插值，其余点按有效点重新归一化权重
+func (g *GridInterpolator) interpolate(timeStep int, lat, lon float64, indices []int, weights []float64, exclude []bool) (float64, error) {
 	// 获取相邻点的值
-	points := make([]float64, 4)
-	for i, idx := range indices {
-		value, err := g.reader.ReadValueAt(timeStep, idx)
+	points, err := g.readPoints(g.reader, poleReducer(g.interpolator), timeStep, indices)
+	if err != nil {
+		return 0, err
+	}
+
+	// 方向场按模长加权
+	var magnitudes []float64
+	m, weighted := g.interpolator.(interpolators.MagnitudeInterpolator)
+	if weighted && g.magnitude != nil {
+		magnitudes, err = g.readPoints(g.magnitude, &interpolators.AverageInterpolator{}, timeStep, indices)
 		if err != nil {
 			return 0, err
 		}
-		points[i] = value
 	}
 
-	// 计算权重
-	lats := g.grid.Latitudes()
-	lons := g.grid.Longitudes()
-	weights := []float64{
-		(lat - lats[latIdx]) / (lats[latIdx+1] - lats[latIdx]),
-		(lon - lons[lonIdx]) / (lons[lonIdx+1] - lons[lonIdx]),
+	var missing int
+	if g.missing.policy != MissingIgnore {
+		missing = g.missing.mask(points)
+		for i := range magnitudes {
+			if g.missing.isMissing(magnitudes[i]) && !math.IsNaN(points[i]) {
+				points[i] = math.NaN()
+				missing++
+			}
+		}
+	}
+
+	var excluded int
+	for i, ex := range exclude {
+		if ex && !math.IsNaN(points[i]) {
+			points[i] = math.NaN()
+			excluded++
+		}
+	}
+
+	switch {
+	case missing > 0:
+		return g.interpolateMissing(timeStep, lat, lon, indices, points, magnitudes, weights, missing)
+	case excluded > 0:
+		value := g.interpolateMasked(points, magnitudes, weights)
+		if math.IsNaN(value) {
+			return 0, ErrNoMatchingSurface
+		}
+		return value, nil
+	case magnitudes != nil:
+		return m.InterpolateWithMagnitude(points, magnitudes, weights), nil
+	default:
+		return g.interpolator.Interpolate(points, weights), nil
+	}
+}
+
+// interpolateMasked 忽略 NaN 点进行插值，没有可用的点时返回 NaN
+func (g *GridInterpolator) interpolateMasked(points, magnitudes, weights []float64) float64 {
+	if m, ok := g.interpolator.(interpolators.MagnitudeInterpolator); ok && magnitudes != nil {
+		return m.InterpolateWithMagnitude(points, magnitudes, weights)
+	}
+	return interpolators.InterpolateMasked(g.interpolator, points, weights)
+}
+
+// readPoints 读取一组网格索引上的值，reader 实现了 BatchValueReader 时一次性读取
+// 虚拟极点的值由 reducer 对最外侧纬度行归并得到
+func (g *GridInterpolator) readPoints(reader ValueReader, reducer interpolators.Reducer, timeStep int, indices []int) ([]float64, error) {
+	points := make([]float64, len(indices))
+
+	gridIndices := make([]int, 0, len(indices))
+	positions := make([]int, 0, len(indices))
+	for i, idx := range indices {
+		if isPoleIndex(idx) {
+			value, err := g.poleValue(reader, reducer, timeStep, idx)
+			if err != nil {
+				return nil, err
+			}
+			points[i] = value
+			continue
+		}
+
+		gridIndices = append(gridIndices, idx)
+		positions = append(positions, i)
+	}
+
+	values := make([]float64, len(gridIndices))
+	if err := readValues(reader, timeStep, gridIndices, values); err != nil {
+		return nil, err
+	}
+	for k, i := range positions {
+		points[i] = values[k]
 	}
 
-	// 使用选定的插值算法进行计算
-	return g.interpolator.Interpolate(points, weights), nil
+	return points, nil
 }
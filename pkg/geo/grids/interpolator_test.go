@@ -2,10 +2,12 @@ package grids_test
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/scorix/walg/pkg/geo/grids"
 	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -232,3 +234,184 @@ func TestGridInterpolator_WithDifferentInterpolators(t *testing.T) {
 		})
 	}
 }
+
+// funcReader 按网格点坐标计算取值，用于在真实网格上验证插值结果
+type funcReader struct {
+	grid grids.Grid
+	mode grids.ScanMode
+	f    func(lat, lon float64) float64
+}
+
+func (r *funcReader) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	lat, lon, ok := grids.GridPoint(r.grid, gridIndex, r.mode)
+	if !ok {
+		return 0, fmt.Errorf("invalid grid index: %d", gridIndex)
+	}
+	return r.f(lat, lon), nil
+}
+
+func TestGridInterpolator_Bicubic(t *testing.T) {
+	field := func(lat, lon float64) float64 { return lat*lat/10 + lon }
+
+	tests := []struct {
+		name string
+		grid grids.Grid
+		mode grids.ScanMode
+		lat  float64
+		lon  float64
+		want float64
+	}{
+		{
+			// 内部点：Catmull-Rom 精确重建二次场
+			name: "interior point",
+			grid: latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1),
+			lat:  4.3,
+			lon:  105.6,
+			want: field(4.3, 105.6),
+		},
+		{
+			name: "interior point with positive j scanning",
+			grid: latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1),
+			mode: grids.ScanModePositiveJ | grids.ScanModeConsecutiveJ,
+			lat:  4.7,
+			lon:  105.2,
+			want: field(4.7, 105.2),
+		},
+		{
+			// 边缘单元格无法取得 4×4 邻域，退化为双线性插值
+			name: "edge falls back to bilinear",
+			grid: latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1),
+			lat:  9.7,
+			lon:  105,
+			want: 0.3*81.0/10 + 0.7*100.0/10 + 105,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &funcReader{grid: tt.grid, mode: tt.mode, f: field}
+			interpolator := grids.NewGridInterpolator(reader, tt.grid, tt.mode, interpolators.NewBicubicInterpolator())
+			got, err := interpolator.InterpolateAt(0, tt.lat, tt.lon)
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 1e-6)
+		})
+	}
+}
+
+func TestGridInterpolator_BicubicLongitudeWrap(t *testing.T) {
+	// 全球网格上跨越 0° 经线时沿经度方向循环取点
+	grid := latlon.NewLatLonGrid(-90, 90, 0, 359, 1, 1)
+	field := func(lat, lon float64) float64 {
+		return math.Cos(lon*math.Pi/180) + lat/100
+	}
+	reader := &funcReader{grid: grid, f: field}
+	interpolator := grids.NewGridInterpolator(reader, grid, 0, interpolators.NewBicubicInterpolator())
+
+	got, err := interpolator.InterpolateAt(0, 10.5, 0.4)
+	assert.NoError(t, err)
+	assert.InDelta(t, field(10.5, 0.4), got, 1e-4)
+}
+
+func TestGridInterpolator_BoundedFields(t *testing.T) {
+	// 孤立降水点 (5, 105)，周围均为 0
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	reader := &funcReader{grid: grid, f: func(lat, lon float64) float64 {
+		if lat == 5 && lon == 105 {
+			return 20
+		}
+		return 0
+	}}
+
+	bicubic := grids.NewGridInterpolator(reader, grid, 0, interpolators.NewBicubicInterpolator())
+	got, err := bicubic.InterpolateAt(0, 5.5, 106.3)
+	assert.NoError(t, err)
+	assert.Less(t, got, 0.0)
+
+	for _, interpolator := range []interpolators.Interpolator{
+		&interpolators.MonotoneCubicInterpolator{},
+		interpolators.NewClippedInterpolator(interpolators.NewBicubicInterpolator()),
+	} {
+		gi := grids.NewGridInterpolator(reader, grid, 0, interpolator)
+		for lat := 3.0; lat <= 7.0; lat += 0.1 {
+			for lon := 103.0; lon <= 107.0; lon += 0.1 {
+				got, err := gi.InterpolateAt(0, lat, lon)
+				assert.NoError(t, err)
+				assert.GreaterOrEqual(t, got, 0.0)
+				assert.LessOrEqual(t, got, 20.0)
+			}
+		}
+	}
+}
+
+func TestDirectionInterpolator(t *testing.T) {
+	grid := &mockGrid{
+		lats: []float64{30.0, 31.0},
+		lons: []float64{120.0, 121.0},
+	}
+
+	// 左侧为 350°，右侧为 10°
+	direction := &mockReader{values: map[int]float64{0: 350, 1: 10, 2: 350, 3: 10}}
+	// 右侧风速更大
+	speed := &mockReader{values: map[int]float64{0: 1, 1: 3, 2: 1, 3: 3}}
+
+	tests := []struct {
+		name         string
+		magnitude    grids.ValueReader
+		interpolator interpolators.Interpolator
+		lat          float64
+		lon          float64
+		want         float64
+	}{
+		{name: "bilinear", interpolator: nil, lat: 30.5, lon: 120.5, want: 0},
+		{name: "idw", interpolator: interpolators.NewIDWInterpolator(2), lat: 30.5, lon: 120.5, want: 0},
+		{name: "nearest", interpolator: &interpolators.NearestInterpolator{}, lat: 30.2, lon: 120.2, want: 350},
+		{
+			name:      "bilinear weighted by speed",
+			magnitude: speed,
+			lat:       30.5,
+			lon:       120.5,
+			want:      math.Atan2(3*math.Sin(10*math.Pi/180)+math.Sin(-10*math.Pi/180), 4*math.Cos(10*math.Pi/180)) * 180 / math.Pi,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interpolator := grids.NewDirectionInterpolator(direction, tt.magnitude, grid, 0, tt.interpolator)
+			got, err := interpolator.InterpolateAt(0, tt.lat, tt.lon)
+			assert.NoError(t, err)
+			diff := math.Mod(got-tt.want+540, 360) - 180
+			assert.InDelta(t, 0, diff, 1e-6, "got %f, want %f", got, tt.want)
+		})
+	}
+
+	t.Run("magnitude read error", func(t *testing.T) {
+		interpolator := grids.NewDirectionInterpolator(direction, &mockReader{values: map[int]float64{}}, grid, 0, nil)
+		_, err := interpolator.InterpolateAt(0, 30.5, 120.5)
+		assert.Error(t, err)
+	})
+}
+
+func TestGridInterpolator_Mode(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	// 纬度 < 5 为类别 1，其余为类别 2，并在 (5, 105) 放置一个孤立的类别 9
+	reader := &funcReader{grid: grid, f: func(lat, lon float64) float64 {
+		switch {
+		case lat == 5 && lon == 105:
+			return 9
+		case lat < 5:
+			return 1
+		default:
+			return 2
+		}
+	}}
+
+	nearest := grids.NewGridInterpolator(reader, grid, 0, &interpolators.NearestInterpolator{})
+	got, err := nearest.InterpolateAt(0, 5.2, 105.2)
+	assert.NoError(t, err)
+	assert.Equal(t, 9.0, got)
+
+	mode := grids.NewGridInterpolator(reader, grid, 0, interpolators.NewModeInterpolator(4, false))
+	got, err = mode.InterpolateAt(0, 5.2, 105.2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, got)
+}
@@ -1,5 +1,7 @@
 package interpolators
 
+import "math"
+
 // AverageInterpolator 简单平均插值实现
 // 简单平均插值是最基础的插值方法，直接计算所有相邻点的算术平均值
 // 适用场景：
@@ -23,3 +25,20 @@ func (a *AverageInterpolator) Interpolate(points []float64, weights []float64) f
 	}
 	return sum / float64(len(points))
 }
+
+// Reduce 返回一组值的算术平均值，可作为连续量粗化网格时的降采样规则
+// NaN 视为缺测，不参与平均；全部缺测时返回 NaN
+func (a *AverageInterpolator) Reduce(values []float64) float64 {
+	var sum float64
+	var count int
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			sum += v
+			count++
+		}
+	}
+	if count == 0 {
+		return math.NaN()
+	}
+	return sum / float64(count)
+}
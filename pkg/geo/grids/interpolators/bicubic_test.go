@@ -0,0 +1,64 @@
+package interpolators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBicubicInterpolator_Interpolate(t *testing.T) {
+	// 以 f(x, y) = x² + 2y 填充 4×4 邻域，中心单元格左下角位于 (0, 0)
+	field := func(x, y float64) float64 { return x*x + 2*y }
+	points := make([]float64, 16)
+	for r := 0; r < 4; r++ {
+		for c := 0; c < 4; c++ {
+			points[4*r+c] = field(float64(c-1), float64(r-1))
+		}
+	}
+
+	tests := []struct {
+		name    string
+		weights []float64
+	}{
+		{name: "bottom left corner", weights: []float64{0, 0}},
+		{name: "top right corner", weights: []float64{1, 1}},
+		{name: "center", weights: []float64{0.5, 0.5}},
+		{name: "off center", weights: []float64{0.2, 0.7}},
+	}
+
+	bc := NewBicubicInterpolator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Catmull-Rom 在等间距网格上可精确重建二次函数
+			want := field(tt.weights[1], tt.weights[0])
+			got := bc.Interpolate(points, tt.weights)
+			assert.InDelta(t, want, got, 1e-10)
+		})
+	}
+}
+
+func TestBicubicInterpolator_CornerValues(t *testing.T) {
+	points := []float64{
+		1, 2, 3, 4,
+		5, 10, 20, 8,
+		9, 30, 40, 12,
+		13, 14, 15, 16,
+	}
+
+	for _, bc := range []*BicubicInterpolator{{}, NewBicubicInterpolator(), NewCubicConvolutionInterpolator(-0.75)} {
+		assert.InDelta(t, 10.0, bc.Interpolate(points, []float64{0, 0}), 1e-10)
+		assert.InDelta(t, 20.0, bc.Interpolate(points, []float64{0, 1}), 1e-10)
+		assert.InDelta(t, 30.0, bc.Interpolate(points, []float64{1, 0}), 1e-10)
+		assert.InDelta(t, 40.0, bc.Interpolate(points, []float64{1, 1}), 1e-10)
+	}
+}
+
+func TestBicubicInterpolator_BilinearFallback(t *testing.T) {
+	bc := NewBicubicInterpolator()
+	bi := &BilinearInterpolator{}
+
+	points := []float64{10, 20, 30, 40}
+	weights := []float64{0.3, 0.6}
+	assert.InDelta(t, bi.Interpolate(points, weights), bc.Interpolate(points, weights), 1e-10)
+	assert.Equal(t, 4, bc.StencilSize())
+}
@@ -0,0 +1,96 @@
+package interpolators
+
+import "math"
+
+// CircularInterpolator 角度插值实现
+// 风向、浪向等角度量在 0°/360° 处不连续，直接加权平均会得到错误结果
+// （例如 350° 与 10° 平均得到 180°）
+// 该实现将每个角度转换为单位向量，用被包装的插值算法分别对 sin、cos 分量插值，
+// 再通过 atan2 转换回角度
+// 适用场景：
+// 1. 风向、浪向、流向等以度为单位的方向场
+// 2. 配合最近邻、双线性、IDW 等任意线性权重方案使用
+//
+// 按模长加权时（InterpolateWithMagnitude），每个方向向量乘以对应的风速、波高等模长，
+// 强风点对结果方向的影响更大
+//
+// 返回值范围为 [0, 360)；各向量相互抵消、合成向量长度为 0 时返回 NaN
+// 方向或模长为 NaN 的点视为缺测，按 InterpolateMasked 的规则忽略
+type CircularInterpolator struct {
+	Interpolator Interpolator
+}
+
+func NewCircularInterpolator(interpolator Interpolator) *CircularInterpolator {
+	return &CircularInterpolator{Interpolator: interpolator}
+}
+
+// StencilSize 返回被包装算法所需的邻域大小
+func (c *CircularInterpolator) StencilSize() int {
+	if s, ok := c.Interpolator.(StencilInterpolator); ok {
+		return s.StencilSize()
+	}
+	return 2
+}
+
+func (c *CircularInterpolator) Interpolate(points []float64, weights []float64) float64 {
+	return c.InterpolateWithMagnitude(points, nil, weights)
+}
+
+// InterpolateMasked 角度插值本身会忽略缺测点
+func (c *CircularInterpolator) InterpolateMasked(points []float64, weights []float64) float64 {
+	return c.Interpolate(points, weights)
+}
+
+// InterpolateWithMagnitude 按模长加权进行角度插值
+// magnitudes 为空时等同于 Interpolate
+func (c *CircularInterpolator) InterpolateWithMagnitude(points []float64, magnitudes []float64, weights []float64) float64 {
+	sins := make([]float64, len(points))
+	coss := make([]float64, len(points))
+	for i, point := range points {
+		m := 1.0
+		if magnitudes != nil {
+			m = magnitudes[i]
+		}
+
+		sin, cos := math.Sincos(point * math.Pi / 180)
+		sins[i] = m * sin
+		coss[i] = m * cos
+	}
+
+	y := InterpolateMasked(c.Interpolator, sins, weights)
+	x := InterpolateMasked(c.Interpolator, coss, weights)
+	if math.IsNaN(x) || math.IsNaN(y) || math.Hypot(x, y) < 1e-12 {
+		return math.NaN()
+	}
+
+	deg := math.Atan2(y, x) * 180 / math.Pi
+	if deg < 0 {
+		deg += 360
+	}
+
+	return deg
+}
+
+// Reduce 返回一组角度的平均方向，可用于计算极点值或粗化方向场
+// NaN 视为缺测；全部缺测或各向量相互抵消时返回 NaN
+func (c *CircularInterpolator) Reduce(values []float64) float64 {
+	var x, y float64
+	for _, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		sin, cos := math.Sincos(v * math.Pi / 180)
+		x += cos
+		y += sin
+	}
+	if math.Hypot(x, y) < 1e-12 {
+		return math.NaN()
+	}
+
+	deg := math.Atan2(y, x) * 180 / math.Pi
+	if deg < 0 {
+		deg += 360
+	}
+
+	return deg
+}
@@ -0,0 +1,88 @@
+package interpolators
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircularInterpolator_Interpolate(t *testing.T) {
+	tests := []struct {
+		name         string
+		interpolator Interpolator
+		points       []float64
+		weights      []float64
+		want         float64
+	}{
+		{
+			// 350° 与 10° 的中点应为 0°，而不是 180°
+			name:         "bilinear across north",
+			interpolator: &BilinearInterpolator{},
+			points:       []float64{350, 10, 350, 10},
+			weights:      []float64{0.5, 0.5},
+			want:         0,
+		},
+		{
+			name:         "bilinear quarter",
+			interpolator: &BilinearInterpolator{},
+			points:       []float64{340, 20, 340, 20},
+			weights:      []float64{0, 0.25},
+			want:         349.6859,
+		},
+		{
+			name:         "idw across north",
+			interpolator: NewIDWInterpolator(2),
+			points:       []float64{355, 5, 355, 5},
+			weights:      []float64{0.5, 0.5},
+			want:         0,
+		},
+		{
+			name:         "nearest keeps original direction",
+			interpolator: &NearestInterpolator{},
+			points:       []float64{350, 10, 170, 190},
+			weights:      []float64{0.8, 0.9},
+			want:         190,
+		},
+		{
+			name:         "no wrap needed",
+			interpolator: &BilinearInterpolator{},
+			points:       []float64{80, 100, 80, 100},
+			weights:      []float64{0.5, 0.5},
+			want:         90,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCircularInterpolator(tt.interpolator)
+			got := c.Interpolate(tt.points, tt.weights)
+			// 比较角度差，0° 与 360° 视为相同
+			diff := math.Mod(got-tt.want+540, 360) - 180
+			assert.InDelta(t, 0, diff, 1e-3, "got %f, want %f", got, tt.want)
+		})
+	}
+}
+
+func TestCircularInterpolator_InterpolateWithMagnitude(t *testing.T) {
+	c := NewCircularInterpolator(&BilinearInterpolator{})
+	points := []float64{0, 90, 0, 90}
+	weights := []float64{0.5, 0.5}
+
+	// 无模长时为 45°
+	assert.InDelta(t, 45.0, c.InterpolateWithMagnitude(points, nil, weights), 1e-10)
+
+	// 东风（90°）风速更大时结果偏向 90°
+	got := c.InterpolateWithMagnitude(points, []float64{1, 3, 1, 3}, weights)
+	assert.InDelta(t, math.Atan2(3, 1)*180/math.Pi, got, 1e-10)
+}
+
+func TestCircularInterpolator_Opposite(t *testing.T) {
+	c := NewCircularInterpolator(&BilinearInterpolator{})
+	assert.True(t, math.IsNaN(c.Interpolate([]float64{0, 180, 0, 180}, []float64{0.5, 0.5})))
+}
+
+func TestCircularInterpolator_StencilSize(t *testing.T) {
+	assert.Equal(t, 2, NewCircularInterpolator(&BilinearInterpolator{}).StencilSize())
+	assert.Equal(t, 4, NewCircularInterpolator(NewBicubicInterpolator()).StencilSize())
+}
@@ -0,0 +1,51 @@
+package interpolators
+
+import "math"
+
+// ClippedInterpolator 限幅插值实现
+// 包装任意插值算法，将结果限制在参与插值的所有点的最小值与最大值之间
+// 适用场景：
+// 1. 希望保留双三次等高阶插值的形状，同时避免降水出现负值、湿度超过 100%
+// 2. 对任意插值算法追加有界性保证
+//
+// 注意：限幅只保证结果不越界，不保证单调；需要单调性时使用 MonotoneCubicInterpolator
+type ClippedInterpolator struct {
+	Interpolator Interpolator
+}
+
+func NewClippedInterpolator(interpolator Interpolator) *ClippedInterpolator {
+	return &ClippedInterpolator{Interpolator: interpolator}
+}
+
+// StencilSize 返回被包装算法所需的邻域大小
+func (c *ClippedInterpolator) StencilSize() int {
+	if s, ok := c.Interpolator.(StencilInterpolator); ok {
+		return s.StencilSize()
+	}
+	return 2
+}
+
+func (c *ClippedInterpolator) Interpolate(points []float64, weights []float64) float64 {
+	return c.clip(c.Interpolator.Interpolate(points, weights), points)
+}
+
+// InterpolateMasked 忽略缺测点插值，并限制在有效点的取值范围内
+func (c *ClippedInterpolator) InterpolateMasked(points []float64, weights []float64) float64 {
+	return c.clip(InterpolateMasked(c.Interpolator, points, weights), points)
+}
+
+func (c *ClippedInterpolator) clip(result float64, points []float64) float64 {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, point := range points {
+		if math.IsNaN(point) {
+			continue
+		}
+		lo = min(lo, point)
+		hi = max(hi, point)
+	}
+	if math.IsNaN(result) || lo > hi {
+		return math.NaN()
+	}
+
+	return min(max(result, lo), hi)
+}
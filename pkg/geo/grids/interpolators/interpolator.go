@@ -7,3 +7,26 @@ type Interpolator interface {
 	// weights: 插值权重
 	Interpolate(points []float64, weights []float64) float64
 }
+
+// StencilInterpolator 需要比 2×2 更大邻域的插值算法
+// 网格插值器会按 StencilSize 收集 n×n 个点，按行优先顺序传入 points：
+// 行沿纬度索引方向，列沿经度索引方向，目标点所在单元格位于邻域中心
+// 靠近网格边缘无法取得完整邻域时只传入 4 个点，实现应退化为双线性插值
+type StencilInterpolator interface {
+	Interpolator
+	// StencilSize 返回每个方向需要的点数，例如双三次插值返回 4
+	StencilSize() int
+}
+
+// MagnitudeInterpolator 可以按模长加权的插值算法
+type MagnitudeInterpolator interface {
+	Interpolator
+	// InterpolateWithMagnitude 执行按模长加权的插值计算
+	// magnitudes: 与 points 一一对应的模长
+	InterpolateWithMagnitude(points []float64, magnitudes []float64, weights []float64) float64
+}
+
+// Reducer 将一组值归并为一个值，用于粗化网格时的降采样
+type Reducer interface {
+	Reduce(values []float64) float64
+}
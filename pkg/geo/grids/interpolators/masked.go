@@ -0,0 +1,65 @@
+package interpolators
+
+import "math"
+
+// MaskedInterpolator 能够自行处理缺测点的插值算法
+// 缺测点以 NaN 表示，非线性算法（最近邻、众数、角度插值等）需要实现该接口，
+// 线性算法由 InterpolateMasked 统一按有效点重新归一化权重
+type MaskedInterpolator interface {
+	Interpolator
+	// InterpolateMasked 仅使用非 NaN 的点进行插值，没有可用的点时返回 NaN
+	InterpolateMasked(points []float64, weights []float64) float64
+}
+
+// InterpolateMasked 忽略缺测点（NaN）进行插值
+// 算法实现了 MaskedInterpolator 时直接调用；否则视为线性算法，
+// 分别对“缺测点置 0 后的值”和“有效点掩码”插值，两者相除即为按有效点重新归一化权重后的结果
+// 有效点的总权重接近 0 时返回 NaN
+func InterpolateMasked(interpolator Interpolator, points []float64, weights []float64) float64 {
+	if m, ok := interpolator.(MaskedInterpolator); ok {
+		return m.InterpolateMasked(points, weights)
+	}
+
+	if !hasNaN(points) {
+		return interpolator.Interpolate(points, weights)
+	}
+
+	values := make([]float64, len(points))
+	mask := make([]float64, len(points))
+	for i, point := range points {
+		if !math.IsNaN(point) {
+			values[i] = point
+			mask[i] = 1
+		}
+	}
+
+	total := interpolator.Interpolate(mask, weights)
+	if math.Abs(total) < 1e-10 {
+		return math.NaN()
+	}
+
+	return interpolator.Interpolate(values, weights) / total
+}
+
+// innerCell 返回 4×4 邻域中目标点所在单元格的四个角点，排列方式与双线性插值相同
+func innerCell(points []float64) []float64 {
+	if len(points) < 16 {
+		return points
+	}
+	return []float64{points[5], points[6], points[9], points[10]}
+}
+
+// stencilOffset 返回 n×n 邻域中第 i 个点相对于单元格左下角的位置
+func stencilOffset(i, n int) (dy, dx float64) {
+	start := 1 - n/2
+	return float64(i/n + start), float64(i%n + start)
+}
+
+func hasNaN(points []float64) bool {
+	for _, point := range points {
+		if math.IsNaN(point) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package interpolators
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolateMasked(t *testing.T) {
+	nan := math.NaN()
+
+	tests := []struct {
+		name         string
+		interpolator Interpolator
+		points       []float64
+		weights      []float64
+		want         float64
+	}{
+		{
+			name:         "bilinear without missing",
+			interpolator: &BilinearInterpolator{},
+			points:       []float64{10, 20, 30, 40},
+			weights:      []float64{0.5, 0.5},
+			want:         25,
+		},
+		{
+			// 右上角缺测，其余三点权重均为 0.25，归一化后平均
+			name:         "bilinear renormalized",
+			interpolator: &BilinearInterpolator{},
+			points:       []float64{10, 20, 30, nan},
+			weights:      []float64{0.5, 0.5},
+			want:         20,
+		},
+		{
+			name:         "average renormalized",
+			interpolator: &AverageInterpolator{},
+			points:       []float64{10, nan, nan, 40},
+			weights:      []float64{0.1, 0.1},
+			want:         25,
+		},
+		{
+			name:         "idw renormalized",
+			interpolator: NewIDWInterpolator(2),
+			points:       []float64{nan, 20, 20, nan},
+			weights:      []float64{0.3, 0.6},
+			want:         20,
+		},
+		{
+			name:         "nearest valid corner",
+			interpolator: &NearestInterpolator{},
+			points:       []float64{nan, 20, 30, 40},
+			weights:      []float64{0.1, 0.2},
+			want:         20, // 右下角距离 √(0.1² + 0.8²)，比左上角 √(0.9² + 0.2²) 更近
+		},
+		{
+			name:         "bicubic falls back to inner cell",
+			interpolator: NewBicubicInterpolator(),
+			points: []float64{
+				nan, 0, 0, 0,
+				0, 10, 20, 0,
+				0, 30, 40, 0,
+				0, 0, 0, 0,
+			},
+			weights: []float64{0.5, 0.5},
+			want:    25,
+		},
+		{
+			name:         "clipped",
+			interpolator: NewClippedInterpolator(&BilinearInterpolator{}),
+			points:       []float64{10, nan, 30, 40},
+			weights:      []float64{0.5, 0.5},
+			want:         80.0 / 3,
+		},
+		{
+			name:         "mode ignores missing",
+			interpolator: &ModeInterpolator{},
+			points:       []float64{nan, nan, 3, 4},
+			weights:      []float64{0.9, 0.1},
+			want:         3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := InterpolateMasked(tt.interpolator, tt.points, tt.weights)
+			assert.InDelta(t, tt.want, got, 1e-10)
+		})
+	}
+}
+
+func TestInterpolateMasked_AllMissing(t *testing.T) {
+	nan := math.NaN()
+	points := []float64{nan, nan, nan, nan}
+
+	for _, interpolator := range []Interpolator{
+		&BilinearInterpolator{},
+		&NearestInterpolator{},
+		NewCircularInterpolator(&BilinearInterpolator{}),
+		NewClippedInterpolator(&BilinearInterpolator{}),
+	} {
+		assert.True(t, math.IsNaN(InterpolateMasked(interpolator, points, []float64{0.5, 0.5})))
+	}
+}
+
+func TestCircularInterpolator_Masked(t *testing.T) {
+	c := NewCircularInterpolator(&BilinearInterpolator{})
+	got := InterpolateMasked(c, []float64{350, math.NaN(), 350, 10}, []float64{0.5, 0.5})
+
+	// 350°、350°、10° 三个单位向量的平均方向
+	want := math.Atan2(2*math.Sin(-10*math.Pi/180)+math.Sin(10*math.Pi/180), 3*math.Cos(10*math.Pi/180))*180/math.Pi + 360
+	assert.InDelta(t, want, got, 1e-10)
+}
@@ -0,0 +1,107 @@
+package interpolators
+
+import (
+	"math"
+)
+
+// ModeInterpolator 众数插值实现
+// 返回邻域内出现次数最多的类别，结果总是邻域中已有的某个取值，不会产生不存在的类别
+// 适用场景：
+// 1. 土地利用类型、降水类型、天气现象代码等分类数据
+// 2. 最近邻插值在类别边界处过于破碎的情况
+// 3. 粗化网格时作为分类数据的降采样规则（见 Reduce）
+//
+// 算法过程：
+// 1. 统计邻域内每个类别的票数；Weighted 为 true 时每个点按到目标点距离平方的倒数计票
+// 2. 选择票数最多的类别
+// 3. 票数相同时，选择离目标点最近的点所属类别；距离仍相同时选择数值较小的类别
+//
+// Size 为邻域在每个方向的点数，默认为 2（四个相邻点）；
+// 取 4 时使用 4×4 邻域，points 与 weights 的排列方式与 BicubicInterpolator 相同
+// NaN 视为缺测，不参与计票；全部缺测时返回 NaN
+type ModeInterpolator struct {
+	Size     int
+	Weighted bool
+}
+
+func NewModeInterpolator(size int, weighted bool) *ModeInterpolator {
+	return &ModeInterpolator{Size: size, Weighted: weighted}
+}
+
+func (m *ModeInterpolator) StencilSize() int {
+	if m.Size < 2 {
+		return 2
+	}
+	return m.Size
+}
+
+func (m *ModeInterpolator) Interpolate(points []float64, weights []float64) float64 {
+	n := int(math.Round(math.Sqrt(float64(len(points)))))
+
+	votes := make(map[float64]float64, len(points))
+	nearest := make(map[float64]float64, len(points))
+	for i, point := range points {
+		if math.IsNaN(point) {
+			continue
+		}
+
+		dy, dx := stencilOffset(i, n)
+		dist := math.Hypot(dy-weights[0], dx-weights[1])
+
+		vote := 1.0
+		if m.Weighted {
+			if dist < 1e-10 {
+				return point // 目标点与网格点重合时直接返回该点的类别
+			}
+			vote = 1 / (dist * dist)
+		}
+		votes[point] += vote
+
+		if d, ok := nearest[point]; !ok || dist < d {
+			nearest[point] = dist
+		}
+	}
+
+	return pickMode(votes, nearest)
+}
+
+// InterpolateMasked 众数插值本身不对缺测点计票
+func (m *ModeInterpolator) InterpolateMasked(points []float64, weights []float64) float64 {
+	return m.Interpolate(points, weights)
+}
+
+// Reduce 返回一组值中出现次数最多的类别，票数相同时选择数值较小的类别
+func (m *ModeInterpolator) Reduce(values []float64) float64 {
+	votes := make(map[float64]float64, len(values))
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			votes[v]++
+		}
+	}
+
+	return pickMode(votes, nil)
+}
+
+// pickMode 选出票数最多的类别，按距离和数值大小打破平局
+func pickMode(votes map[float64]float64, nearest map[float64]float64) float64 {
+	best := math.NaN()
+	for class, vote := range votes {
+		if math.IsNaN(best) {
+			best = class
+			continue
+		}
+
+		switch {
+		case vote > votes[best]:
+			best = class
+		case vote < votes[best]:
+		case nearest != nil && nearest[class] < nearest[best]:
+			best = class
+		case nearest != nil && nearest[class] > nearest[best]:
+		case class < best:
+			best = class
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,97 @@
+package interpolators
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModeInterpolator_Interpolate(t *testing.T) {
+	tests := []struct {
+		name     string
+		weighted bool
+		points   []float64
+		weights  []float64
+		want     float64
+	}{
+		{
+			name:    "clear majority",
+			points:  []float64{3, 3, 3, 7},
+			weights: []float64{0.9, 0.9}, // 即使目标点靠近 7 所在的角点
+			want:    3,
+		},
+		{
+			// 2:2 平局时选择离目标点最近的类别
+			name:    "tie broken by distance",
+			points:  []float64{1, 1, 2, 2},
+			weights: []float64{0.8, 0.5},
+			want:    2,
+		},
+		{
+			// 距离也相同时选择数值较小的类别
+			name:    "tie broken by value",
+			points:  []float64{5, 2, 2, 5},
+			weights: []float64{0.5, 0.5},
+			want:    2,
+		},
+		{
+			// 按距离加权时，靠近的单个点可以胜过较远的两个点
+			name:     "distance weighted",
+			weighted: true,
+			points:   []float64{4, 9, 9, 1},
+			weights:  []float64{0.1, 0.1},
+			want:     4,
+		},
+		{
+			name:     "weighted exact grid point",
+			weighted: true,
+			points:   []float64{4, 9, 9, 9},
+			weights:  []float64{0, 0},
+			want:     4,
+		},
+		{
+			name:    "missing values ignored",
+			points:  []float64{math.NaN(), 6, math.NaN(), 8},
+			weights: []float64{0.5, 0.6},
+			want:    6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModeInterpolator(2, tt.weighted)
+			assert.Equal(t, tt.want, m.Interpolate(tt.points, tt.weights))
+		})
+	}
+
+	t.Run("all missing", func(t *testing.T) {
+		m := &ModeInterpolator{}
+		assert.True(t, math.IsNaN(m.Interpolate([]float64{math.NaN(), math.NaN(), math.NaN(), math.NaN()}, []float64{0.5, 0.5})))
+	})
+}
+
+func TestModeInterpolator_Stencil(t *testing.T) {
+	m := NewModeInterpolator(4, false)
+	assert.Equal(t, 4, m.StencilSize())
+	assert.Equal(t, 2, (&ModeInterpolator{}).StencilSize())
+
+	points := []float64{
+		1, 1, 1, 1,
+		1, 2, 2, 3,
+		3, 2, 2, 3,
+		3, 3, 3, 3,
+	}
+	assert.Equal(t, 3.0, m.Interpolate(points, []float64{0.5, 0.5}))
+
+	// 边缘只有四个点时仍按四点计票
+	assert.Equal(t, 2.0, m.Interpolate([]float64{2, 2, 2, 1}, []float64{0.5, 0.5}))
+}
+
+func TestModeInterpolator_Reduce(t *testing.T) {
+	m := &ModeInterpolator{}
+	assert.Equal(t, 5.0, m.Reduce([]float64{5, 1, 5, 2, 5, 1}))
+	assert.Equal(t, 1.0, m.Reduce([]float64{2, 1, 2, 1}))
+	assert.Equal(t, 7.0, m.Reduce([]float64{math.NaN(), 7}))
+	assert.True(t, math.IsNaN(m.Reduce(nil)))
+}
@@ -0,0 +1,85 @@
+package interpolators
+
+import "math"
+
+// MonotoneCubicInterpolator 单调三次插值实现
+// 在 4×4 邻域上沿 x、y 方向依次做一维 Fritsch–Carlson 单调三次 Hermite 插值
+// 斜率经过限制后，每个区间内的插值结果不会越过区间两端点的取值，
+// 因此最终结果总是落在目标点所在单元格四个角点的最小值与最大值之间
+// 适用场景：
+// 1. 降水量、云量、相对湿度等有界物理量
+// 2. 需要比双线性更平滑，但不能出现负值或超过上限的场
+//
+// 算法过程（一维，points 为 y0..y3，目标点位于 y1 与 y2 之间）：
+// 1. 计算三个区间的差分 d0, d1, d2
+// 2. 在 y1, y2 处取相邻差分的平均值作为斜率；差分异号（局部极值）时斜率为 0
+// 3. 若 α² + β² > 9（α = m1/d1, β = m2/d1），按 3/√(α² + β²) 缩放斜率以保持单调
+// 4. 用三次 Hermite 基函数计算插值结果
+//
+// points 与 weights 的排列方式与 BicubicInterpolator 相同；
+// 只传入 4 个点时退化为双线性插值；没有参数，零值即可使用
+type MonotoneCubicInterpolator struct{}
+
+// NewMonotoneCubicInterpolator 创建单调三次插值器
+func NewMonotoneCubicInterpolator() *MonotoneCubicInterpolator {
+	return &MonotoneCubicInterpolator{}
+}
+
+func (mc *MonotoneCubicInterpolator) StencilSize() int {
+	return 4
+}
+
+func (mc *MonotoneCubicInterpolator) Interpolate(points []float64, weights []float64) float64 {
+	if len(points) < 16 {
+		return (&BilinearInterpolator{}).Interpolate(points, weights)
+	}
+
+	var rows [4]float64
+	for r := 0; r < 4; r++ {
+		rows[r] = monotoneCubic(points[4*r:4*r+4], weights[1])
+	}
+
+	return monotoneCubic(rows[:], weights[0])
+}
+
+// InterpolateMasked 邻域中存在缺测点时，退化为只使用目标点所在单元格有效角点的双线性插值
+// 有效角点的凸组合同样不会越界
+func (mc *MonotoneCubicInterpolator) InterpolateMasked(points []float64, weights []float64) float64 {
+	if !hasNaN(points) {
+		return mc.Interpolate(points, weights)
+	}
+	return InterpolateMasked(&BilinearInterpolator{}, innerCell(points), weights)
+}
+
+// monotoneCubic 在 y[1] 与 y[2] 之间做 Fritsch–Carlson 单调三次插值
+func monotoneCubic(y []float64, t float64) float64 {
+	d0 := y[1] - y[0]
+	d1 := y[2] - y[1]
+	d2 := y[3] - y[2]
+
+	var m1, m2 float64
+	if d0*d1 > 0 {
+		m1 = (d0 + d1) / 2
+	}
+	if d1*d2 > 0 {
+		m2 = (d1 + d2) / 2
+	}
+
+	if d1 != 0 {
+		alpha := m1 / d1
+		beta := m2 / d1
+		if s := alpha*alpha + beta*beta; s > 9 {
+			tau := 3 / math.Sqrt(s)
+			m1 = tau * alpha * d1
+			m2 = tau * beta * d1
+		}
+	}
+
+	t2 := t * t
+	t3 := t2 * t
+
+	return (2*t3-3*t2+1)*y[1] +
+		(t3-2*t2+t)*m1 +
+		(-2*t3+3*t2)*y[2] +
+		(t3-t2)*m2
+}
@@ -0,0 +1,85 @@
+package interpolators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonotoneCubicInterpolator_Interpolate(t *testing.T) {
+	mc := NewMonotoneCubicInterpolator()
+
+	t.Run("linear field", func(t *testing.T) {
+		points := make([]float64, 16)
+		for r := 0; r < 4; r++ {
+			for c := 0; c < 4; c++ {
+				points[4*r+c] = float64(c-1) + 2*float64(r-1)
+			}
+		}
+		assert.InDelta(t, 0.3+2*0.6, mc.Interpolate(points, []float64{0.6, 0.3}), 1e-10)
+	})
+
+	t.Run("corner values", func(t *testing.T) {
+		points := []float64{
+			1, 2, 3, 4,
+			5, 10, 20, 8,
+			9, 30, 40, 12,
+			13, 14, 15, 16,
+		}
+		assert.InDelta(t, 10.0, mc.Interpolate(points, []float64{0, 0}), 1e-10)
+		assert.InDelta(t, 20.0, mc.Interpolate(points, []float64{0, 1}), 1e-10)
+		assert.InDelta(t, 30.0, mc.Interpolate(points, []float64{1, 0}), 1e-10)
+		assert.InDelta(t, 40.0, mc.Interpolate(points, []float64{1, 1}), 1e-10)
+	})
+
+	t.Run("stays within cell bounds", func(t *testing.T) {
+		// 孤立的强降水点：双三次插值会在其周围产生负值
+		points := []float64{
+			0, 0, 0, 0,
+			0, 0, 0, 0,
+			0, 0, 0, 50,
+			0, 0, 0, 0,
+		}
+
+		bc := NewBicubicInterpolator()
+		assert.Less(t, bc.Interpolate(points, []float64{0.8, 0.5}), 0.0)
+
+		for _, y := range []float64{0, 0.1, 0.25, 0.5, 0.75, 0.9, 1} {
+			for _, x := range []float64{0, 0.1, 0.25, 0.5, 0.75, 0.9, 1} {
+				got := mc.Interpolate(points, []float64{y, x})
+				assert.GreaterOrEqual(t, got, 0.0)
+				assert.LessOrEqual(t, got, 50.0)
+			}
+		}
+	})
+
+	t.Run("bilinear fallback", func(t *testing.T) {
+		points := []float64{10, 20, 30, 40}
+		weights := []float64{0.3, 0.6}
+		assert.InDelta(t, (&BilinearInterpolator{}).Interpolate(points, weights), mc.Interpolate(points, weights), 1e-10)
+	})
+}
+
+func TestClippedInterpolator_Interpolate(t *testing.T) {
+	points := []float64{
+		0, 0, 0, 0,
+		0, 0, 0, 0,
+		0, 0, 0, 50,
+		0, 0, 0, 0,
+	}
+	weights := []float64{0.8, 0.5}
+
+	bc := NewBicubicInterpolator()
+	clipped := NewClippedInterpolator(bc)
+
+	assert.Less(t, bc.Interpolate(points, weights), 0.0)
+	assert.Equal(t, 0.0, clipped.Interpolate(points, weights))
+	assert.Equal(t, 4, clipped.StencilSize())
+	assert.Equal(t, 2, NewClippedInterpolator(&BilinearInterpolator{}).StencilSize())
+
+	// 结果在范围内时不做修改
+	for i := range points {
+		points[i] = float64(i)
+	}
+	assert.Equal(t, bc.Interpolate(points, weights), clipped.Interpolate(points, weights))
+}
@@ -1,5 +1,7 @@
 package interpolators
 
+import "math"
+
 // NearestInterpolator 最近邻插值实现
 // 最近邻插值是最简单的插值方法，直接使用距离目标点最近的已知点的值
 // 适用场景：
@@ -30,3 +32,25 @@ func (n *NearestInterpolator) Interpolate(points []float64, weights []float64) f
 		return points[3] // 右上角点最近
 	}
 }
+
+// InterpolateMasked 返回离目标点最近的有效点的值
+func (n *NearestInterpolator) InterpolateMasked(points []float64, weights []float64) float64 {
+	if !hasNaN(points) {
+		return n.Interpolate(points, weights)
+	}
+
+	size := int(math.Round(math.Sqrt(float64(len(points)))))
+	result, best := math.NaN(), math.Inf(1)
+	for i, point := range points {
+		if math.IsNaN(point) {
+			continue
+		}
+
+		dy, dx := stencilOffset(i, size)
+		if d := math.Hypot(dy-weights[0], dx-weights[1]); d < best {
+			result, best = point, d
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,174 @@
+package grids
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/scorix/walg/pkg/geo/distance"
+	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+)
+
+// ErrNoMatchingSurface 表示搜索范围内没有与目标地表类型匹配的有效网格点
+var ErrNoMatchingSurface = errors.New("no neighbour with matching surface type")
+
+// SurfaceType 查询点的地表类型
+type SurfaceType int
+
+const (
+	// SurfaceAny 不区分地表类型
+	SurfaceAny SurfaceType = iota
+	// SurfaceLand 陆地，海陆掩码值 >= 0.5
+	SurfaceLand
+	// SurfaceSea 海洋，海陆掩码值 < 0.5
+	SurfaceSea
+)
+
+func (s SurfaceType) String() string {
+	switch s {
+	case SurfaceAny:
+		return "any"
+	case SurfaceLand:
+		return "land"
+	case SurfaceSea:
+		return "sea"
+	default:
+		return fmt.Sprintf("SurfaceType(%d)", int(s))
+	}
+}
+
+// Matches 判断海陆掩码值（陆地比例，0 为海洋，1 为陆地）是否属于该地表类型
+func (s SurfaceType) Matches(maskValue float64) bool {
+	switch s {
+	case SurfaceLand:
+		return maskValue >= 0.5
+	case SurfaceSea:
+		return maskValue < 0.5
+	default:
+		return true
+	}
+}
+
+// surfaceMask 海陆掩码场及扩大搜索的范围
+type surfaceMask struct {
+	reader ValueReader
+	radius int
+}
+
+// WithLandSeaMask 设置海陆掩码场
+// mask 与数据场使用相同的网格和扫描方式，取值为陆地比例，只读取第 0 个时间步；
+// searchRadius 为四个相邻点都不匹配时向外扩大搜索的最大圈数
+// 返回插值器本身，便于链式调用
+func (g *GridInterpolator) WithLandSeaMask(mask ValueReader, searchRadius int) *GridInterpolator {
+	g.mask = &surfaceMask{reader: mask, radius: searchRadius}
+	return g
+}
+
+// InterpolateAtSurface 只使用与目标地表类型相同的相邻点进行插值
+// 例如沿海陆地站点的 2 米温度只使用陆地格点，避免混入海洋格点造成数度的误差
+// 相邻点中只有部分匹配时按匹配的点重新归一化权重；
+// 都不匹配时逐圈向外搜索，返回最先找到的一圈中离目标点最近的匹配点的值
+func (g *GridInterpolator) InterpolateAtSurface(timeStep int, lat, lon float64, surface SurfaceType) (float64, error) {
+	if surface == SurfaceAny {
+		return g.InterpolateAt(timeStep, lat, lon)
+	}
+	if g.mask == nil {
+		return 0, errors.New("land-sea mask is not set")
+	}
+
+	indices, weights, err := g.neighbours(lat, lon)
+	if err != nil {
+		return 0, err
+	}
+
+	maskValues, err := g.readPoints(g.mask.reader, &interpolators.AverageInterpolator{}, 0, indices)
+	if err != nil {
+		return 0, err
+	}
+
+	exclude := make([]bool, len(indices))
+	var matched int
+	for i, v := range maskValues {
+		exclude[i] = !surface.Matches(v)
+		if !exclude[i] {
+			matched++
+		}
+	}
+
+	if matched > 0 {
+		value, err := g.interpolate(timeStep, lat, lon, indices, weights, exclude)
+		if !errors.Is(err, ErrNoMatchingSurface) {
+			return value, err
+		}
+	}
+
+	return g.searchSurface(timeStep, lat, lon, surface)
+}
+
+// searchSurface 从最近点开始逐圈向外搜索与地表类型匹配的有效网格点
+func (g *GridInterpolator) searchSurface(timeStep int, lat, lon float64, surface SurfaceType) (float64, error) {
+	latIdx, lonIdx := g.grid.GetNearestIndex(lat, lon)
+	latSize := len(g.grid.Latitudes())
+	lonSize := len(g.grid.Longitudes())
+	wrap := isSphere(g.grid)
+
+	for r := 1; r <= g.mask.radius; r++ {
+		value, best := math.NaN(), math.Inf(1)
+
+		for di := -r; di <= r; di++ {
+			for dj := -r; dj <= r; dj++ {
+				// 只检查当前这一圈
+				if max(abs(di), abs(dj)) != r {
+					continue
+				}
+
+				i, j := latIdx+di, lonIdx+dj
+				if i < 0 || i >= latSize {
+					continue
+				}
+				if wrap {
+					j = wrapIndex(j, lonSize)
+				}
+				idx := GridIndexFromIndices(g.grid, i, j, g.scanningMode)
+				if idx < 0 {
+					continue
+				}
+
+				maskValue, err := g.mask.reader.ReadValueAt(0, idx)
+				if err != nil {
+					return 0, err
+				}
+				if !surface.Matches(maskValue) {
+					continue
+				}
+
+				v, err := g.reader.ReadValueAt(timeStep, idx)
+				if err != nil {
+					return 0, err
+				}
+				if math.IsNaN(v) || (g.missing.policy != MissingIgnore && g.missing.isMissing(v)) {
+					continue
+				}
+
+				pLat, pLon := geographicPoint(g.grid, i, j)
+				d := distance.Haversine(lat, lon, pLat, pLon)
+				if d < best {
+					value, best = v, d
+				}
+			}
+		}
+
+		if !math.IsNaN(value) {
+			return value, nil
+		}
+	}
+
+	return 0, ErrNoMatchingSurface
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,73 @@
+package grids_test
+
+import (
+	"testing"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGridInterpolator_InterpolateAtSurface(t *testing.T) {
+	// 经度 <= 104 为陆地，陆地温度 20，海洋温度 10
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	isLand := func(lon float64) bool { return lon <= 104 }
+
+	mask := &funcReader{grid: grid, f: func(lat, lon float64) float64 {
+		if isLand(lon) {
+			return 1
+		}
+		return 0
+	}}
+	temperature := &funcReader{grid: grid, f: func(lat, lon float64) float64 {
+		if isLand(lon) {
+			return 20
+		}
+		return 10
+	}}
+
+	tests := []struct {
+		name    string
+		radius  int
+		surface grids.SurfaceType
+		lat     float64
+		lon     float64
+		want    float64
+		wantErr error
+	}{
+		{name: "coastal land station", radius: 3, surface: grids.SurfaceLand, lat: 5.5, lon: 104.3, want: 20},
+		{name: "coastal sea point", radius: 3, surface: grids.SurfaceSea, lat: 5.5, lon: 104.3, want: 10},
+		{name: "any surface mixes", radius: 3, surface: grids.SurfaceAny, lat: 5.5, lon: 104.5, want: 15},
+		{name: "widen search", radius: 3, surface: grids.SurfaceLand, lat: 5.2, lon: 106.8, want: 20},
+		{name: "search radius exhausted", radius: 2, surface: grids.SurfaceLand, lat: 5.2, lon: 106.8, wantErr: grids.ErrNoMatchingSurface},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gi := grids.NewGridInterpolator(temperature, grid, 0, nil).WithLandSeaMask(mask, tt.radius)
+			got, err := gi.InterpolateAtSurface(0, tt.lat, tt.lon, tt.surface)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 1e-10)
+		})
+	}
+
+	t.Run("mask not set", func(t *testing.T) {
+		gi := grids.NewGridInterpolator(temperature, grid, 0, nil)
+		_, err := gi.InterpolateAtSurface(0, 5.5, 104.3, grids.SurfaceLand)
+		assert.Error(t, err)
+	})
+}
+
+func TestSurfaceType_Matches(t *testing.T) {
+	assert.True(t, grids.SurfaceLand.Matches(1))
+	assert.True(t, grids.SurfaceLand.Matches(0.5))
+	assert.False(t, grids.SurfaceLand.Matches(0.2))
+	assert.True(t, grids.SurfaceSea.Matches(0))
+	assert.False(t, grids.SurfaceSea.Matches(0.7))
+	assert.True(t, grids.SurfaceAny.Matches(0.3))
+}
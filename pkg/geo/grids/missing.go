@@ -0,0 +1,133 @@
+package grids
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/scorix/walg/pkg/geo/distance"
+)
+
+// ErrMissingValue 表示插值所需的网格点缺测
+var ErrMissingValue = errors.New("missing value")
+
+// MissingValueError 插值邻域中存在缺测点时返回的错误
+type MissingValueError struct {
+	TimeStep int
+	Lat      float64
+	Lon      float64
+	Missing  int // 缺测点个数
+	Total    int // 邻域点总数
+}
+
+func (e *MissingValueError) Error() string {
+	return fmt.Sprintf("%d of %d neighbours missing at time step %d, point (%f, %f)",
+		e.Missing, e.Total, e.TimeStep, e.Lat, e.Lon)
+}
+
+func (e *MissingValueError) Unwrap() error {
+	return ErrMissingValue
+}
+
+// MissingPolicy 缺测值处理策略
+type MissingPolicy int
+
+const (
+	// MissingIgnore 不识别缺测值，原样参与插值（默认）
+	MissingIgnore MissingPolicy = iota
+	// MissingRenormalize 只使用有效的相邻点，并按有效点重新归一化权重
+	MissingRenormalize
+	// MissingNearestValid 存在缺测点时，使用离目标点最近的有效点的值
+	MissingNearestValid
+	// MissingFail 存在缺测点时返回 MissingValueError
+	MissingFail
+)
+
+func (p MissingPolicy) String() string {
+	switch p {
+	case MissingIgnore:
+		return "ignore"
+	case MissingRenormalize:
+		return "renormalize"
+	case MissingNearestValid:
+		return "nearest-valid"
+	case MissingFail:
+		return "fail"
+	default:
+		return fmt.Sprintf("MissingPolicy(%d)", int(p))
+	}
+}
+
+// missingValues 缺测值识别规则：NaN 以及任意一个哨兵值（如 GRIB 位图缺测、NetCDF _FillValue）
+type missingValues struct {
+	policy    MissingPolicy
+	sentinels []float64
+}
+
+func (m *missingValues) isMissing(v float64) bool {
+	if math.IsNaN(v) {
+		return true
+	}
+	for _, s := range m.sentinels {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// mask 将缺测点替换为 NaN，返回缺测点个数
+func (m *missingValues) mask(points []float64) int {
+	var n int
+	for i, v := range points {
+		if m.isMissing(v) {
+			points[i] = math.NaN()
+			n++
+		}
+	}
+	return n
+}
+
+// WithMissingValues 设置缺测值处理策略，NaN 总是视为缺测，sentinels 为额外的缺测哨兵值
+// 返回插值器本身，便于链式调用
+func (g *GridInterpolator) WithMissingValues(policy MissingPolicy, sentinels ...float64) *GridInterpolator {
+	g.missing = missingValues{policy: policy, sentinels: sentinels}
+	return g
+}
+
+// interpolateMissing 按缺测值策略处理含缺测点的邻域
+func (g *GridInterpolator) interpolateMissing(timeStep int, lat, lon float64, indices []int, points, magnitudes, weights []float64, missing int) (float64, error) {
+	missingErr := &MissingValueError{TimeStep: timeStep, Lat: lat, Lon: lon, Missing: missing, Total: len(points)}
+
+	switch g.missing.policy {
+	case MissingRenormalize:
+		value := g.interpolateMasked(points, magnitudes, weights)
+		if math.IsNaN(value) {
+			return 0, missingErr
+		}
+		return value, nil
+
+	case MissingNearestValid:
+		value, best := math.NaN(), math.Inf(1)
+		for i, idx := range indices {
+			if math.IsNaN(points[i]) {
+				continue
+			}
+			latIdx, lonIdx, ok := GridIndicesFromIndex(g.grid, idx, g.scanningMode)
+			if !ok {
+				continue
+			}
+			pLat, pLon := geographicPoint(g.grid, latIdx, lonIdx)
+			if d := distance.Haversine(lat, lon, pLat, pLon); d < best {
+				value, best = points[i], d
+			}
+		}
+		if math.IsNaN(value) {
+			return 0, missingErr
+		}
+		return value, nil
+
+	default:
+		return 0, missingErr
+	}
+}
@@ -0,0 +1,128 @@
+package grids_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGridInterpolator_MissingValues(t *testing.T) {
+	grid := &mockGrid{
+		lats: []float64{30.0, 31.0},
+		lons: []float64{120.0, 121.0},
+	}
+
+	// 右上角为 9999 哨兵值，右下角为 NaN
+	reader := &mockReader{
+		values: map[int]float64{
+			0: 10.0,
+			1: math.NaN(),
+			2: 15.0,
+			3: 9999,
+		},
+	}
+
+	tests := []struct {
+		name         string
+		policy       grids.MissingPolicy
+		interpolator interpolators.Interpolator
+		lat          float64
+		lon          float64
+		want         float64
+		wantErr      bool
+	}{
+		{
+			name:   "renormalize bilinear",
+			policy: grids.MissingRenormalize,
+			lat:    30.5,
+			lon:    120.5,
+			want:   12.5,
+		},
+		{
+			name:         "renormalize idw",
+			policy:       grids.MissingRenormalize,
+			interpolator: interpolators.NewIDWInterpolator(2),
+			lat:          30.5,
+			lon:          120.5,
+			want:         12.5,
+		},
+		{
+			name:         "renormalize nearest",
+			policy:       grids.MissingRenormalize,
+			interpolator: &interpolators.NearestInterpolator{},
+			lat:          30.2,
+			lon:          120.8,
+			want:         10,
+		},
+		{
+			name:   "nearest valid",
+			policy: grids.MissingNearestValid,
+			lat:    30.6,
+			lon:    120.9,
+			want:   15,
+		},
+		{
+			name:    "fail",
+			policy:  grids.MissingFail,
+			lat:     30.5,
+			lon:     120.5,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gi := grids.NewGridInterpolator(reader, grid, 0, tt.interpolator).WithMissingValues(tt.policy, 9999)
+			got, err := gi.InterpolateAt(0, tt.lat, tt.lon)
+			if tt.wantErr {
+				var missingErr *grids.MissingValueError
+				require.ErrorAs(t, err, &missingErr)
+				assert.ErrorIs(t, err, grids.ErrMissingValue)
+				assert.Equal(t, 2, missingErr.Missing)
+				assert.Equal(t, 4, missingErr.Total)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 1e-10)
+		})
+	}
+}
+
+func TestGridInterpolator_MissingValues_AllMissing(t *testing.T) {
+	grid := &mockGrid{
+		lats: []float64{30.0, 31.0},
+		lons: []float64{120.0, 121.0},
+	}
+	reader := &mockReader{
+		values: map[int]float64{0: -999, 1: -999, 2: math.NaN(), 3: -999},
+	}
+
+	for _, policy := range []grids.MissingPolicy{grids.MissingRenormalize, grids.MissingNearestValid, grids.MissingFail} {
+		t.Run(policy.String(), func(t *testing.T) {
+			gi := grids.NewGridInterpolator(reader, grid, 0, nil).WithMissingValues(policy, -999)
+			_, err := gi.InterpolateAt(0, 30.5, 120.5)
+			assert.True(t, errors.Is(err, grids.ErrMissingValue))
+		})
+	}
+}
+
+func TestGridInterpolator_MissingValues_Ignore(t *testing.T) {
+	grid := &mockGrid{
+		lats: []float64{30.0, 31.0},
+		lons: []float64{120.0, 121.0},
+	}
+	reader := &mockReader{
+		values: map[int]float64{0: 10, 1: 20, 2: 15, 3: 9999},
+	}
+
+	// 默认策略下哨兵值原样参与插值
+	gi := grids.NewGridInterpolator(reader, grid, 0, nil)
+	got, err := gi.InterpolateAt(0, 30.5, 120.5)
+	require.NoError(t, err)
+	assert.InDelta(t, (10+20+15+9999)/4.0, got, 1e-10)
+}
@@ -0,0 +1,398 @@
+package grids
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+)
+
+// Point 经纬度坐标
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// planEntry 一个目标点的插值方案，Indices 为空表示该点位于网格之外
+type planEntry struct {
+	indices []int
+	weights []float64
+}
+
+// InterpolationPlan 针对固定目标点集合预先计算好的插值方案
+// 对同一批站点反复插值（每个时间步、每个要素）时，网格索引和插值权重只需计算一次，
+// 之后可以作用于任意 ValueReader 和时间步
+// 方案可以通过 WriteTo 序列化，服务启动时用 ReadInterpolationPlan 加载
+type InterpolationPlan struct {
+	interpolator interpolators.Interpolator
+	gridSize     int // 创建方案时网格的点数
+	points       []Point
+	entries      []planEntry
+	poleRows     map[int][]int // 虚拟极点对应的最外侧纬度行网格索引
+	flat         []int         // 所有目标点依次需要读取的网格索引，不含虚拟极点
+}
+
+var (
+	// ErrPlanUnsupported 插值器配置了插值方案无法复现的选项
+	ErrPlanUnsupported = errors.New("interpolation plan does not support missing values, land-sea mask or magnitude weighting")
+	// ErrInvalidPlan 序列化的插值方案格式错误或网格索引无效
+	ErrInvalidPlan = errors.New("invalid interpolation plan")
+)
+
+// NewInterpolationPlan 在网格上为一组目标点创建插值方案
+// interpolator 为空时使用双线性插值；位于网格之外的点在应用方案时结果为 NaN
+// 方案的结果与未设置缺测值策略、海陆掩码和模长场的 GridInterpolator 相同
+func NewInterpolationPlan(grid Grid, mode ScanMode, interpolator interpolators.Interpolator, points []Point) *InterpolationPlan {
+	p, _ := NewGridInterpolator(nil, grid, mode, interpolator).Plan(points)
+	return p
+}
+
+// Plan 使用插值器的网格和插值算法为一组目标点创建插值方案
+// 方案不会重放缺测值处理、海陆掩码和模长加权，设置了这些选项时返回 ErrPlanUnsupported
+func (g *GridInterpolator) Plan(points []Point) (*InterpolationPlan, error) {
+	if g.missing.policy != MissingIgnore || g.mask != nil || g.magnitude != nil {
+		return nil, ErrPlanUnsupported
+	}
+
+	grid, mode := g.grid, g.scanningMode
+	p := &InterpolationPlan{
+		interpolator: g.interpolator,
+		gridSize:     grid.Size(),
+		points:       points,
+		entries:      make([]planEntry, len(points)),
+		poleRows:     make(map[int][]int),
+	}
+
+	for i, pt := range points {
+		indices, weights, err := g.neighbours(pt.Lat, pt.Lon)
+		if err != nil {
+			continue
+		}
+
+		for _, idx := range indices {
+			if isPoleIndex(idx) && p.poleRows[idx] == nil {
+				p.poleRows[idx] = poleRowIndices(grid, mode, idx)
+			}
+		}
+		p.entries[i] = planEntry{indices: indices, weights: weights}
+	}
+	p.flatten()
+
+	return p, nil
+}
+
+// flatten 将所有目标点需要读取的网格索引展开为一个数组，以便一次性批量读取
+func (p *InterpolationPlan) flatten() {
+	p.flat = p.flat[:0]
+	for _, e := range p.entries {
+		for _, idx := range e.indices {
+			if !isPoleIndex(idx) {
+				p.flat = append(p.flat, idx)
+			}
+		}
+	}
+}
+
+// Len 返回目标点个数
+func (p *InterpolationPlan) Len() int {
+	return len(p.points)
+}
+
+// Points 返回目标点
+func (p *InterpolationPlan) Points() []Point {
+	return p.points
+}
+
+// GridSize 返回创建方案时网格的点数，加载的方案只能用于点数相同的网格
+func (p *InterpolationPlan) GridSize() int {
+	return p.gridSize
+}
+
+// Apply 按方案对指定时间步插值，结果按目标点顺序写入 dst
+// dst 的长度不能小于目标点个数；reader 实现了 BatchValueReader 时所有网格点一次性读取
+func (p *InterpolationPlan) Apply(reader ValueReader, timeStep int, dst []float64) error {
+	return p.ApplyContext(context.Background(), reader, timeStep, dst)
+}
+
+// ApplyContext 与 Apply 相同，但读取数据时会传入 ctx，取消后返回 ctx.Err()
+func (p *InterpolationPlan) ApplyContext(ctx context.Context, reader ValueReader, timeStep int, dst []float64) error {
+	reader = bindContext(ctx, reader)
+
+	if len(dst) < len(p.entries) {
+		return fmt.Errorf("destination too short: %d < %d", len(dst), len(p.entries))
+	}
+
+	values := make([]float64, len(p.flat))
+	if err := readValues(reader, timeStep, p.flat, values); err != nil {
+		return err
+	}
+
+	poles := make(map[int]float64, len(p.poleRows))
+	var buf []float64
+	next := 0
+
+	for i, e := range p.entries {
+		if e.indices == nil {
+			dst[i] = math.NaN()
+			continue
+		}
+
+		if cap(buf) < len(e.indices) {
+			buf = make([]float64, len(e.indices))
+		}
+		points := buf[:len(e.indices)]
+		for k, idx := range e.indices {
+			if isPoleIndex(idx) {
+				v, ok := poles[idx]
+				if !ok {
+					var err error
+					v, err = reducePole(reader, poleReducer(p.interpolator), timeStep, p.poleRows[idx], nil)
+					if err != nil {
+						return err
+					}
+					poles[idx] = v
+				}
+				points[k] = v
+				continue
+			}
+
+			points[k] = values[next]
+			next++
+		}
+
+		dst[i] = p.interpolator.Interpolate(points, e.weights)
+	}
+
+	return nil
+}
+
+// planMagic 插值方案序列化格式的文件头
+var planMagic = [8]byte{'W', 'A', 'L', 'G', 'P', 'L', 'A', 'N'}
+
+const planVersion uint32 = 2
+
+// WriteTo 将插值方案以小端二进制格式写入 w
+// 插值算法本身不会被序列化，加载时需要重新指定
+func (p *InterpolationPlan) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	write := func(v any) {
+		if cw.err == nil {
+			cw.err = binary.Write(cw, binary.LittleEndian, v)
+		}
+	}
+
+	write(planMagic)
+	write(planVersion)
+	write(int64(p.gridSize))
+	write(uint32(len(p.points)))
+	for i, pt := range p.points {
+		e := p.entries[i]
+		write([2]float64{pt.Lat, pt.Lon})
+		write(uint32(len(e.indices)))
+		for _, idx := range e.indices {
+			write(int64(idx))
+		}
+		if len(e.indices) > 0 {
+			write(uint32(len(e.weights)))
+			write(e.weights)
+		}
+	}
+
+	poles := slices.Sorted(maps.Keys(p.poleRows))
+	write(uint32(len(poles)))
+	for _, pole := range poles {
+		row := p.poleRows[pole]
+		write(int64(pole))
+		write(uint32(len(row)))
+		for _, idx := range row {
+			write(int64(idx))
+		}
+	}
+
+	if cw.err == nil {
+		cw.err = cw.w.(*bufio.Writer).Flush()
+	}
+
+	return cw.n, cw.err
+}
+
+// ReadInterpolationPlan 读取由 WriteTo 写出的插值方案
+// interpolator 必须与创建方案时使用的插值算法一致，为空时使用双线性插值；
+// 网格索引超出方案记录的网格点数、虚拟极点没有对应的纬度行或权重个数不对时返回 ErrInvalidPlan
+func ReadInterpolationPlan(r io.Reader, interpolator interpolators.Interpolator) (*InterpolationPlan, error) {
+	if interpolator == nil {
+		interpolator = &interpolators.BilinearInterpolator{}
+	}
+
+	br := bufio.NewReader(r)
+	var err error
+	read := func(v any) {
+		if err == nil {
+			err = binary.Read(br, binary.LittleEndian, v)
+		}
+	}
+
+	var magic [8]byte
+	var version uint32
+	read(&magic)
+	read(&version)
+	if err != nil {
+		return nil, err
+	}
+	if magic != planMagic {
+		return nil, fmt.Errorf("%w: bad magic", ErrInvalidPlan)
+	}
+	if version != planVersion {
+		return nil, fmt.Errorf("unsupported interpolation plan version: %d", version)
+	}
+
+	var size int64
+	var count uint32
+	read(&size)
+	read(&count)
+	if err == nil && (size < 1 || size > math.MaxInt32) {
+		return nil, fmt.Errorf("%w: grid size %d", ErrInvalidPlan, size)
+	}
+
+	p := &InterpolationPlan{
+		interpolator: interpolator,
+		gridSize:     int(size),
+		poleRows:     make(map[int][]int),
+	}
+	for i := uint32(0); i < count && err == nil; i++ {
+		var pt [2]float64
+		var n uint32
+		read(&pt)
+		read(&n)
+
+		var e planEntry
+		if n > 0 && err == nil {
+			e.indices, err = readPlanIndices(br, n)
+
+			var w uint32
+			read(&w)
+			if err == nil && w != 2 {
+				return nil, fmt.Errorf("%w: entry %d has %d weights", ErrInvalidPlan, i, w)
+			}
+			e.weights = make([]float64, 2)
+			read(e.weights)
+		}
+		if err == nil {
+			if verr := p.checkEntry(e); verr != nil {
+				return nil, fmt.Errorf("%w: entry %d: %w", ErrInvalidPlan, i, verr)
+			}
+		}
+
+		p.points = append(p.points, Point{Lat: pt[0], Lon: pt[1]})
+		p.entries = append(p.entries, e)
+	}
+
+	var poles uint32
+	read(&poles)
+	for i := uint32(0); i < poles && err == nil; i++ {
+		var pole int64
+		var n uint32
+		read(&pole)
+		read(&n)
+		if err != nil {
+			break
+		}
+		if !isPoleIndex(int(pole)) || n == 0 {
+			return nil, fmt.Errorf("%w: pole %d with %d points", ErrInvalidPlan, pole, n)
+		}
+
+		var row []int
+		if row, err = readPlanIndices(br, n); err == nil {
+			if verr := p.checkIndices(row, false); verr != nil {
+				return nil, fmt.Errorf("%w: pole %d: %w", ErrInvalidPlan, pole, verr)
+			}
+			p.poleRows[int(pole)] = row
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read interpolation plan: %w", err)
+	}
+
+	// 每个虚拟极点都需要对应的纬度行
+	for i, e := range p.entries {
+		for _, idx := range e.indices {
+			if isPoleIndex(idx) && p.poleRows[idx] == nil {
+				return nil, fmt.Errorf("%w: entry %d uses pole %d without a row", ErrInvalidPlan, i, idx)
+			}
+		}
+	}
+	p.flatten()
+
+	return p, nil
+}
+
+// checkEntry 检查一个目标点的网格索引个数和取值，以及权重是否在 [0, 1] 之间
+// 相邻点个数为 4 或者更大的正方形邻域
+func (p *InterpolationPlan) checkEntry(e planEntry) error {
+	if len(e.indices) == 0 {
+		return nil
+	}
+
+	k := int(math.Round(math.Sqrt(float64(len(e.indices)))))
+	if k < 2 || k*k != len(e.indices) {
+		return fmt.Errorf("%d neighbours", len(e.indices))
+	}
+	for _, w := range e.weights {
+		if !(w >= 0 && w <= 1) {
+			return fmt.Errorf("weight %v out of range", w)
+		}
+	}
+	return p.checkIndices(e.indices, true)
+}
+
+// checkIndices 检查网格索引是否在网格范围内，poles 为 true 时允许虚拟极点
+func (p *InterpolationPlan) checkIndices(indices []int, poles bool) error {
+	for _, idx := range indices {
+		if (idx < 0 || idx >= p.gridSize) && !(poles && isPoleIndex(idx)) {
+			return fmt.Errorf("grid index %d out of range for %d points", idx, p.gridSize)
+		}
+	}
+	return nil
+}
+
+// planChunk 读取索引数组时每次分配的最大元素个数
+// 数组长度来自文件，按块读取可以避免损坏的文件一次性申请过大的内存，数据不足时以 EOF 结束
+const planChunk = 4096
+
+// readPlanIndices 按块读取 n 个 int64 网格索引
+func readPlanIndices(r io.Reader, n uint32) ([]int, error) {
+	indices := make([]int, 0, min(n, planChunk))
+	chunk := make([]int64, min(n, planChunk))
+	for remaining := int(n); remaining > 0; remaining -= len(chunk) {
+		chunk = chunk[:min(remaining, planChunk)]
+		if err := binary.Read(r, binary.LittleEndian, chunk); err != nil {
+			return nil, err
+		}
+		for _, v := range chunk {
+			indices = append(indices, int(v))
+		}
+	}
+
+	return indices, nil
+}
+
+// countingWriter 统计写入的字节数
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,214 @@
+package grids_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/gaussian"
+	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// timeReader 取值随时间步变化的读取器
+type timeReader struct {
+	grid grids.Grid
+	mode grids.ScanMode
+}
+
+func (r *timeReader) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	lat, lon, _ := grids.GridPoint(r.grid, gridIndex, r.mode)
+	return lat + math.Sin(lon*math.Pi/180)*float64(timeStep+1), nil
+}
+
+func TestInterpolationPlan(t *testing.T) {
+	grid := gaussian.NewRegular(48)
+	mode := grids.ScanModePositiveJ
+	reader := &timeReader{grid: grid, mode: mode}
+
+	points := []grids.Point{
+		{Lat: 31.23, Lon: 121.47},
+		{Lat: -33.87, Lon: 151.21},
+		{Lat: 51.5, Lon: -0.12},
+		{Lat: 89.9, Lon: 10},   // 极点附近
+		{Lat: -89.5, Lon: 200}, // 极点附近
+	}
+
+	for _, interpolator := range []interpolators.Interpolator{
+		nil,
+		interpolators.NewBicubicInterpolator(),
+		interpolators.NewIDWInterpolator(2),
+	} {
+		plan := grids.NewInterpolationPlan(grid, mode, interpolator, points)
+		require.Equal(t, len(points), plan.Len())
+
+		gi := grids.NewGridInterpolator(reader, grid, mode, interpolator)
+		dst := make([]float64, plan.Len())
+		for step := 0; step < 3; step++ {
+			require.NoError(t, plan.Apply(reader, step, dst))
+
+			for i, pt := range points {
+				want, err := gi.InterpolateAt(step, pt.Lat, pt.Lon)
+				require.NoError(t, err)
+				assert.InDelta(t, want, dst[i], 1e-12)
+			}
+		}
+	}
+}
+
+func TestInterpolationPlan_Outside(t *testing.T) {
+	grid := &mockGrid{lats: []float64{30, 31}, lons: []float64{120, 121}}
+	reader := &mockReader{values: map[int]float64{0: 10, 1: 20, 2: 15, 3: 25}}
+
+	plan := grids.NewInterpolationPlan(grid, 0, nil, []grids.Point{{Lat: 30.5, Lon: 120.5}, {Lat: 40, Lon: 120}})
+
+	dst := make([]float64, 2)
+	require.NoError(t, plan.Apply(reader, 0, dst))
+	assert.InDelta(t, 17.5, dst[0], 1e-12)
+	assert.True(t, math.IsNaN(dst[1]))
+
+	assert.Error(t, plan.Apply(reader, 0, make([]float64, 1)))
+	assert.Error(t, plan.Apply(reader, -1, dst))
+}
+
+func TestInterpolationPlan_Serialization(t *testing.T) {
+	grid := gaussian.NewRegular(48)
+	reader := &timeReader{grid: grid}
+	points := []grids.Point{
+		{Lat: 31.23, Lon: 121.47},
+		{Lat: 89.9, Lon: 10},
+		{Lat: -89.9, Lon: 10},
+	}
+
+	plan := grids.NewInterpolationPlan(grid, 0, nil, points)
+
+	var buf bytes.Buffer
+	n, err := plan.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	loaded, err := grids.ReadInterpolationPlan(bytes.NewReader(buf.Bytes()), nil)
+	require.NoError(t, err)
+	assert.Equal(t, points, loaded.Points())
+	assert.Equal(t, grid.Size(), loaded.GridSize())
+
+	want := make([]float64, plan.Len())
+	got := make([]float64, loaded.Len())
+	require.NoError(t, plan.Apply(reader, 1, want))
+	require.NoError(t, loaded.Apply(reader, 1, got))
+	assert.Equal(t, want, got)
+
+	_, err = grids.ReadInterpolationPlan(bytes.NewReader([]byte("not a plan at all")), nil)
+	assert.Error(t, err)
+
+	_, err = grids.ReadInterpolationPlan(bytes.NewReader(buf.Bytes()[:buf.Len()-3]), nil)
+	assert.Error(t, err)
+}
+
+func TestInterpolationPlan_LargeStencil(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 20, 100, 120, 1, 1)
+	reader := &timeReader{grid: grid}
+	interpolator := &interpolators.ModeInterpolator{Size: 6}
+	points := []grids.Point{{Lat: 10.3, Lon: 110.6}}
+
+	plan := grids.NewInterpolationPlan(grid, 0, interpolator, points)
+	dst := make([]float64, plan.Len())
+	require.NoError(t, plan.Apply(reader, 0, dst))
+
+	want, err := grids.NewGridInterpolator(reader, grid, 0, interpolator).InterpolateAt(0, 10.3, 110.6)
+	require.NoError(t, err)
+	assert.Equal(t, want, dst[0])
+}
+
+func TestGridInterpolator_PlanUnsupported(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 20, 100, 120, 1, 1)
+	reader := &timeReader{grid: grid}
+	points := []grids.Point{{Lat: 10.3, Lon: 110.6}}
+
+	_, err := grids.NewGridInterpolator(reader, grid, 0, nil).WithMissingValues(grids.MissingRenormalize).Plan(points)
+	assert.ErrorIs(t, err, grids.ErrPlanUnsupported)
+
+	_, err = grids.NewGridInterpolator(reader, grid, 0, nil).WithLandSeaMask(reader, 1).Plan(points)
+	assert.ErrorIs(t, err, grids.ErrPlanUnsupported)
+
+	_, err = grids.NewDirectionInterpolator(reader, reader, grid, 0, nil).Plan(points)
+	assert.ErrorIs(t, err, grids.ErrPlanUnsupported)
+
+	plan, err := grids.NewGridInterpolator(reader, grid, 0, nil).Plan(points)
+	require.NoError(t, err)
+	assert.Equal(t, 1, plan.Len())
+}
+
+// planFile 按序列化格式写出一个只有一个目标点、没有虚拟极点行的插值方案
+func planFile(gridSize int64, indices []int64, weights []float64) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("WALGPLAN")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(2))           // 版本
+	_ = binary.Write(&buf, binary.LittleEndian, gridSize)            // 网格点数
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(1))           // 目标点个数
+	_ = binary.Write(&buf, binary.LittleEndian, [2]float64{10, 110}) // 坐标
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(indices)))
+	_ = binary.Write(&buf, binary.LittleEndian, indices)
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(weights)))
+	_ = binary.Write(&buf, binary.LittleEndian, weights)
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0)) // 虚拟极点个数
+	return buf.Bytes()
+}
+
+func TestReadInterpolationPlan_CorruptCount(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("WALGPLAN")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(2))           // 版本
+	_ = binary.Write(&buf, binary.LittleEndian, int64(100))          // 网格点数
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(1))           // 目标点个数
+	_ = binary.Write(&buf, binary.LittleEndian, [2]float64{10, 110}) // 坐标
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(math.MaxUint32))
+
+	_, err := grids.ReadInterpolationPlan(bytes.NewReader(buf.Bytes()), nil)
+	assert.Error(t, err)
+}
+
+func TestReadInterpolationPlan_Invalid(t *testing.T) {
+	plan, err := grids.ReadInterpolationPlan(bytes.NewReader(planFile(100, []int64{0, 1, 10, 11}, []float64{0.5, 0.5})), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 100, plan.GridSize())
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"index out of range", planFile(100, []int64{0, 1, 10, 100}, []float64{0.5, 0.5})},
+		{"negative index", planFile(100, []int64{0, 1, 10, -1}, []float64{0.5, 0.5})},
+		{"pole without row", planFile(100, []int64{0, 1, -2, -2}, []float64{0.5, 0.5})},
+		{"neighbour count", planFile(100, []int64{0, 1, 10}, []float64{0.5, 0.5})},
+		{"weight count", planFile(100, []int64{0, 1, 10, 11}, []float64{0.5, 0.5, 0.5})},
+		{"weight range", planFile(100, []int64{0, 1, 10, 11}, []float64{0.5, 2})},
+		{"grid size", planFile(0, []int64{0, 1, 10, 11}, []float64{0.5, 0.5})},
+	}
+	for _, tt := range tests {
+		_, err := grids.ReadInterpolationPlan(bytes.NewReader(tt.data), nil)
+		assert.ErrorIs(t, err, grids.ErrInvalidPlan, tt.name)
+	}
+}
+
+func BenchmarkInterpolationPlan_Apply(b *testing.B) {
+	grid := gaussian.NewRegular(320)
+	reader := &timeReader{grid: grid}
+
+	points := make([]grids.Point, 0, 10000)
+	for i := 0; i < cap(points); i++ {
+		points = append(points, grids.Point{Lat: float64(i%170) - 85 + 0.3, Lon: float64(i%360) + 0.7})
+	}
+
+	plan := grids.NewInterpolationPlan(grid, 0, nil, points)
+	dst := make([]float64, plan.Len())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = plan.Apply(reader, 0, dst)
+	}
+}
@@ -0,0 +1,120 @@
+package grids
+
+import (
+	"math"
+	"sort"
+
+	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+)
+
+// 虚拟极点的网格索引
+// 高斯网格等全球网格的最外侧纬度行不在极点上，极点的值取最外侧纬度行的纬向平均
+const (
+	northPoleIndex = -2
+	southPoleIndex = -3
+)
+
+func isPoleIndex(idx int) bool {
+	return idx == northPoleIndex || idx == southPoleIndex
+}
+
+// polarRows 返回最北和最南纬度行的下标
+func polarRows(lats []float64) (north, south int) {
+	north, south = 0, len(lats)-1
+	if lats[north] < lats[south] {
+		north, south = south, north
+	}
+	return north, south
+}
+
+// poleNeighbours 计算位于最外侧纬度行与极点之间的点的相邻点
+// 四个点依次为最外侧纬度行上目标点两侧的两点以及两个虚拟极点，排列方式与双线性插值相同
+func (g *GridInterpolator) poleNeighbours(lat, lon float64) ([]int, []float64, bool) {
+	lats := g.grid.Latitudes()
+	lons := g.grid.Longitudes()
+	north, south := polarRows(lats)
+
+	var row, pole int
+	var poleLat float64
+	switch {
+	case lat > lats[north] && lats[north] < 90:
+		row, pole, poleLat = north, northPoleIndex, 90
+	case lat < lats[south] && lats[south] > -90:
+		row, pole, poleLat = south, southPoleIndex, -90
+	default:
+		return nil, nil, false
+	}
+
+	// 沿纬度行找到目标点西侧的经度下标
+	lon = lons[0] + math.Mod(math.Mod(lon-lons[0], 360)+360, 360)
+	j := sort.SearchFloat64s(lons, lon)
+	if j == len(lons) || lons[j] != lon {
+		j--
+	}
+
+	indices := []int{
+		GridIndexFromIndices(g.grid, row, j, g.scanningMode),
+		GridIndexFromIndices(g.grid, row, wrapIndex(j+1, len(lons)), g.scanningMode),
+		pole,
+		pole,
+	}
+	weights := []float64{
+		cellFraction(lat, lats[row], poleLat),
+		cellFraction(lon, lons[j], unwrapLon(lons, j+1)),
+	}
+
+	return indices, weights, true
+}
+
+// poleValue 计算虚拟极点的值：读取最外侧纬度行的全部点，忽略缺测后用 reducer 归并
+func (g *GridInterpolator) poleValue(reader ValueReader, reducer interpolators.Reducer, timeStep, idx int) (float64, error) {
+	return reducePole(reader, reducer, timeStep, poleRowIndices(g.grid, g.scanningMode, idx), &g.missing)
+}
+
+// poleRowIndices 返回虚拟极点所在一侧最外侧纬度行全部点的网格索引
+func poleRowIndices(g Grid, mode ScanMode, idx int) []int {
+	north, south := polarRows(g.Latitudes())
+
+	row := north
+	if idx == southPoleIndex {
+		row = south
+	}
+
+	indices := make([]int, len(g.Longitudes()))
+	for j := range indices {
+		indices[j] = GridIndexFromIndices(g, row, j, mode)
+	}
+
+	return indices
+}
+
+// reducePole 读取最外侧纬度行，忽略缺测后用 reducer 归并为极点的值
+func reducePole(reader ValueReader, reducer interpolators.Reducer, timeStep int, row []int, missing *missingValues) (float64, error) {
+	rowValues := make([]float64, len(row))
+	if err := readValues(reader, timeStep, row, rowValues); err != nil {
+		return 0, err
+	}
+
+	values := rowValues[:0]
+	for _, v := range rowValues {
+		if math.IsNaN(v) || (missing != nil && missing.policy != MissingIgnore && missing.isMissing(v)) {
+			continue
+		}
+		values = append(values, v)
+	}
+
+	if len(values) == 0 {
+		return math.NaN(), nil
+	}
+
+	return reducer.Reduce(values), nil
+}
+
+// poleReducer 返回数据场计算极点值的规则：插值算法实现了 Reducer 时使用该算法（如众数、角度平均），
+// 否则使用算术平均
+func poleReducer(interpolator interpolators.Interpolator) interpolators.Reducer {
+	if r, ok := interpolator.(interpolators.Reducer); ok {
+		return r
+	}
+	return &interpolators.AverageInterpolator{}
+}
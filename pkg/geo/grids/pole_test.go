@@ -0,0 +1,129 @@
+package grids_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/gaussian"
+	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+	"github.com/scorix/walg/pkg/geo/grids/rotated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGridInterpolator_LongitudeWrap(t *testing.T) {
+	field := func(lat, lon float64) float64 { return lat + lon/1000 }
+
+	t.Run("latlon last column", func(t *testing.T) {
+		grid := latlon.NewLatLonGrid(-90, 90, 0, 359, 1, 1)
+		reader := &funcReader{grid: grid, f: field}
+		gi := grids.NewGridInterpolator(reader, grid, 0, nil)
+
+		// 359° 与 0°（360°）之间
+		got, err := gi.InterpolateAt(0, 10, 359.4)
+		require.NoError(t, err)
+		assert.InDelta(t, 10+0.6*0.359, got, 1e-9)
+
+		got, err = gi.InterpolateAt(0, 10, -0.6)
+		require.NoError(t, err)
+		assert.InDelta(t, 10+0.6*0.359, got, 1e-9)
+	})
+
+	t.Run("gaussian dateline", func(t *testing.T) {
+		grid := gaussian.NewRegular(48)
+		mode := grids.ScanModeNegativeI
+		reader := &funcReader{grid: grid, mode: mode, f: func(lat, lon float64) float64 {
+			return math.Cos(lon * math.Pi / 180)
+		}}
+		gi := grids.NewGridInterpolator(reader, grid, mode, nil)
+
+		for _, lon := range []float64{-0.5, 359.5, 180.2, -179.8} {
+			got, err := gi.InterpolateAt(0, 0, lon)
+			require.NoError(t, err)
+			assert.InDelta(t, math.Cos(lon*math.Pi/180), got, 1e-3, "lon %f", lon)
+		}
+	})
+}
+
+func TestGridInterpolator_Poles(t *testing.T) {
+	grid := gaussian.NewRegular(48)
+	lats := grid.Latitudes()
+
+	// 纬向平均为 10 的场
+	reader := &funcReader{grid: grid, f: func(lat, lon float64) float64 {
+		return 10 + math.Sin(lon*math.Pi/180)
+	}}
+	gi := grids.NewGridInterpolator(reader, grid, 0, nil)
+
+	t.Run("north pole", func(t *testing.T) {
+		got, err := gi.InterpolateAt(0, 90, 123)
+		require.NoError(t, err)
+		assert.InDelta(t, 10, got, 1e-9)
+	})
+
+	t.Run("south pole", func(t *testing.T) {
+		got, err := gi.InterpolateAt(0, -90, 0)
+		require.NoError(t, err)
+		assert.InDelta(t, 10, got, 1e-9)
+	})
+
+	t.Run("between polar row and pole", func(t *testing.T) {
+		lat := (lats[0] + 90) / 2
+		got, err := gi.InterpolateAt(0, lat, 90)
+		require.NoError(t, err)
+		assert.InDelta(t, 10.5, got, 1e-9)
+	})
+
+	t.Run("categorical pole uses mode", func(t *testing.T) {
+		reader := &funcReader{grid: grid, f: func(lat, lon float64) float64 {
+			if lon < 300 {
+				return 3
+			}
+			return 7
+		}}
+		gi := grids.NewGridInterpolator(reader, grid, 0, &interpolators.ModeInterpolator{})
+		got, err := gi.InterpolateAt(0, 89.9, 310)
+		require.NoError(t, err)
+		assert.Equal(t, 3.0, got)
+	})
+}
+
+// geoReader 按网格点的地理坐标取值
+type geoReader struct {
+	grid interface {
+		grids.Grid
+		grids.GeographicLocator
+	}
+	f func(lat, lon float64) float64
+}
+
+func (r *geoReader) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	latIdx, lonIdx, ok := grids.GridIndicesFromIndex(r.grid, gridIndex, 0)
+	if !ok {
+		return 0, fmt.Errorf("invalid grid index: %d", gridIndex)
+	}
+	return r.f(r.grid.Geographic(latIdx, lonIdx)), nil
+}
+
+func TestGridInterpolator_RotatedNoVirtualPole(t *testing.T) {
+	// 旋转坐标系中的全球网格，最外侧纬度行不对应地理极点
+	grid := rotated.NewRotated(latlon.NewLatLonGrid(-89.5, 89.5, 0, 359, 1, 1), -30, 0, 0)
+	field := func(lat, lon float64) float64 {
+		return math.Cos(lat*math.Pi/180) * math.Cos(lon*math.Pi/180)
+	}
+	reader := &geoReader{grid: grid, f: field}
+	gi := grids.NewGridInterpolator(reader, grid, 0, nil)
+
+	// 地理纬度高于最外侧纬度行的旋转纬度，但位于旋转网格内部
+	got, err := gi.InterpolateAt(0, 89.8, 10)
+	require.NoError(t, err)
+	assert.InDelta(t, field(89.8, 10), got, 1e-4)
+
+	gi.WithMissingValues(grids.MissingNearestValid)
+	got, err = gi.InterpolateAt(0, 89.8, 10)
+	require.NoError(t, err)
+	assert.InDelta(t, field(89.8, 10), got, 1e-4)
+}
@@ -0,0 +1,120 @@
+package rotated
+
+import (
+	"math"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+)
+
+// rotated 旋转经纬度网格
+// 网格在旋转坐标系中是规则的经纬度网格，Latitudes 和 Longitudes 返回旋转坐标；
+// GetNearestIndex 等方法接收地理坐标，先转换到旋转坐标系再查找
+type rotated struct {
+	base         grids.Grid // 旋转坐标系中的网格
+	southPoleLat float64    // 旋转后南极点的地理纬度
+	southPoleLon float64    // 旋转后南极点的地理经度
+	angle        float64    // 绕新极轴的旋转角度
+	sinTheta     float64
+	cosTheta     float64
+}
+
+// NewRotated 创建旋转经纬度网格，base 为旋转坐标系中的网格
+// southPoleLat、southPoleLon 为旋转后南极点的地理坐标，angle 为绕新极轴的旋转角度，单位均为度
+func NewRotated(base grids.Grid, southPoleLat, southPoleLon, angle float64) *rotated {
+	theta := (90 + southPoleLat) * math.Pi / 180
+
+	return &rotated{
+		base:         base,
+		southPoleLat: southPoleLat,
+		southPoleLon: southPoleLon,
+		angle:        angle,
+		sinTheta:     math.Sin(theta),
+		cosTheta:     math.Cos(theta),
+	}
+}
+
+// SouthPole 返回旋转后南极点的地理坐标
+func (g *rotated) SouthPole() (lat, lon float64) {
+	return g.southPoleLat, g.southPoleLon
+}
+
+// Angle 返回绕新极轴的旋转角度
+func (g *rotated) Angle() float64 {
+	return g.angle
+}
+
+func (g *rotated) Size() int {
+	return g.base.Size()
+}
+
+// Latitudes 返回旋转坐标系中的纬度
+func (g *rotated) Latitudes() []float64 {
+	return g.base.Latitudes()
+}
+
+// Longitudes 返回旋转坐标系中的经度
+func (g *rotated) Longitudes() []float64 {
+	return g.base.Longitudes()
+}
+
+// IsSphere 旋转坐标系中覆盖全部经度时为 true
+func (g *rotated) IsSphere() bool {
+	s, ok := g.base.(interface{ IsSphere() bool })
+	return ok && s.IsSphere()
+}
+
+func (g *rotated) GetNearestIndex(lat, lon float64) (int, int) {
+	return g.base.GetNearestIndex(g.Rotate(lat, lon))
+}
+
+func (g *rotated) GuessNearestIndex(lat, lon float64) (int, int) {
+	return g.base.GuessNearestIndex(g.Rotate(lat, lon))
+}
+
+// EnclosingCell 实现 grids.CellLocator，在旋转坐标系中查找单元格
+func (g *rotated) EnclosingCell(lat, lon float64) (grids.Cell, bool) {
+	rlat, rlon := g.Rotate(lat, lon)
+	return grids.EnclosingCell(g.base, rlat, rlon)
+}
+
+// Geographic 返回网格点的地理坐标
+func (g *rotated) Geographic(latIdx, lonIdx int) (lat, lon float64) {
+	return g.Unrotate(g.base.Latitudes()[latIdx], g.base.Longitudes()[lonIdx])
+}
+
+// Rotate 将地理坐标转换为旋转坐标
+func (g *rotated) Rotate(lat, lon float64) (rlat, rlon float64) {
+	x, y, z := cartesian(lat, lon-g.southPoleLon)
+
+	xr := g.cosTheta*x + g.sinTheta*z
+	zr := -g.sinTheta*x + g.cosTheta*z
+
+	rlat, rlon = spherical(xr, y, zr)
+	return rlat, normalizeLon(rlon - g.angle)
+}
+
+// Unrotate 将旋转坐标转换为地理坐标
+func (g *rotated) Unrotate(rlat, rlon float64) (lat, lon float64) {
+	x, y, z := cartesian(rlat, rlon+g.angle)
+
+	xg := g.cosTheta*x - g.sinTheta*z
+	zg := g.sinTheta*x + g.cosTheta*z
+
+	lat, lon = spherical(xg, y, zg)
+	return lat, normalizeLon(lon + g.southPoleLon)
+}
+
+func cartesian(lat, lon float64) (x, y, z float64) {
+	phi, lambda := lat*math.Pi/180, lon*math.Pi/180
+	return math.Cos(phi) * math.Cos(lambda), math.Cos(phi) * math.Sin(lambda), math.Sin(phi)
+}
+
+func spherical(x, y, z float64) (lat, lon float64) {
+	z = math.Max(-1, math.Min(1, z))
+	return math.Asin(z) * 180 / math.Pi, math.Atan2(y, x) * 180 / math.Pi
+}
+
+// normalizeLon 将经度规范到 [-180, 180)
+func normalizeLon(lon float64) float64 {
+	return math.Mod(math.Mod(lon+180, 360)+360, 360) - 180
+}
@@ -0,0 +1,71 @@
+package rotated_test
+
+import (
+	"testing"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+	"github.com/scorix/walg/pkg/geo/grids/rotated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotated_RoundTrip(t *testing.T) {
+	g := rotated.NewRotated(latlon.NewLatLonGrid(-10, 10, -10, 10, 1, 1), -40, 10, 0)
+
+	// 旋转坐标系的北极位于地理坐标 (40, -170)
+	rlat, _ := g.Rotate(40, -170)
+	assert.InDelta(t, 90, rlat, 1e-9)
+
+	// 旋转坐标系的原点位于地理坐标 (50, 10)
+	lat, lon := g.Unrotate(0, 0)
+	assert.InDelta(t, 50, lat, 1e-9)
+	assert.InDelta(t, 10, lon, 1e-9)
+
+	for _, p := range [][2]float64{{45, 5}, {55.5, 20.25}, {-10, 170}} {
+		rlat, rlon := g.Rotate(p[0], p[1])
+		lat, lon := g.Unrotate(rlat, rlon)
+		assert.InDelta(t, p[0], lat, 1e-9)
+		assert.InDelta(t, p[1], lon, 1e-9)
+	}
+
+	// 南极点在 -90 时不旋转
+	id := rotated.NewRotated(latlon.NewLatLonGrid(-10, 10, -10, 10, 1, 1), -90, 0, 0)
+	rlat, rlon := id.Rotate(12.5, -33)
+	assert.InDelta(t, 12.5, rlat, 1e-9)
+	assert.InDelta(t, -33, rlon, 1e-9)
+}
+
+func TestRotated_Interpolate(t *testing.T) {
+	g := rotated.NewRotated(latlon.NewLatLonGrid(-10, 10, -10, 10, 0.5, 0.5), -40, 10, 0)
+	require.Implements(t, (*grids.CellLocator)(nil), g)
+
+	// 值为旋转坐标系中的纬度，插值结果应等于目标点的旋转纬度
+	reader := readerFunc(func(timeStep, gridIndex int) (float64, error) {
+		lat, _, _ := grids.GridPoint(g, gridIndex, 0)
+		return lat, nil
+	})
+
+	gi := grids.NewGridInterpolator(reader, g, 0, &interpolators.BilinearInterpolator{})
+	v, err := gi.InterpolateAt(0, 52.3, 14.1)
+	require.NoError(t, err)
+
+	rlat, _ := g.Rotate(52.3, 14.1)
+	assert.InDelta(t, rlat, v, 1e-9)
+
+	latIdx, lonIdx := g.GetNearestIndex(50, 10)
+	assert.Equal(t, []int{20, 20}, []int{latIdx, lonIdx})
+	lat, lon := g.Geographic(latIdx, lonIdx)
+	assert.InDelta(t, 50, lat, 1e-9)
+	assert.InDelta(t, 10, lon, 1e-9)
+
+	_, err = gi.InterpolateAt(0, -30, 10)
+	assert.Error(t, err)
+}
+
+type readerFunc func(timeStep, gridIndex int) (float64, error)
+
+func (f readerFunc) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	return f(timeStep, gridIndex)
+}
@@ -0,0 +1,58 @@
+package grids
+
+import "math"
+
+// sphere 由覆盖全部经度的网格实现，例如全球经纬度网格和高斯网格
+type sphere interface {
+	IsSphere() bool
+}
+
+// isSphere 判断网格在经度方向是否首尾相接
+func isSphere(g Grid) bool {
+	s, ok := g.(sphere)
+	return ok && s.IsSphere()
+}
+
+// stencil 计算以目标点所在单元格为中心的 size×size 邻域，返回按行优先排列的网格索引
+// 全球网格在经度方向循环取点；邻域超出网格范围时返回 false
+func stencil(g Grid, mode ScanMode, cell Cell, size int) ([]int, bool) {
+	latSize := len(g.Latitudes())
+	lonSize := len(g.Longitudes())
+	wrap := isSphere(g)
+
+	start := 1 - size/2
+	indices := make([]int, 0, size*size)
+	for r := start; r < start+size; r++ {
+		i := cell.LatIdx + r
+		if i < 0 || i >= latSize {
+			return nil, false
+		}
+
+		for c := start; c < start+size; c++ {
+			j := cell.LonIdx + c
+			if wrap {
+				j = wrapIndex(j, lonSize)
+			}
+
+			idx := GridIndexFromIndices(g, i, j, mode)
+			if idx < 0 {
+				return nil, false
+			}
+			indices = append(indices, idx)
+		}
+	}
+
+	return indices, true
+}
+
+// wrapIndex 将经度下标循环映射到 [0, n)
+func wrapIndex(i, n int) int {
+	return ((i % n) + n) % n
+}
+
+// unwrapLon 返回全球网格上第 i 个经度，下标超出范围时按 360° 展开
+// 例如最后一列之后的一列为第一列经度加 360°
+func unwrapLon(lons []float64, i int) float64 {
+	n := len(lons)
+	return lons[wrapIndex(i, n)] + 360*math.Floor(float64(i)/float64(n))
+}
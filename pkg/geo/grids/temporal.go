@@ -0,0 +1,266 @@
+package grids
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+)
+
+// TimeAxis 描述时间步与时间的对应关系，时间必须严格递增
+type TimeAxis interface {
+	// Len 返回时间步个数
+	Len() int
+	// Time 返回第 step 个时间步对应的时间
+	Time(step int) time.Time
+}
+
+// TimeSteps 按时间升序排列的时间步列表，是最简单的 TimeAxis 实现
+type TimeSteps []time.Time
+
+func (t TimeSteps) Len() int {
+	return len(t)
+}
+
+func (t TimeSteps) Time(step int) time.Time {
+	return t[step]
+}
+
+// TimeAxisProvider 可以提供时间轴的读取器
+type TimeAxisProvider interface {
+	TimeAxis() TimeAxis
+}
+
+// ReaderTimeAxis 返回读取器的时间轴，读取器未实现 TimeAxisProvider 时返回 nil
+func ReaderTimeAxis(r ValueReader) TimeAxis {
+	if p, ok := r.(TimeAxisProvider); ok {
+		return p.TimeAxis()
+	}
+	return nil
+}
+
+// TimeAxis 返回读取器提供的时间轴，没有时返回 nil
+func (g *GridInterpolator) TimeAxis() TimeAxis {
+	return ReaderTimeAxis(g.reader)
+}
+
+// WithTimeAxis 为读取器附加时间轴，返回的读取器实现 TimeAxisProvider
+// 底层读取器实现的 BatchValueReader 会被保留
+func WithTimeAxis(r ValueReader, axis TimeAxis) ValueReader {
+	t := &timedReader{ValueReader: r, axis: axis}
+	if batch, ok := r.(BatchValueReader); ok {
+		return &timedBatchReader{timedReader: t, batch: batch}
+	}
+	return t
+}
+
+// timedReader 附加了时间轴的读取器
+type timedReader struct {
+	ValueReader
+	axis TimeAxis
+}
+
+func (r *timedReader) TimeAxis() TimeAxis {
+	return r.axis
+}
+
+// timedBatchReader 底层读取器实现了 BatchValueReader 的 timedReader
+type timedBatchReader struct {
+	*timedReader
+	batch BatchValueReader
+}
+
+func (r *timedBatchReader) ReadValuesAt(timeStep int, indices []int, dst []float64) error {
+	return r.batch.ReadValuesAt(timeStep, indices, dst)
+}
+
+func (r *timedBatchReader) ReadField(timeStep int, dst []float64) error {
+	return r.batch.ReadField(timeStep, dst)
+}
+
+// ErrTimeOutOfRange 插值时间超出时间轴范围
+var ErrTimeOutOfRange = errors.New("time out of range")
+
+// TimeRangeError 记录超出时间轴范围的时间
+type TimeRangeError struct {
+	Time       time.Time
+	Start, End time.Time
+}
+
+func (e *TimeRangeError) Error() string {
+	return fmt.Sprintf("time %s is outside the time axis [%s, %s]",
+		e.Time.Format(time.RFC3339), e.Start.Format(time.RFC3339), e.End.Format(time.RFC3339))
+}
+
+func (e *TimeRangeError) Unwrap() error {
+	return ErrTimeOutOfRange
+}
+
+// TemporalMethod 时间方向的插值方法
+type TemporalMethod int
+
+const (
+	// TemporalLinear 在相邻两个时间步之间线性插值
+	TemporalLinear TemporalMethod = iota
+	// TemporalCubicHermite 使用前后四个时间步做三次 Hermite 插值，切线由有限差分估计
+	// 时间步间隔可以不均匀，边界处使用单侧差分
+	TemporalCubicHermite
+)
+
+func (m TemporalMethod) String() string {
+	switch m {
+	case TemporalLinear:
+		return "linear"
+	case TemporalCubicHermite:
+		return "cubic-hermite"
+	default:
+		return fmt.Sprintf("TemporalMethod(%d)", int(m))
+	}
+}
+
+// TemporalInterpolator 在任意时间和位置插值
+// 先由空间插值器得到相关时间步上的值，再在时间方向插值；
+// 空间插值器为 CircularInterpolator 时按角度展开后插值，结果在 [0, 360) 范围内
+type TemporalInterpolator struct {
+	spatial *GridInterpolator
+	axis    TimeAxis
+	method  TemporalMethod
+}
+
+// NewTemporalInterpolator 创建时间插值器
+// axis 为 nil 时使用空间插值器读取器提供的时间轴（见 TimeAxisProvider）
+func NewTemporalInterpolator(spatial *GridInterpolator, axis TimeAxis, method TemporalMethod) *TemporalInterpolator {
+	if axis == nil {
+		axis = ReaderTimeAxis(spatial.reader)
+	}
+	return &TemporalInterpolator{spatial: spatial, axis: axis, method: method}
+}
+
+// InterpolateAtTime 在指定时间和位置插值，时间超出时间轴范围时返回 TimeRangeError
+func (t *TemporalInterpolator) InterpolateAtTime(tm time.Time, lat, lon float64) (float64, error) {
+	return t.InterpolateAtTimeContext(context.Background(), tm, lat, lon)
+}
+
+// InterpolateAtTimeContext 与 InterpolateAtTime 相同，ctx 取消后返回 ctx.Err()
+func (t *TemporalInterpolator) InterpolateAtTimeContext(ctx context.Context, tm time.Time, lat, lon float64) (float64, error) {
+	step, frac, err := locateTime(t.axis, tm)
+	if err != nil {
+		return 0, err
+	}
+
+	// 空间邻居只计算一次
+	indices, weights, err := t.spatial.neighbours(lat, lon)
+	if err != nil {
+		return 0, err
+	}
+
+	g := t.spatial.withContext(ctx)
+	at := func(s int) (float64, error) {
+		return g.interpolate(s, lat, lon, indices, weights, nil)
+	}
+
+	if frac == 0 {
+		return at(step)
+	}
+
+	steps := []int{step, step + 1}
+	if t.method == TemporalCubicHermite {
+		steps = []int{max(step-1, 0), step, step + 1, min(step+2, t.axis.Len()-1)}
+	}
+
+	values := make([]float64, len(steps))
+	for i, s := range steps {
+		if values[i], err = at(s); err != nil {
+			return 0, err
+		}
+	}
+
+	_, circular := t.spatial.interpolator.(*interpolators.CircularInterpolator)
+	if circular {
+		unwrapAngles(values)
+	}
+
+	var v float64
+	switch t.method {
+	case TemporalCubicHermite:
+		times := make([]float64, len(steps))
+		for i, s := range steps {
+			times[i] = t.axis.Time(s).Sub(t.axis.Time(step)).Seconds()
+		}
+		v = cubicHermite(times, values, frac)
+	default:
+		v = values[0] + (values[1]-values[0])*frac
+	}
+
+	if circular {
+		v = math.Mod(v, 360)
+		if v < 0 {
+			v += 360
+		}
+	}
+
+	return v, nil
+}
+
+// locateTime 查找 tm 所在的时间步区间，返回区间起点和 tm 在区间内的比例
+func locateTime(axis TimeAxis, tm time.Time) (int, float64, error) {
+	if axis == nil {
+		return 0, 0, errors.New("no time axis")
+	}
+
+	n := axis.Len()
+	if n == 0 {
+		return 0, 0, fmt.Errorf("empty time axis: %w", ErrTimeOutOfRange)
+	}
+
+	start, end := axis.Time(0), axis.Time(n-1)
+	if tm.Before(start) || tm.After(end) {
+		return 0, 0, &TimeRangeError{Time: tm, Start: start, End: end}
+	}
+
+	// 第一个晚于 tm 的时间步
+	i := sort.Search(n, func(i int) bool { return axis.Time(i).After(tm) })
+	if i == n {
+		return n - 1, 0, nil
+	}
+
+	t0, t1 := axis.Time(i-1), axis.Time(i)
+	return i - 1, float64(tm.Sub(t0)) / float64(t1.Sub(t0)), nil
+}
+
+// cubicHermite 在 times[1]、times[2] 之间做三次 Hermite 插值
+// times、values 为四个时间步（边界处首尾可能与相邻点重复），times 以 times[1] 为零点
+func cubicHermite(times, values []float64, frac float64) float64 {
+	slope := func(a, b int) float64 {
+		if times[b] == times[a] {
+			return 0
+		}
+		return (values[b] - values[a]) / (times[b] - times[a])
+	}
+
+	h := times[2] - times[1]
+	m0 := slope(0, 2)
+	m1 := slope(1, 3)
+
+	f2 := frac * frac
+	f3 := f2 * frac
+	h00 := 2*f3 - 3*f2 + 1
+	h10 := f3 - 2*f2 + frac
+	h01 := -2*f3 + 3*f2
+	h11 := f3 - f2
+
+	return h00*values[1] + h10*h*m0 + h01*values[2] + h11*h*m1
+}
+
+// unwrapAngles 将角度序列展开为连续序列，使相邻角度之差不超过 180 度
+func unwrapAngles(values []float64) {
+	for i := 1; i < len(values); i++ {
+		d := values[i] - values[i-1]
+		d -= 360 * math.Round(d/360)
+		values[i] = values[i-1] + d
+	}
+}
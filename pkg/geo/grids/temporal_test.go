@@ -0,0 +1,143 @@
+package grids_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stepFuncReader 所有网格点取值相同，只随时间步变化
+type stepFuncReader struct {
+	f func(step int) float64
+}
+
+func (r *stepFuncReader) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	return r.f(timeStep), nil
+}
+
+func threeHourly(n int) grids.TimeSteps {
+	base := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	steps := make(grids.TimeSteps, n)
+	for i := range steps {
+		steps[i] = base.Add(time.Duration(i) * 3 * time.Hour)
+	}
+	return steps
+}
+
+func TestTemporalInterpolator(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	axis := threeHourly(8)
+	// 以小时为单位的二次函数
+	reader := &stepFuncReader{f: func(step int) float64 { h := float64(step * 3); return h * h }}
+	spatial := grids.NewGridInterpolator(reader, grid, 0, &interpolators.BilinearInterpolator{})
+
+	at := time.Date(2024, 7, 1, 14, 20, 0, 0, time.UTC)
+	hours := 14 + 20.0/60
+
+	tests := []struct {
+		name   string
+		method grids.TemporalMethod
+		want   float64
+	}{
+		{name: "linear", method: grids.TemporalLinear, want: 144 + (225-144)*(hours-12)/3},
+		// 等间隔时间步上，有限差分切线的三次 Hermite 插值对二次函数是精确的
+		{name: "cubic hermite", method: grids.TemporalCubicHermite, want: hours * hours},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ti := grids.NewTemporalInterpolator(spatial, axis, tt.method)
+
+			v, err := ti.InterpolateAtTime(at, 5.5, 105.5)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, v, 1e-9)
+
+			// 恰好落在时间步上
+			v, err = ti.InterpolateAtTime(axis[7], 5.5, 105.5)
+			require.NoError(t, err)
+			assert.InDelta(t, 441.0, v, 1e-9)
+
+			// 边界区间
+			v, err = ti.InterpolateAtTime(axis[0].Add(90*time.Minute), 5.5, 105.5)
+			require.NoError(t, err)
+			assert.False(t, math.IsNaN(v))
+		})
+	}
+}
+
+func TestTemporalInterpolator_OutOfRange(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	axis := threeHourly(4)
+	spatial := grids.NewGridInterpolator(&stepFuncReader{f: func(int) float64 { return 1 }}, grid, 0, &interpolators.BilinearInterpolator{})
+	ti := grids.NewTemporalInterpolator(spatial, axis, grids.TemporalLinear)
+
+	_, err := ti.InterpolateAtTime(axis[3].Add(time.Minute), 5, 105)
+	require.ErrorIs(t, err, grids.ErrTimeOutOfRange)
+
+	var rangeErr *grids.TimeRangeError
+	require.True(t, errors.As(err, &rangeErr))
+	assert.Equal(t, axis[0], rangeErr.Start)
+	assert.Equal(t, axis[3], rangeErr.End)
+
+	_, err = ti.InterpolateAtTime(axis[0].Add(-time.Second), 5, 105)
+	assert.ErrorIs(t, err, grids.ErrTimeOutOfRange)
+}
+
+func TestTemporalInterpolator_Direction(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	axis := threeHourly(4)
+	dirs := []float64{340, 350, 10, 20}
+	reader := &stepFuncReader{f: func(step int) float64 { return dirs[step] }}
+	spatial := grids.NewGridInterpolator(reader, grid, 0, interpolators.NewCircularInterpolator(&interpolators.BilinearInterpolator{}))
+
+	for _, method := range []grids.TemporalMethod{grids.TemporalLinear, grids.TemporalCubicHermite} {
+		ti := grids.NewTemporalInterpolator(spatial, axis, method)
+		v, err := ti.InterpolateAtTime(axis[1].Add(90*time.Minute), 5, 105)
+		require.NoError(t, err, method.String())
+		assert.InDelta(t, 0, math.Min(v, 360-v), 1e-6, method.String())
+	}
+}
+
+func TestTemporalInterpolator_ReaderTimeAxis(t *testing.T) {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	axis := threeHourly(4)
+	reader := grids.WithTimeAxis(&stepFuncReader{f: func(step int) float64 { return float64(step) }}, axis)
+	spatial := grids.NewGridInterpolator(reader, grid, 0, &interpolators.BilinearInterpolator{})
+	assert.Equal(t, grids.TimeAxis(axis), spatial.TimeAxis())
+
+	ti := grids.NewTemporalInterpolator(spatial, nil, grids.TemporalLinear)
+	v, err := ti.InterpolateAtTime(axis[2].Add(time.Hour), 5, 105)
+	require.NoError(t, err)
+	assert.InDelta(t, 2+1.0/3, v, 1e-9)
+
+	plain := grids.NewGridInterpolator(&stepFuncReader{f: func(int) float64 { return 0 }}, grid, 0, &interpolators.BilinearInterpolator{})
+	_, err = grids.NewTemporalInterpolator(plain, nil, grids.TemporalLinear).InterpolateAtTime(axis[0], 5, 105)
+	assert.Error(t, err)
+}
+
+func TestWithTimeAxis_Batch(t *testing.T) {
+	axis := threeHourly(1)
+
+	// 底层读取器不支持批量读取时不实现 BatchValueReader，可以由 AsBatchValueReader 按网格点数逐点读取
+	reader := grids.WithTimeAxis(&stepFuncReader{f: func(step int) float64 { return 7 }}, axis)
+	_, ok := reader.(grids.BatchValueReader)
+	assert.False(t, ok)
+
+	dst := make([]float64, 3)
+	require.NoError(t, grids.AsBatchValueReader(reader, 3).ReadField(0, dst))
+	assert.Equal(t, []float64{7, 7, 7}, dst)
+
+	fr := &fieldReader{fields: [][]float64{{1, 2, 3}}}
+	batch, ok := grids.WithTimeAxis(grids.AsValueReader(fr), axis).(grids.BatchValueReader)
+	require.True(t, ok)
+	require.NoError(t, batch.ReadField(0, dst))
+	assert.Equal(t, []float64{1, 2, 3}, dst)
+	assert.Equal(t, grids.TimeAxis(axis), grids.ReaderTimeAxis(batch.(grids.ValueReader)))
+}
@@ -0,0 +1,168 @@
+package timeaxis
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrInvalidSteps 预报时效不是严格递增的
+	ErrInvalidSteps = errors.New("forecast steps must be strictly increasing")
+	// ErrNoSteps 时间轴没有时间步
+	ErrNoSteps = errors.New("time axis has no steps")
+)
+
+// Axis 预报时间轴，由起报（分析）时间和一组预报时效组成
+// 第 i 个时间步的有效时间为 reference + steps[i]，预报时效可以不等间隔
+// Axis 实现了 grids.TimeAxis 接口
+type Axis struct {
+	reference time.Time
+	steps     []time.Duration
+}
+
+// New 使用起报时间和预报时效创建时间轴，至少有一个预报时效，且必须严格递增
+func New(reference time.Time, steps ...time.Duration) (*Axis, error) {
+	if len(steps) == 0 {
+		return nil, ErrNoSteps
+	}
+	for i := 1; i < len(steps); i++ {
+		if steps[i] <= steps[i-1] {
+			return nil, fmt.Errorf("step %d (%s) after %s: %w", i, steps[i], steps[i-1], ErrInvalidSteps)
+		}
+	}
+
+	return &Axis{reference: reference, steps: slices.Clone(steps)}, nil
+}
+
+// Regular 创建等间隔的时间轴，第一个时间步的预报时效为 start
+// interval 必须大于 0，count 至少为 1
+func Regular(reference time.Time, start, interval time.Duration, count int) (*Axis, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid interval: %s", interval)
+	}
+	if count < 1 {
+		return nil, fmt.Errorf("invalid step count %d: %w", count, ErrNoSteps)
+	}
+
+	steps := make([]time.Duration, count)
+	for i := range steps {
+		steps[i] = start + time.Duration(i)*interval
+	}
+
+	return &Axis{reference: reference, steps: steps}, nil
+}
+
+// Segment 时间轴的一段，以 Interval 为间隔一直延伸到 Until（含）
+type Segment struct {
+	Until    time.Duration
+	Interval time.Duration
+}
+
+// FromSegments 按分段间隔创建时间轴，例如逐小时到 90 小时，之后逐 3 小时到 240 小时：
+//
+//	FromSegments(ref, 0, Segment{90 * time.Hour, time.Hour}, Segment{240 * time.Hour, 3 * time.Hour})
+func FromSegments(reference time.Time, start time.Duration, segments ...Segment) (*Axis, error) {
+	steps := []time.Duration{start}
+	for _, seg := range segments {
+		if seg.Interval <= 0 {
+			return nil, fmt.Errorf("invalid segment interval: %s", seg.Interval)
+		}
+
+		for s := steps[len(steps)-1] + seg.Interval; s <= seg.Until; s += seg.Interval {
+			steps = append(steps, s)
+		}
+	}
+
+	return New(reference, steps...)
+}
+
+// Reference 返回起报时间
+func (a *Axis) Reference() time.Time {
+	return a.reference
+}
+
+// Len 返回时间步个数
+func (a *Axis) Len() int {
+	return len(a.steps)
+}
+
+// Step 返回第 i 个时间步的预报时效
+func (a *Axis) Step(i int) time.Duration {
+	return a.steps[i]
+}
+
+// Steps 返回所有预报时效的副本
+func (a *Axis) Steps() []time.Duration {
+	return slices.Clone(a.steps)
+}
+
+// Time 返回第 i 个时间步的有效时间
+func (a *Axis) Time(i int) time.Time {
+	return a.reference.Add(a.steps[i])
+}
+
+// Start 返回第一个时间步的有效时间
+func (a *Axis) Start() time.Time {
+	return a.Time(0)
+}
+
+// End 返回最后一个时间步的有效时间
+func (a *Axis) End() time.Time {
+	return a.Time(len(a.steps) - 1)
+}
+
+// Index 返回有效时间恰好为 valid 的时间步，不存在时返回 false
+func (a *Axis) Index(valid time.Time) (int, bool) {
+	return slices.BinarySearch(a.steps, valid.Sub(a.reference))
+}
+
+// IndexOfStep 返回预报时效恰好为 step 的时间步，不存在时返回 false
+func (a *Axis) IndexOfStep(step time.Duration) (int, bool) {
+	return slices.BinarySearch(a.steps, step)
+}
+
+// Floor 返回有效时间不晚于 valid 的最后一个时间步，valid 早于时间轴起点时返回 false
+func (a *Axis) Floor(valid time.Time) (int, bool) {
+	d := valid.Sub(a.reference)
+	i := sort.Search(len(a.steps), func(i int) bool { return a.steps[i] > d })
+	return i - 1, i > 0
+}
+
+// Nearest 返回有效时间最接近 valid 的时间步，距离相同时取较早的时间步
+func (a *Axis) Nearest(valid time.Time) int {
+	i, ok := a.Floor(valid)
+	if !ok {
+		return 0
+	}
+	if i+1 < len(a.steps) && a.Time(i+1).Sub(valid) < valid.Sub(a.Time(i)) {
+		return i + 1
+	}
+	return i
+}
+
+// All 按顺序遍历所有时间步及其有效时间
+func (a *Axis) All() iter.Seq2[int, time.Time] {
+	return func(yield func(int, time.Time) bool) {
+		for i := range a.steps {
+			if !yield(i, a.Time(i)) {
+				return
+			}
+		}
+	}
+}
+
+// Between 按顺序遍历有效时间位于 [from, to] 内的时间步
+func (a *Axis) Between(from, to time.Time) iter.Seq2[int, time.Time] {
+	return func(yield func(int, time.Time) bool) {
+		start := sort.Search(len(a.steps), func(i int) bool { return !a.Time(i).Before(from) })
+		for i := start; i < len(a.steps) && !a.Time(i).After(to); i++ {
+			if !yield(i, a.Time(i)) {
+				return
+			}
+		}
+	}
+}
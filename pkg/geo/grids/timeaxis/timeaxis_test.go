@@ -0,0 +1,105 @@
+package timeaxis_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/timeaxis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ grids.TimeAxis = (*timeaxis.Axis)(nil)
+
+var ref = time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+
+func TestNew(t *testing.T) {
+	a, err := timeaxis.New(ref, 0, 3*time.Hour, 6*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 3, a.Len())
+	assert.Equal(t, ref, a.Reference())
+	assert.Equal(t, ref.Add(6*time.Hour), a.End())
+
+	_, err = timeaxis.New(ref, 0, 3*time.Hour, 3*time.Hour)
+	assert.ErrorIs(t, err, timeaxis.ErrInvalidSteps)
+}
+
+func TestFromSegments(t *testing.T) {
+	a, err := timeaxis.FromSegments(ref, 0,
+		timeaxis.Segment{Until: 90 * time.Hour, Interval: time.Hour},
+		timeaxis.Segment{Until: 240 * time.Hour, Interval: 3 * time.Hour},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, 91+50, a.Len())
+	assert.Equal(t, 90*time.Hour, a.Step(90))
+	assert.Equal(t, 93*time.Hour, a.Step(91))
+	assert.Equal(t, 240*time.Hour, a.Step(a.Len()-1))
+
+	_, err = timeaxis.FromSegments(ref, 0, timeaxis.Segment{Until: time.Hour})
+	assert.Error(t, err)
+}
+
+func TestAxis_Lookup(t *testing.T) {
+	a, err := timeaxis.New(ref, 0, time.Hour, 2*time.Hour, 5*time.Hour)
+	require.NoError(t, err)
+
+	i, ok := a.Index(ref.Add(2 * time.Hour))
+	assert.True(t, ok)
+	assert.Equal(t, 2, i)
+
+	_, ok = a.Index(ref.Add(3 * time.Hour))
+	assert.False(t, ok)
+
+	i, ok = a.IndexOfStep(5 * time.Hour)
+	assert.True(t, ok)
+	assert.Equal(t, 3, i)
+
+	i, ok = a.Floor(ref.Add(4 * time.Hour))
+	assert.True(t, ok)
+	assert.Equal(t, 2, i)
+
+	_, ok = a.Floor(ref.Add(-time.Minute))
+	assert.False(t, ok)
+
+	assert.Equal(t, 2, a.Nearest(ref.Add(3*time.Hour)))
+	assert.Equal(t, 3, a.Nearest(ref.Add(4*time.Hour)))
+	assert.Equal(t, 0, a.Nearest(ref.Add(-time.Hour)))
+	assert.Equal(t, 3, a.Nearest(ref.Add(10*time.Hour)))
+}
+
+func TestRegular_Invalid(t *testing.T) {
+	_, err := timeaxis.Regular(ref, 0, 0, 5)
+	assert.Error(t, err)
+
+	_, err = timeaxis.Regular(ref, 0, -time.Hour, 5)
+	assert.Error(t, err)
+
+	_, err = timeaxis.Regular(ref, 0, time.Hour, -1)
+	assert.Error(t, err)
+
+	_, err = timeaxis.Regular(ref, 0, time.Hour, 0)
+	assert.ErrorIs(t, err, timeaxis.ErrNoSteps)
+
+	_, err = timeaxis.New(ref)
+	assert.ErrorIs(t, err, timeaxis.ErrNoSteps)
+}
+
+func TestAxis_Iterate(t *testing.T) {
+	a, err := timeaxis.Regular(ref, 3*time.Hour, 3*time.Hour, 5)
+	require.NoError(t, err)
+
+	var steps []int
+	for i, tm := range a.All() {
+		assert.Equal(t, a.Time(i), tm)
+		steps = append(steps, i)
+	}
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, steps)
+
+	steps = steps[:0]
+	for i := range a.Between(ref.Add(5*time.Hour), ref.Add(12*time.Hour)) {
+		steps = append(steps, i)
+	}
+	assert.Equal(t, []int{1, 2, 3}, steps)
+}
@@ -0,0 +1,167 @@
+package vertical
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+)
+
+// ErrOutOfRange 目标气压或高度超出廓线范围，不做外推
+var ErrOutOfRange = errors.New("vertical coordinate out of range")
+
+// Column 由每个层次的水平插值器组成的三维场
+// 在某一点先对每个层次做水平插值得到廓线，再在垂直方向插值
+type Column struct {
+	levels          []Level
+	fields          []*grids.GridInterpolator
+	surfacePressure *grids.GridInterpolator
+	heights         []*grids.GridInterpolator
+}
+
+// NewColumn 创建三维场，fields[i] 为第 i 个层次的水平插值器
+func NewColumn(levels []Level, fields []*grids.GridInterpolator) (*Column, error) {
+	if len(levels) != len(fields) {
+		return nil, fmt.Errorf("levels and fields length mismatch: %d != %d", len(levels), len(fields))
+	}
+	if len(levels) == 0 {
+		return nil, errors.New("no levels")
+	}
+
+	return &Column{levels: levels, fields: fields}, nil
+}
+
+// WithSurfacePressure 设置地面气压（Pa）场，混合层计算层次气压时需要
+func (c *Column) WithSurfacePressure(ps *grids.GridInterpolator) *Column {
+	c.surfacePressure = ps
+	return c
+}
+
+// WithHeights 设置每个层次的离地高度（m）场，用于在等压面或混合层上按高度插值
+// heights[i] 为第 i 个层次的高度场，个数必须与层次个数相同
+func (c *Column) WithHeights(heights []*grids.GridInterpolator) (*Column, error) {
+	if len(heights) != len(c.levels) {
+		return nil, fmt.Errorf("levels and heights length mismatch: %d != %d", len(c.levels), len(heights))
+	}
+
+	c.heights = heights
+	return c, nil
+}
+
+// Profile 某一点的垂直廓线
+// Pressure、Height 分别为每个层次的气压（Pa）和离地高度（m），无法确定时为 NaN
+type Profile struct {
+	Levels   []Level
+	Pressure []float64
+	Height   []float64
+	Values   []float64
+}
+
+// Profile 返回指定时间步和位置的垂直廓线
+func (c *Column) Profile(timeStep int, lat, lon float64) (*Profile, error) {
+	n := len(c.levels)
+	p := &Profile{
+		Levels:   c.levels,
+		Pressure: make([]float64, n),
+		Height:   make([]float64, n),
+		Values:   make([]float64, n),
+	}
+
+	ps := math.NaN()
+	if c.surfacePressure != nil {
+		v, err := c.surfacePressure.InterpolateAt(timeStep, lat, lon)
+		if err != nil {
+			return nil, fmt.Errorf("surface pressure: %w", err)
+		}
+		ps = v
+	}
+
+	for i, l := range c.levels {
+		v, err := c.fields[i].InterpolateAt(timeStep, lat, lon)
+		if err != nil {
+			return nil, fmt.Errorf("level %d: %w", i, err)
+		}
+		p.Values[i] = v
+		p.Pressure[i] = l.Pressure(ps)
+
+		switch {
+		case c.heights != nil:
+			z, err := c.heights[i].InterpolateAt(timeStep, lat, lon)
+			if err != nil {
+				return nil, fmt.Errorf("level %d height: %w", i, err)
+			}
+			p.Height[i] = z
+		case l.Type == Height:
+			p.Height[i] = l.Value
+		default:
+			p.Height[i] = math.NaN()
+		}
+	}
+
+	return p, nil
+}
+
+// Level 返回第 i 个层次在指定时间步和位置的值
+func (c *Column) Level(i, timeStep int, lat, lon float64) (float64, error) {
+	return c.fields[i].InterpolateAt(timeStep, lat, lon)
+}
+
+// AtPressure 返回气压 pa（Pa）处的值，垂直方向按对数气压线性插值
+func (c *Column) AtPressure(timeStep int, lat, lon, pa float64) (float64, error) {
+	p, err := c.Profile(timeStep, lat, lon)
+	if err != nil {
+		return 0, err
+	}
+	return p.AtPressure(pa)
+}
+
+// AtHeight 返回离地高度 z（m）处的值，垂直方向按高度线性插值
+func (c *Column) AtHeight(timeStep int, lat, lon, z float64) (float64, error) {
+	p, err := c.Profile(timeStep, lat, lon)
+	if err != nil {
+		return 0, err
+	}
+	return p.AtHeight(z)
+}
+
+// AtPressure 在廓线上按对数气压线性插值
+func (p *Profile) AtPressure(pa float64) (float64, error) {
+	coords := make([]float64, len(p.Pressure))
+	for i, v := range p.Pressure {
+		coords[i] = math.Log(v)
+	}
+	return interpolateLinear(coords, p.Values, math.Log(pa))
+}
+
+// AtHeight 在廓线上按高度线性插值
+func (p *Profile) AtHeight(z float64) (float64, error) {
+	return interpolateLinear(p.Height, p.Values, z)
+}
+
+// interpolateLinear 在单调（升序或降序）的坐标上线性插值，坐标为 NaN 的层次被跳过
+func interpolateLinear(coords, values []float64, x float64) (float64, error) {
+	prev := -1
+	for i, c := range coords {
+		if math.IsNaN(c) {
+			continue
+		}
+		if c == x {
+			return values[i], nil
+		}
+		if prev >= 0 {
+			c0 := coords[prev]
+			if (c0 < x && x < c) || (c < x && x < c0) {
+				f := (x - c0) / (c - c0)
+				return values[prev] + (values[i]-values[prev])*f, nil
+			}
+		}
+		prev = i
+	}
+
+	if prev < 0 {
+		return 0, errors.New("vertical coordinate unavailable")
+	}
+
+	return 0, fmt.Errorf("%w: %f", ErrOutOfRange, x)
+}
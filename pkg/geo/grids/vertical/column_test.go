@@ -0,0 +1,108 @@
+package vertical_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+	"github.com/scorix/walg/pkg/geo/grids/vertical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// constReader 所有网格点和时间步取值相同
+type constReader float64
+
+func (r constReader) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	return float64(r), nil
+}
+
+func fields(values ...float64) []*grids.GridInterpolator {
+	grid := latlon.NewLatLonGrid(0, 10, 100, 110, 1, 1)
+	out := make([]*grids.GridInterpolator, len(values))
+	for i, v := range values {
+		out[i] = grids.NewGridInterpolator(constReader(v), grid, 0, &interpolators.BilinearInterpolator{})
+	}
+	return out
+}
+
+func TestColumn_Isobaric(t *testing.T) {
+	col, err := vertical.NewColumn(vertical.IsobaricLevels(1000, 850, 500), fields(288, 280, 252))
+	require.NoError(t, err)
+
+	p, err := col.Profile(0, 5, 105)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{100000, 85000, 50000}, p.Pressure)
+	assert.True(t, math.IsNaN(p.Height[0]))
+
+	v, err := col.AtPressure(0, 5, 105, 70000)
+	require.NoError(t, err)
+	f := math.Log(700.0/850) / math.Log(500.0/850)
+	assert.InDelta(t, 280+(252-280)*f, v, 1e-9)
+
+	v, err = col.AtPressure(0, 5, 105, 85000)
+	require.NoError(t, err)
+	assert.Equal(t, 280.0, v)
+
+	_, err = col.AtPressure(0, 5, 105, 30000)
+	assert.ErrorIs(t, err, vertical.ErrOutOfRange)
+
+	// 没有高度场时不能按高度插值
+	_, err = col.AtHeight(0, 5, 105, 1000)
+	assert.Error(t, err)
+
+	// 高度场个数必须与层次个数相同
+	_, err = col.WithHeights(fields(100, 1500))
+	assert.Error(t, err)
+
+	// 提供位势高度后按高度插值
+	_, err = col.WithHeights(fields(100, 1500, 5600))
+	require.NoError(t, err)
+	v, err = col.AtHeight(0, 5, 105, 800)
+	require.NoError(t, err)
+	assert.InDelta(t, 284, v, 1e-9)
+}
+
+func TestColumn_Hybrid(t *testing.T) {
+	levels, err := vertical.HybridLevels([]float64{0, 5000, 20000}, []float64{1, 0.8, 0.1})
+	require.NoError(t, err)
+
+	col, err := vertical.NewColumn(levels, fields(10, 20, 30))
+	require.NoError(t, err)
+	col.WithSurfacePressure(fields(100000)[0])
+
+	p, err := col.Profile(0, 5, 105)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{100000, 85000, 30000}, p.Pressure)
+
+	v, err := col.AtPressure(0, 5, 105, 50000)
+	require.NoError(t, err)
+	f := math.Log(50000.0/85000) / math.Log(30000.0/85000)
+	assert.InDelta(t, 20+10*f, v, 1e-9)
+}
+
+func TestColumn_Height(t *testing.T) {
+	col, err := vertical.NewColumn(vertical.HeightLevels(10, 100), fields(5, 14))
+	require.NoError(t, err)
+
+	v, err := col.AtHeight(0, 5, 105, 50)
+	require.NoError(t, err)
+	assert.InDelta(t, 9, v, 1e-9)
+
+	v, err = col.Level(1, 0, 5, 105)
+	require.NoError(t, err)
+	assert.Equal(t, 14.0, v)
+
+	_, err = col.AtHeight(0, 5, 105, 5)
+	assert.ErrorIs(t, err, vertical.ErrOutOfRange)
+
+	_, err = vertical.NewColumn(vertical.HeightLevels(10), fields(1, 2))
+	assert.Error(t, err)
+}
+
+func TestStandardPressure(t *testing.T) {
+	assert.InDelta(t, 101325, vertical.StandardPressure(0), 1e-6)
+	assert.InDelta(t, 23842, vertical.FlightLevelPressure(350), 5)
+}
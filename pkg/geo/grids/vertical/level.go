@@ -0,0 +1,101 @@
+package vertical
+
+import (
+	"fmt"
+	"math"
+)
+
+// LevelType 垂直层次的类型
+type LevelType int
+
+const (
+	// Isobaric 等压面，Value 单位为 hPa
+	Isobaric LevelType = iota
+	// Height 离地高度，Value 单位为 m
+	Height
+	// Hybrid 混合 sigma-气压层，层次气压 p = A + B*ps，A 单位为 Pa
+	Hybrid
+)
+
+func (t LevelType) String() string {
+	switch t {
+	case Isobaric:
+		return "isobaric"
+	case Height:
+		return "height"
+	case Hybrid:
+		return "hybrid"
+	default:
+		return fmt.Sprintf("LevelType(%d)", int(t))
+	}
+}
+
+// Level 一个垂直层次
+type Level struct {
+	Type  LevelType
+	Value float64 // 等压面气压（hPa）或离地高度（m），混合层为层号
+	A, B  float64 // 混合层系数
+}
+
+// IsobaricLevels 创建一组等压面，单位为 hPa
+func IsobaricLevels(hPa ...float64) []Level {
+	levels := make([]Level, len(hPa))
+	for i, p := range hPa {
+		levels[i] = Level{Type: Isobaric, Value: p}
+	}
+	return levels
+}
+
+// HeightLevels 创建一组离地高度层，单位为 m
+func HeightLevels(meters ...float64) []Level {
+	levels := make([]Level, len(meters))
+	for i, z := range meters {
+		levels[i] = Level{Type: Height, Value: z}
+	}
+	return levels
+}
+
+// HybridLevels 使用 a/b 系数创建一组混合层，a、b 长度必须相同
+func HybridLevels(a, b []float64) ([]Level, error) {
+	if len(a) != len(b) {
+		return nil, fmt.Errorf("hybrid coefficients length mismatch: %d != %d", len(a), len(b))
+	}
+
+	levels := make([]Level, len(a))
+	for i := range a {
+		levels[i] = Level{Type: Hybrid, Value: float64(i + 1), A: a[i], B: b[i]}
+	}
+	return levels, nil
+}
+
+// Pressure 返回层次的气压（Pa），ps 为地面气压（Pa），仅混合层使用
+// 高度层没有固定气压，返回 NaN
+func (l Level) Pressure(ps float64) float64 {
+	switch l.Type {
+	case Isobaric:
+		return l.Value * 100
+	case Hybrid:
+		return l.A + l.B*ps
+	default:
+		return math.NaN()
+	}
+}
+
+// 国际标准大气参数
+const (
+	standardPressure  = 101325.0 // 海平面气压，Pa
+	standardTemp      = 288.15   // 海平面温度，K
+	standardLapseRate = 0.0065   // 对流层温度递减率，K/m
+	gasConstant       = 287.05287
+	gravity           = 9.80665
+)
+
+// StandardPressure 返回国际标准大气中对流层高度 altitude（m）处的气压（Pa）
+func StandardPressure(altitude float64) float64 {
+	return standardPressure * math.Pow(1-standardLapseRate*altitude/standardTemp, gravity/(gasConstant*standardLapseRate))
+}
+
+// FlightLevelPressure 返回飞行高度层（百英尺）对应的标准气压（Pa）
+func FlightLevelPressure(fl float64) float64 {
+	return StandardPressure(fl * 100 * 0.3048)
+}
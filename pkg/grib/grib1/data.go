@@ -0,0 +1,152 @@
+package grib1
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/scorix/walg/pkg/grib/internal/bitio"
+)
+
+// Values 解码消息的值，按网格索引排列，缺测点为 NaN
+// 缩减高斯网格的每个纬圈按经度线性插值展开为规则高斯网格
+func (m *Message) Values() ([]float64, error) {
+	flags := m.bds[3] >> 4
+	if flags&0x8 != 0 {
+		return nil, fmt.Errorf("spherical harmonic data: %w", ErrUnsupported)
+	}
+	if flags&0x4 != 0 || flags&0x1 != 0 {
+		return nil, fmt.Errorf("complex packing: %w", ErrUnsupported)
+	}
+
+	n := m.gds.size()
+	count := n
+	if m.bitmap != nil {
+		if len(m.bitmap)*8 < n {
+			return nil, fmt.Errorf("bitmap too short: %d bits for %d points", len(m.bitmap)*8, n)
+		}
+		count = 0
+		for i := 0; i < n; i++ {
+			if m.bitmap[i/8]&(0x80>>(i%8)) != 0 {
+				count++
+			}
+		}
+	}
+
+	packed, err := m.unpack(count)
+	if err != nil {
+		return nil, err
+	}
+
+	values := packed
+	if m.bitmap != nil {
+		values = make([]float64, n)
+		k := 0
+		for i := range values {
+			if m.bitmap[i/8]&(0x80>>(i%8)) == 0 {
+				values[i] = math.NaN()
+				continue
+			}
+			values[i] = packed[k]
+			k++
+		}
+	}
+
+	if m.Reduced {
+		return m.expand(values)
+	}
+	return values, nil
+}
+
+// unpack 解码简单打包的 count 个值，Y = (R + X * 2^E) / 10^D
+func (m *Message) unpack(count int) ([]float64, error) {
+	e := int(int16At(m.bds, 4))
+	r := ibmFloat(m.bds, 6)
+	nbits := int(m.bds[10])
+	scale := math.Pow10(m.DecimalScale)
+
+	// 分配前检查点数不超过网格大小，且 BDS 中有足够的位
+	if n := m.gds.size(); count > n {
+		return nil, fmt.Errorf("%d packed values for %d grid points", count, n)
+	}
+	// BDS 第 4 字节低 4 位为末尾未使用的位数
+	avail := int64(len(m.bds)-11)*8 - int64(m.bds[3]&0x0f)
+	if int64(count)*int64(nbits) > avail {
+		return nil, fmt.Errorf("%d values of %d bits in %d bytes: %w", count, nbits, len(m.bds)-11, bitio.ErrShortData)
+	}
+
+	values := make([]float64, count)
+	if nbits == 0 {
+		for i := range values {
+			values[i] = r / scale
+		}
+		return values, nil
+	}
+
+	br := bitio.NewReader(m.bds[11:])
+	step := math.Ldexp(1, e)
+	for i := range values {
+		x, err := br.Read(nbits)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = (r + float64(x)*step) / scale
+	}
+
+	return values, nil
+}
+
+// expand 将缩减高斯网格的数据按纬圈线性插值展开到规则高斯网格，结果按北到南、西到东排列
+// 每个纬圈的点从经度 lo1 开始等间隔分布，-i 扫描时自东向西排列，插值在经度方向循环；一侧缺测时取较近的点
+func (m *Message) expand(values []float64) ([]float64, error) {
+	if n := m.gds.size(); len(values) != n {
+		return nil, fmt.Errorf("decoded %d values, PL list expects %d", len(values), n)
+	}
+
+	nj := len(m.gds.pl)
+	ni := 4 * m.gds.n
+	out := make([]float64, ni*nj)
+
+	dir := 1.0
+	if m.gds.scanning&0x80 != 0 {
+		dir = -1
+	}
+
+	off := 0
+	for j, count := range m.gds.pl {
+		row := values[off : off+count]
+		off += count
+
+		latIdx := j
+		if m.gds.scanning&0x40 != 0 {
+			latIdx = nj - 1 - j
+		}
+		dst := out[latIdx*ni : (latIdx+1)*ni]
+
+		for k := range dst {
+			// 目标经度相对纬圈起点的偏移，换算为纬圈内的点序号
+			lon := math.Mod(dir*(float64(k)*360/float64(ni)-m.gds.lo1), 360)
+			if lon < 0 {
+				lon += 360
+			}
+			pos := lon * float64(count) / 360
+			i0 := int(pos)
+			f := pos - float64(i0)
+			v0, v1 := row[i0%count], row[(i0+1)%count]
+
+			switch {
+			case f == 0:
+				dst[k] = v0
+			case math.IsNaN(v0) || math.IsNaN(v1):
+				if f < 0.5 {
+					dst[k] = v0
+				} else {
+					dst[k] = v1
+				}
+			default:
+				dst[k] = v0 + (v1-v0)*f
+			}
+		}
+	}
+
+	return out, nil
+}
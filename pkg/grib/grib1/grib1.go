@@ -0,0 +1,280 @@
+// Package grib1 实现了 GRIB1 格式的纯 Go 解码
+// 支持的网格为经纬度网格（类型 0）、高斯网格（类型 4，包括准规则的缩减高斯网格）和旋转经纬度网格（类型 10），
+// 支持简单打包的格点数据和位图
+package grib1
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+)
+
+var (
+	// ErrNotGRIB1 数据不是 GRIB1 消息
+	ErrNotGRIB1 = errors.New("not a GRIB1 message")
+	// ErrUnsupported 不支持的网格或打包方式
+	ErrUnsupported = errors.New("unsupported GRIB1 feature")
+)
+
+// Message 一条 GRIB1 消息
+type Message struct {
+	TableVersion  uint8
+	Centre        uint8
+	SubCentre     uint8
+	Process       uint8
+	Parameter     uint8
+	LevelType     uint8
+	Level         uint16
+	ReferenceTime time.Time
+	// ForecastTime 预报时效，时间范围指示符表示时段统计时为时段结束时刻
+	ForecastTime       time.Duration
+	TimeRangeIndicator uint8
+	DecimalScale       int
+
+	// GridType GDS 中的数据表示类型（表 6）
+	GridType uint8
+	Grid     grids.Grid
+	ScanMode grids.ScanMode
+	// Reduced 为 true 时原始数据位于准规则的缩减高斯网格上，解码时按纬圈线性插值展开到 Grid
+	Reduced bool
+
+	gds    *gridDefinition
+	bitmap []byte
+	bds    []byte
+}
+
+// ValidTime 返回有效时间，即起报时间加预报时效
+func (m *Message) ValidTime() time.Time {
+	return m.ReferenceTime.Add(m.ForecastTime)
+}
+
+// ReadMessage 从 r 读取下一条 GRIB1 消息，r 中没有更多消息时返回 io.EOF
+// 消息之间的非 GRIB 字节被跳过
+func ReadMessage(r io.Reader) (*Message, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(r, header[:4]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+
+	for !bytes.Equal(header[:4], []byte("GRIB")) {
+		copy(header, header[1:4])
+		if _, err := io.ReadFull(r, header[3:4]); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+	}
+
+	if _, err := io.ReadFull(r, header[4:]); err != nil {
+		return nil, fmt.Errorf("read indicator section: %w", err)
+	}
+	if header[7] != 1 {
+		return nil, fmt.Errorf("edition %d: %w", header[7], ErrNotGRIB1)
+	}
+
+	total := int(uint24At(header, 4))
+	if total < 8+28+12+4 {
+		return nil, fmt.Errorf("invalid message length %d: %w", total, ErrNotGRIB1)
+	}
+
+	body := make([]byte, total-8)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("read message: %w", err)
+	}
+
+	return parseMessage(body)
+}
+
+// Decode 解码 data 中的所有 GRIB1 消息
+func Decode(data []byte) ([]*Message, error) {
+	r := bytes.NewReader(data)
+
+	var msgs []*Message
+	for {
+		m, err := ReadMessage(r)
+		if errors.Is(err, io.EOF) {
+			return msgs, nil
+		}
+		if err != nil {
+			return msgs, err
+		}
+		msgs = append(msgs, m)
+	}
+}
+
+// section 读取 off 处以 3 字节长度开头的段
+func section(body []byte, off int, name string) ([]byte, error) {
+	if off+3 > len(body) {
+		return nil, fmt.Errorf("truncated %s", name)
+	}
+
+	length := int(uint24At(body, off))
+	if length < 3 || off+length > len(body) {
+		return nil, fmt.Errorf("invalid %s length %d", name, length)
+	}
+	return body[off : off+length], nil
+}
+
+func parseMessage(body []byte) (*Message, error) {
+	pds, err := section(body, 0, "PDS")
+	if err != nil {
+		return nil, err
+	}
+	if len(pds) < 28 {
+		return nil, fmt.Errorf("PDS too short: %d", len(pds))
+	}
+
+	m := &Message{}
+	if err := m.parseProduct(pds); err != nil {
+		return nil, err
+	}
+
+	off := len(pds)
+	if pds[7]&0x80 == 0 {
+		return nil, fmt.Errorf("predefined grid %d: %w", pds[6], ErrUnsupported)
+	}
+
+	gds, err := section(body, off, "GDS")
+	if err != nil {
+		return nil, err
+	}
+	if err := m.parseGrid(gds); err != nil {
+		return nil, err
+	}
+	off += len(gds)
+
+	if pds[7]&0x40 != 0 {
+		bms, err := section(body, off, "BMS")
+		if err != nil {
+			return nil, err
+		}
+		if len(bms) < 6 {
+			return nil, fmt.Errorf("BMS too short: %d", len(bms))
+		}
+		if uint16At(bms, 4) != 0 {
+			return nil, fmt.Errorf("predefined bitmap %d: %w", uint16At(bms, 4), ErrUnsupported)
+		}
+		m.bitmap = bms[6:]
+		off += len(bms)
+	}
+
+	bds, err := section(body, off, "BDS")
+	if err != nil {
+		return nil, err
+	}
+	if len(bds) < 11 {
+		return nil, fmt.Errorf("BDS too short: %d", len(bds))
+	}
+	m.bds = bds
+	off += len(bds)
+
+	if off+4 > len(body) || !bytes.Equal(body[off:off+4], []byte("7777")) {
+		return nil, errors.New("missing end section")
+	}
+
+	return m, nil
+}
+
+func (m *Message) parseProduct(pds []byte) error {
+	m.TableVersion = pds[3]
+	m.Centre = pds[4]
+	m.Process = pds[5]
+	m.Parameter = pds[8]
+	m.LevelType = pds[9]
+	m.Level = uint16At(pds, 10)
+	m.SubCentre = pds[25]
+	m.DecimalScale = int(int16At(pds, 26))
+	m.TimeRangeIndicator = pds[20]
+
+	year := (int(pds[24])-1)*100 + int(pds[12])
+	m.ReferenceTime = time.Date(year, time.Month(pds[13]), int(pds[14]), int(pds[15]), int(pds[16]), 0, 0, time.UTC)
+
+	unit, err := timeUnit(pds[17])
+	if err != nil {
+		return err
+	}
+
+	p1, p2 := int(pds[18]), int(pds[19])
+	switch m.TimeRangeIndicator {
+	case 0, 1:
+		m.ForecastTime = time.Duration(p1) * unit
+	case 10:
+		// P1 占用两个字节
+		m.ForecastTime = time.Duration(uint16At(pds, 18)) * unit
+	case 2, 3, 4, 5:
+		// 时段统计，有效时间为时段结束时刻
+		m.ForecastTime = time.Duration(p2) * unit
+	default:
+		m.ForecastTime = time.Duration(p1) * unit
+	}
+
+	return nil
+}
+
+// timeUnit 表 4 时间单位
+func timeUnit(code uint8) (time.Duration, error) {
+	switch code {
+	case 0:
+		return time.Minute, nil
+	case 1:
+		return time.Hour, nil
+	case 2:
+		return 24 * time.Hour, nil
+	case 10:
+		return 3 * time.Hour, nil
+	case 11:
+		return 6 * time.Hour, nil
+	case 12:
+		return 12 * time.Hour, nil
+	case 254:
+		return time.Second, nil
+	default:
+		return 0, fmt.Errorf("time unit %d: %w", code, ErrUnsupported)
+	}
+}
+
+func uint16At(b []byte, off int) uint16 {
+	return uint16(b[off])<<8 | uint16(b[off+1])
+}
+
+func uint24At(b []byte, off int) uint32 {
+	return uint32(b[off])<<16 | uint32(b[off+1])<<8 | uint32(b[off+2])
+}
+
+// int16At 读取符号-数值表示的有符号整数（最高位为符号位）
+func int16At(b []byte, off int) int16 {
+	v := uint16At(b, off)
+	if v&0x8000 != 0 {
+		return -int16(v & 0x7fff)
+	}
+	return int16(v)
+}
+
+func int24At(b []byte, off int) int32 {
+	v := uint24At(b, off)
+	if v&0x800000 != 0 {
+		return -int32(v & 0x7fffff)
+	}
+	return int32(v)
+}
+
+// ibmFloat 解码 IBM 单精度浮点数：符号位、7 位以 16 为底的偏移 64 指数、24 位尾数
+func ibmFloat(b []byte, off int) float64 {
+	sign := 1.0
+	if b[off]&0x80 != 0 {
+		sign = -1
+	}
+	exp := int(b[off]&0x7f) - 64
+	mant := float64(uint24At(b, off+1)) / (1 << 24)
+
+	return sign * mant * math.Pow(16, float64(exp))
+}
@@ -0,0 +1,257 @@
+package grib1_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/gaussian"
+	"github.com/scorix/walg/pkg/grib/grib1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 以下辅助函数按 GRIB1 规范手工拼装消息
+
+func u16(v int) []byte { return []byte{byte(v >> 8), byte(v)} }
+func u24(v int) []byte { return []byte{byte(v >> 16), byte(v >> 8), byte(v)} }
+
+// s16、s24 符号-数值表示的有符号整数
+func s16(v int) []byte {
+	if v < 0 {
+		return u16(-v | 0x8000)
+	}
+	return u16(v)
+}
+
+func s24(v int) []byte {
+	if v < 0 {
+		return u24(-v | 0x800000)
+	}
+	return u24(v)
+}
+
+func milli(v float64) []byte { return s24(int(math.Round(v * 1000))) }
+
+// ibm 编码 IBM 单精度浮点数
+func ibm(v float64) []byte {
+	if v == 0 {
+		return []byte{0, 0, 0, 0}
+	}
+
+	var sign byte
+	if v < 0 {
+		sign, v = 0x80, -v
+	}
+	exp := 64
+	for v >= 1 {
+		v /= 16
+		exp++
+	}
+	for v < 1.0/16 {
+		v *= 16
+		exp--
+	}
+	return append([]byte{sign | byte(exp)}, u24(int(v*(1<<24)))...)
+}
+
+func sec(parts ...[]byte) []byte {
+	body := bytes.Join(parts, nil)
+	return append(u24(len(body)+3), body...)
+}
+
+var ref = time.Date(2008, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// pds 参数 167（2 米温度），单位小时
+func pds(flags byte, tri byte, p1, p2 byte, d int) []byte {
+	return sec([]byte{128, 98, 141, 255, flags, 167, 1}, u16(0),
+		[]byte{byte(ref.Year() % 100), byte(ref.Month()), byte(ref.Day()), byte(ref.Hour()), byte(ref.Minute()), 1, p1, p2, tri},
+		u16(0), []byte{0, byte(ref.Year()/100 + 1), 0}, s16(d), make([]byte, 12))
+}
+
+func latLonGDS(ni, nj int, la1, lo1, la2, lo2, di, dj float64, scan byte) []byte {
+	return sec([]byte{0, 255, 0}, u16(ni), u16(nj), milli(la1), milli(lo1), []byte{0x80},
+		milli(la2), milli(lo2), u16(int(di*1000)), u16(int(dj*1000)), []byte{scan}, make([]byte, 4))
+}
+
+func bds(r float64, e, bits int, data ...byte) []byte {
+	return sec([]byte{0}, s16(e), ibm(r), []byte{byte(bits)}, data)
+}
+
+func message(sections ...[]byte) []byte {
+	body := bytes.Join(sections, nil)
+	total := 8 + len(body) + 4
+	msg := append(append([]byte("GRIB"), u24(total)...), 1)
+	return append(append(msg, body...), "7777"...)
+}
+
+func TestScanModeFromFlags(t *testing.T) {
+	assert.Equal(t, grids.ScanMode(0), grib1.ScanModeFromFlags(0))
+	assert.Equal(t, grids.ScanModeNegativeI, grib1.ScanModeFromFlags(0x80))
+	assert.Equal(t, grids.ScanModePositiveJ, grib1.ScanModeFromFlags(0x40))
+	assert.Equal(t, grids.ScanModeConsecutiveJ, grib1.ScanModeFromFlags(0x20))
+	// 未定义的低位被忽略
+	assert.Equal(t, grids.ScanModePositiveJ, grib1.ScanModeFromFlags(0x4f))
+}
+
+func TestDecode_LatLon(t *testing.T) {
+	// 2x3 网格，X = 0..5，Y = (100 + X) / 10，累积量的时段为 0-6 小时
+	msg := message(
+		pds(0x80, 4, 0, 6, 1),
+		latLonGDS(3, 2, 11, 100, 10, 102, 1, 1, 0),
+		bds(100, 0, 4, 0x01, 0x23, 0x45),
+	)
+
+	msgs, err := grib1.Decode(msg)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+
+	m := msgs[0]
+	assert.Equal(t, uint8(98), m.Centre)
+	assert.Equal(t, uint8(167), m.Parameter)
+	assert.Equal(t, uint8(1), m.LevelType)
+	assert.Equal(t, ref, m.ReferenceTime)
+	assert.Equal(t, 6*time.Hour, m.ForecastTime)
+	assert.Equal(t, []float64{11, 10}, m.Grid.Latitudes())
+	assert.Equal(t, []float64{100, 101, 102}, m.Grid.Longitudes())
+
+	values, err := m.Values()
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{10, 10.1, 10.2, 10.3, 10.4, 10.5}, values, 1e-9)
+
+	r, err := grib1.NewReader(msgs)
+	require.NoError(t, err)
+	assert.Equal(t, ref.Add(6*time.Hour), r.TimeAxis().Time(0))
+
+	v, err := grids.NewGridInterpolator(r, r.Grid(), r.ScanMode(), nil).InterpolateAt(0, 10.5, 100.5)
+	require.NoError(t, err)
+	assert.InDelta(t, 10.2, v, 1e-9)
+}
+
+func TestDecode_BitmapAndScanMode(t *testing.T) {
+	// 南到北扫描，位图 101101，负参考值与二进制比例因子
+	msg := message(
+		pds(0xc0, 0, 3, 0, 0),
+		latLonGDS(3, 2, 10, 100, 11, 102, 1, 1, 0x40),
+		sec([]byte{2}, u16(0), []byte{0xb4, 0}),
+		bds(-2, 1, 8, 1, 2, 3, 4),
+	)
+
+	msgs, err := grib1.Decode(msg)
+	require.NoError(t, err)
+
+	m := msgs[0]
+	assert.Equal(t, grids.ScanModePositiveJ, m.ScanMode)
+	assert.Equal(t, 3*time.Hour, m.ForecastTime)
+
+	values, err := m.Values()
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, values[0])
+	assert.True(t, math.IsNaN(values[1]))
+	assert.Equal(t, []float64{2, 4}, values[2:4])
+	assert.True(t, math.IsNaN(values[4]))
+	assert.Equal(t, 6.0, values[5])
+}
+
+func TestDecode_ReducedGaussian(t *testing.T) {
+	g := gaussian.NewRegular(2)
+	lats := g.Latitudes()
+	pl := []int{4, 8, 8, 4}
+
+	// PL 列表紧随 32 字节的 GDS 之后，位于第 33 字节
+	gds := sec([]byte{0, 33, 4}, u16(0xffff), u16(4), milli(lats[0]), milli(0), []byte{0x80},
+		milli(lats[3]), milli(315), u16(0xffff), u16(2), []byte{0}, make([]byte, 4),
+		u16(pl[0]), u16(pl[1]), u16(pl[2]), u16(pl[3]))
+
+	// 第 1、4 行为 0 10 20 30，第 2、3 行为 0..7
+	data := []byte{0, 10, 20, 30, 0, 1, 2, 3, 4, 5, 6, 7, 0, 1, 2, 3, 4, 5, 6, 7, 0, 10, 20, 30}
+	msg := message(pds(0x80, 0, 0, 0, 0), gds, bds(0, 0, 8, data...))
+
+	msgs, err := grib1.Decode(msg)
+	require.NoError(t, err)
+
+	m := msgs[0]
+	assert.True(t, m.Reduced)
+	assert.Same(t, g, m.Grid)
+	assert.Equal(t, grids.ScanMode(0), m.ScanMode)
+
+	values, err := m.Values()
+	require.NoError(t, err)
+	require.Len(t, values, 32)
+	assert.Equal(t, []float64{0, 5, 10, 15, 20, 25, 30, 15}, values[:8])
+	assert.Equal(t, []float64{0, 1, 2, 3, 4, 5, 6, 7}, values[8:16])
+	assert.Equal(t, values[:8], values[24:])
+
+	// -i 扫描，每个纬圈从经度 315 开始自东向西排列
+	gds = sec([]byte{0, 33, 4}, u16(0xffff), u16(4), milli(lats[0]), milli(315), []byte{0x80},
+		milli(lats[3]), milli(0), u16(0xffff), u16(2), []byte{0x80}, make([]byte, 4),
+		u16(pl[0]), u16(pl[1]), u16(pl[2]), u16(pl[3]))
+	msgs, err = grib1.Decode(message(pds(0x80, 0, 0, 0, 0), gds, bds(0, 0, 8, data...)))
+	require.NoError(t, err)
+	assert.Equal(t, grids.ScanMode(0), msgs[0].ScanMode)
+
+	values, err = msgs[0].Values()
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{15, 30, 25, 20, 15, 10, 5, 0}, values[:8], 1e-9)
+	assert.InDeltaSlice(t, []float64{7, 6, 5, 4, 3, 2, 1, 0}, values[8:16], 1e-9)
+
+	// PL 列表中点数为 0 的纬圈
+	gds = sec([]byte{0, 33, 4}, u16(0xffff), u16(4), milli(lats[0]), milli(0), []byte{0x80},
+		milli(lats[3]), milli(315), u16(0xffff), u16(2), []byte{0}, make([]byte, 4),
+		u16(4), u16(0), u16(8), u16(4))
+	_, err = grib1.Decode(message(pds(0x80, 0, 0, 0, 0), gds, bds(0, 0, 8, data...)))
+	assert.Error(t, err)
+}
+
+func TestDecode_Rotated(t *testing.T) {
+	gds := sec([]byte{0, 255, 10}, u16(21), u16(21), milli(-10), milli(-10), []byte{0x80},
+		milli(10), milli(10), u16(1000), u16(1000), []byte{0x40}, make([]byte, 4),
+		milli(-40), milli(10), ibm(0))
+
+	// 常数场
+	msg := message(pds(0x80, 0, 0, 0, 0), gds, bds(5, 0, 0))
+
+	msgs, err := grib1.Decode(bytes.Repeat(msg, 2))
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+
+	m := msgs[0]
+	assert.Equal(t, uint8(10), m.GridType)
+	require.Implements(t, (*grids.CellLocator)(nil), m.Grid)
+
+	r, err := grib1.NewReader(msgs)
+	require.NoError(t, err)
+
+	// 旋转坐标原点位于地理坐标 (50, 10)
+	v, err := grids.NewGridInterpolator(r, r.Grid(), r.ScanMode(), nil).InterpolateAt(1, 50, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, v)
+
+	_, err = grids.NewGridInterpolator(r, r.Grid(), r.ScanMode(), nil).InterpolateAt(0, 0, 10)
+	assert.Error(t, err)
+}
+
+func TestDecode_Errors(t *testing.T) {
+	_, err := grib1.Decode([]byte("GRIB\x00\x00\x40\x02"))
+	assert.ErrorIs(t, err, grib1.ErrNotGRIB1)
+
+	gds := sec([]byte{0, 255, 5}, make([]byte, 26))
+	_, err = grib1.Decode(message(pds(0x80, 0, 0, 0, 0), gds, bds(0, 0, 0)))
+	assert.ErrorIs(t, err, grib1.ErrUnsupported)
+
+	// 没有 GDS 的预定义网格
+	_, err = grib1.Decode(message(pds(0x00, 0, 0, 0, 0), bds(0, 0, 0)))
+	assert.ErrorIs(t, err, grib1.ErrUnsupported)
+
+	// GDS 声明的点数与网格范围不符
+	_, err = grib1.Decode(message(pds(0x80, 0, 0, 0, 0), latLonGDS(4, 2, 11, 100, 10, 102, 1, 1, 0), bds(0, 0, 0)))
+	assert.Error(t, err)
+
+	// 6 个 8 位的值只有 2 字节数据
+	msgs, err := grib1.Decode(message(pds(0x80, 0, 0, 0, 0), latLonGDS(3, 2, 11, 100, 10, 102, 1, 1, 0), bds(0, 0, 8, 1, 2)))
+	require.NoError(t, err)
+	_, err = msgs[0].Values()
+	assert.Error(t, err)
+}
@@ -0,0 +1,188 @@
+package grib1
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/gaussian"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+	"github.com/scorix/walg/pkg/geo/grids/rotated"
+)
+
+// ScanModeFromFlags 将 GRIB1 代码表 8 的扫描方式标志转换为 ScanMode
+// GRIB1 只定义了前 3 位，含义与 GRIB2 相同，第 1 位为最高位
+func ScanModeFromFlags(flags uint8) grids.ScanMode {
+	return grids.ScanMode(bits.Reverse8(flags & 0xe0))
+}
+
+// gridDefinition GDS 中解码所需的参数
+type gridDefinition struct {
+	ni, nj   int
+	la1, lo1 float64
+	la2, lo2 float64
+	di, dj   float64
+	n        int   // 高斯网格极点到赤道之间的纬圈数
+	pl       []int // 缩减高斯网格每个纬圈的点数
+	scanning uint8
+}
+
+// size 返回原始数据的点数
+func (d *gridDefinition) size() int {
+	if d.pl == nil {
+		return d.ni * d.nj
+	}
+
+	n := 0
+	for _, c := range d.pl {
+		n += c
+	}
+	return n
+}
+
+func (m *Message) parseGrid(gds []byte) error {
+	if len(gds) < 32 {
+		return fmt.Errorf("GDS too short: %d", len(gds))
+	}
+
+	m.GridType = gds[5]
+	switch m.GridType {
+	case 0, 4:
+	case 10:
+		if len(gds) < 42 {
+			return fmt.Errorf("rotated GDS too short: %d", len(gds))
+		}
+	default:
+		return fmt.Errorf("grid type %d: %w", m.GridType, ErrUnsupported)
+	}
+
+	def := &gridDefinition{
+		ni:       int(uint16At(gds, 6)),
+		nj:       int(uint16At(gds, 8)),
+		la1:      float64(int24At(gds, 10)) / 1000,
+		lo1:      float64(int24At(gds, 13)) / 1000,
+		la2:      float64(int24At(gds, 17)) / 1000,
+		lo2:      float64(int24At(gds, 20)) / 1000,
+		scanning: gds[27],
+	}
+	if di := uint16At(gds, 23); di != 0xffff {
+		def.di = float64(di) / 1000
+	}
+	if m.GridType == 4 {
+		def.n = int(uint16At(gds, 25))
+	} else if dj := uint16At(gds, 25); dj != 0xffff {
+		def.dj = float64(dj) / 1000
+	}
+
+	// 准规则网格：Ni 缺测，每个纬圈的点数在 PL 列表中
+	if def.ni == 0xffff {
+		pl, err := parsePL(gds, def.nj)
+		if err != nil {
+			return err
+		}
+		def.pl = pl
+	}
+
+	m.gds = def
+	m.ScanMode = ScanModeFromFlags(def.scanning)
+
+	var err error
+	switch m.GridType {
+	case 0:
+		m.Grid, err = latLonGrid(def)
+	case 4:
+		m.Grid, err = gaussianGrid(def)
+		m.Reduced = def.pl != nil
+		if m.Reduced && def.scanning&0x20 != 0 {
+			return fmt.Errorf("quasi-regular grid with consecutive j scanning: %w", ErrUnsupported)
+		}
+		if m.Reduced {
+			// 展开后按北到南、西到东排列
+			m.ScanMode = 0
+		}
+	case 10:
+		var base grids.Grid
+		if base, err = latLonGrid(def); err == nil {
+			m.Grid = rotated.NewRotated(base,
+				float64(int24At(gds, 32))/1000, float64(int24At(gds, 35))/1000, ibmFloat(gds, 38))
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// 规则网格的数据点数须与网格一致
+	if !m.Reduced && m.Grid.Size() != def.size() {
+		return fmt.Errorf("grid has %d points, GDS declares %dx%d", m.Grid.Size(), def.ni, def.nj)
+	}
+
+	return nil
+}
+
+// parsePL 读取准规则网格每个纬圈的点数
+func parsePL(gds []byte, nj int) ([]int, error) {
+	nv, loc := int(gds[3]), int(gds[4])
+	if loc == 0 || loc == 255 {
+		return nil, fmt.Errorf("quasi-regular grid without PL list")
+	}
+
+	// loc 为从 1 开始的字节位置，PL 列表位于垂直坐标参数之后
+	off := loc - 1 + 4*nv
+	if off+2*nj > len(gds) {
+		return nil, fmt.Errorf("PL list truncated")
+	}
+
+	pl := make([]int, nj)
+	for i := range pl {
+		pl[i] = int(uint16At(gds, off+2*i))
+		if pl[i] == 0 {
+			return nil, fmt.Errorf("PL entry %d is zero", i)
+		}
+	}
+	return pl, nil
+}
+
+// latLonGrid 创建经纬度网格，旋转网格在旋转坐标系中同样使用该网格
+func latLonGrid(def *gridDefinition) (grids.Grid, error) {
+	if def.pl != nil {
+		return nil, fmt.Errorf("quasi-regular lat/lon grid: %w", ErrUnsupported)
+	}
+	if def.ni < 1 || def.nj < 1 {
+		return nil, fmt.Errorf("invalid grid size %dx%d", def.ni, def.nj)
+	}
+
+	west, east := def.lo1, def.lo2
+	if ScanModeFromFlags(def.scanning).IsNegativeI() {
+		west, east = east, west
+	}
+	if east < west {
+		east += 360
+	}
+	// 跨越本初子午线的区域使用负经度表示西经
+	if east > 360 {
+		west, east = west-360, east-360
+	}
+
+	di, dj := def.di, def.dj
+	if def.ni > 1 && di <= 0 {
+		di = (east - west) / float64(def.ni-1)
+	}
+	if def.nj > 1 && dj <= 0 {
+		dj = math.Abs(def.la2-def.la1) / float64(def.nj-1)
+	}
+
+	return latlon.NewLatLonGrid(math.Min(def.la1, def.la2), math.Max(def.la1, def.la2), west, east, dj, di), nil
+}
+
+// gaussianGrid 创建全球规则高斯网格，缩减高斯网格展开到同样 N 的规则网格
+func gaussianGrid(def *gridDefinition) (grids.Grid, error) {
+	if def.n < 1 || def.nj != 2*def.n {
+		return nil, fmt.Errorf("gaussian grid with %d rows and N=%d: %w", def.nj, def.n, ErrUnsupported)
+	}
+	if def.pl == nil && def.ni != 4*def.n {
+		return nil, fmt.Errorf("regional gaussian grid %dx%d: %w", def.ni, def.nj, ErrUnsupported)
+	}
+
+	return gaussian.NewRegular(def.n), nil
+}
@@ -0,0 +1,32 @@
+package grib1
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/scorix/walg/pkg/grib"
+)
+
+// NewReader 将一组消息作为 ValueReader 使用，第 i 个时间步对应第 i 条消息
+// 所有消息必须位于相同的网格和扫描方式上
+func NewReader(msgs []*Message) (*grib.Reader, error) {
+	if len(msgs) == 0 {
+		return nil, errors.New("no messages")
+	}
+
+	times := make([]time.Time, len(msgs))
+	for i, m := range msgs {
+		// 旋转网格每条消息各自创建，按内容比较
+		sameGrid := m.Grid == msgs[0].Grid || reflect.DeepEqual(m.Grid, msgs[0].Grid)
+		if !sameGrid || m.ScanMode != msgs[0].ScanMode {
+			return nil, fmt.Errorf("message %d is on a different grid", i)
+		}
+		times[i] = m.ValidTime()
+	}
+
+	return grib.NewReader(msgs[0].Grid, msgs[0].ScanMode, times, func(step int) ([]float64, error) {
+		return msgs[step].Values()
+	}), nil
+}
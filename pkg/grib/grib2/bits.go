@@ -0,0 +1,75 @@
+package grib2
+
+import "math"
+
+// uint16At 等按大端序读取无符号整数
+func uint16At(b []byte, off int) uint16 {
+	return uint16(b[off])<<8 | uint16(b[off+1])
+}
+
+func uint32At(b []byte, off int) uint32 {
+	return uint32(b[off])<<24 | uint32(b[off+1])<<16 | uint32(b[off+2])<<8 | uint32(b[off+3])
+}
+
+func uint64At(b []byte, off int) uint64 {
+	return uint64(uint32At(b, off))<<32 | uint64(uint32At(b, off+4))
+}
+
+// int16At 读取 GRIB2 符号-数值表示的有符号整数（最高位为符号位）
+func int16At(b []byte, off int) int16 {
+	v := uint16At(b, off)
+	if v&0x8000 != 0 {
+		return -int16(v & 0x7fff)
+	}
+	return int16(v)
+}
+
+func int32At(b []byte, off int) int32 {
+	v := uint32At(b, off)
+	if v&0x80000000 != 0 {
+		return -int32(v & 0x7fffffff)
+	}
+	return int32(v)
+}
+
+// intN 将 n 字节的符号-数值表示转换为有符号整数
+func intN(b []byte) int64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	sign := uint64(1) << (8*len(b) - 1)
+	if v&sign != 0 {
+		return -int64(v &^ sign)
+	}
+	return int64(v)
+}
+
+func float32At(b []byte, off int) float32 {
+	return math.Float32frombits(uint32At(b, off))
+}
+
+// putInt16 等按 GRIB2 符号-数值表示写入有符号整数
+func putInt16(b []byte, v int) []byte {
+	u := uint16(v)
+	if v < 0 {
+		u = uint16(-v) | 0x8000
+	}
+	return append(b, byte(u>>8), byte(u))
+}
+
+func putInt32(b []byte, v int64) []byte {
+	u := uint32(v)
+	if v < 0 {
+		u = uint32(-v) | 0x80000000
+	}
+	return putUint32(b, u)
+}
+
+func putUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+func putUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
@@ -0,0 +1,283 @@
+package grib2
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/scorix/walg/pkg/grib/internal/bitio"
+)
+
+// packing 模板 5.0/5.2/5.3 共有的简单打包参数
+type packing struct {
+	reference float32
+	binary    int // E
+	decimal   int // D
+	bits      int
+}
+
+func (m *Message) packing() packing {
+	return packing{
+		reference: float32At(m.drs, 11),
+		binary:    int(int16At(m.drs, 15)),
+		decimal:   int(int16At(m.drs, 17)),
+		bits:      int(m.drs[19]),
+	}
+}
+
+// value 计算 Y = (R + X * 2^E) / 10^D
+func (p packing) value(x int64) float64 {
+	return (float64(p.reference) + float64(x)*math.Ldexp(1, p.binary)) / math.Pow10(p.decimal)
+}
+
+// Values 解码场的值，按网格索引（即扫描方式规定的顺序）排列，缺测点为 NaN
+func (m *Message) Values() ([]float64, error) {
+	// 打包的值个数来自第 5 段，解码前先与网格点数和位图核对，避免按损坏的个数分配内存
+	if m.NumValues < 0 || m.NumValues > m.NumPoints {
+		return nil, fmt.Errorf("%d packed values for %d grid points", m.NumValues, m.NumPoints)
+	}
+	if m.bitmap != nil && len(m.bitmap)*8 < m.NumPoints {
+		return nil, fmt.Errorf("bitmap too short: %d bits for %d points", len(m.bitmap)*8, m.NumPoints)
+	}
+
+	var (
+		packed []float64
+		err    error
+	)
+
+	switch m.DataTemplate {
+	case 0:
+		packed, err = m.unpackSimple()
+	case 2, 3:
+		packed, err = m.unpackComplex()
+	default:
+		err = fmt.Errorf("data representation template 5.%d: %w", m.DataTemplate, ErrUnsupported)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if m.bitmap == nil {
+		if len(packed) != m.NumPoints {
+			return nil, fmt.Errorf("decoded %d values for %d grid points", len(packed), m.NumPoints)
+		}
+		return packed, nil
+	}
+
+	return applyBitmap(m.bitmap, packed, m.NumPoints)
+}
+
+// applyBitmap 按位图将打包的值展开到所有网格点，位图为 0 的点为 NaN
+func applyBitmap(bitmap []byte, packed []float64, n int) ([]float64, error) {
+	if len(bitmap)*8 < n {
+		return nil, fmt.Errorf("bitmap too short: %d bits for %d points", len(bitmap)*8, n)
+	}
+
+	values := make([]float64, n)
+	k := 0
+	for i := range values {
+		if bitmap[i/8]&(0x80>>(i%8)) == 0 {
+			values[i] = math.NaN()
+			continue
+		}
+		if k >= len(packed) {
+			return nil, fmt.Errorf("bitmap has more points than %d packed values", len(packed))
+		}
+		values[i] = packed[k]
+		k++
+	}
+
+	if k != len(packed) {
+		return nil, fmt.Errorf("bitmap has %d points for %d packed values", k, len(packed))
+	}
+	return values, nil
+}
+
+// unpackSimple 解码模板 5.0 简单打包
+func (m *Message) unpackSimple() ([]float64, error) {
+	p := m.packing()
+	if int64(m.NumValues)*int64(p.bits) > int64(len(m.data))*8 {
+		return nil, fmt.Errorf("%d values of %d bits in %d bytes: %w", m.NumValues, p.bits, len(m.data), bitio.ErrShortData)
+	}
+	values := make([]float64, m.NumValues)
+
+	// 位宽为 0 时为常数场
+	if p.bits == 0 {
+		v := p.value(0)
+		for i := range values {
+			values[i] = v
+		}
+		return values, nil
+	}
+
+	br := bitio.NewReader(m.data)
+	for i := range values {
+		x, err := br.Read(p.bits)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = p.value(int64(x))
+	}
+
+	return values, nil
+}
+
+// unpackComplex 解码模板 5.2 复杂打包和 5.3 带空间差分的复杂打包
+func (m *Message) unpackComplex() ([]float64, error) {
+	p := m.packing()
+	drs := m.drs
+
+	missingMode := drs[22]
+	if missingMode > 2 {
+		return nil, fmt.Errorf("missing value management %d: %w", missingMode, ErrUnsupported)
+	}
+
+	ng := int(uint32At(drs, 31))
+	widthRef := int64(drs[35])
+	widthBits := int(drs[36])
+	lengthRef := int64(uint32At(drs, 37))
+	lengthIncr := int64(drs[41])
+	lastLength := int64(uint32At(drs, 42))
+	lengthBits := int(drs[46])
+
+	// 每个组至少对应一个值，组数不会超过打包的值个数；组的参考值、宽度和长度都需要数据
+	if ng > m.NumValues {
+		return nil, fmt.Errorf("%d groups for %d values", ng, m.NumValues)
+	}
+	if int64(ng)*int64(p.bits+widthBits+lengthBits) > int64(len(m.data))*8 {
+		return nil, fmt.Errorf("%d groups in %d bytes: %w", ng, len(m.data), bitio.ErrShortData)
+	}
+
+	br := bitio.NewReader(m.data)
+
+	// 空间差分的附加描述符：初始值和整体最小值
+	var (
+		order int
+		first [2]int64
+		minv  int64
+	)
+	if m.DataTemplate == 3 {
+		order = int(drs[47])
+		ospd := int(drs[48])
+		if order < 1 || order > 2 || ospd < 1 || ospd > 4 {
+			return nil, fmt.Errorf("spatial differencing order %d with %d octets: %w", order, ospd, ErrUnsupported)
+		}
+
+		if len(m.data) < (order+1)*ospd {
+			return nil, bitio.ErrShortData
+		}
+		for i := 0; i < order; i++ {
+			first[i] = intN(m.data[i*ospd : (i+1)*ospd])
+		}
+		minv = intN(m.data[order*ospd : (order+1)*ospd])
+		br.Seek((order + 1) * ospd * 8)
+	}
+
+	readGroup := func(bits int, f func(v int64) int64) ([]int64, error) {
+		out := make([]int64, ng)
+		for i := range out {
+			v, err := br.Read(bits)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = f(int64(v))
+		}
+		br.Align()
+		return out, nil
+	}
+
+	refs, err := readGroup(p.bits, func(v int64) int64 { return v })
+	if err != nil {
+		return nil, fmt.Errorf("group references: %w", err)
+	}
+	widths, err := readGroup(widthBits, func(v int64) int64 { return widthRef + v })
+	if err != nil {
+		return nil, fmt.Errorf("group widths: %w", err)
+	}
+	lengths, err := readGroup(lengthBits, func(v int64) int64 { return lengthRef + v*lengthIncr })
+	if err != nil {
+		return nil, fmt.Errorf("group lengths: %w", err)
+	}
+	if ng > 0 {
+		lengths[ng-1] = lastLength
+	}
+
+	ints := make([]int64, 0, m.NumValues)
+	missing := make([]bool, 0, m.NumValues)
+	for g := 0; g < ng; g++ {
+		width := int(widths[g])
+		if lengths[g] > int64(m.NumValues-len(ints)) {
+			return nil, fmt.Errorf("group %d length %d exceeds %d values", g, lengths[g], m.NumValues)
+		}
+		for k := int64(0); k < lengths[g]; k++ {
+			var raw int64
+			isMissing := false
+
+			if width == 0 {
+				raw = 0
+				isMissing = isMissingCode(refs[g], p.bits, missingMode)
+			} else {
+				v, err := br.Read(width)
+				if err != nil {
+					return nil, fmt.Errorf("group %d values: %w", g, err)
+				}
+				raw = int64(v)
+				isMissing = isMissingCode(raw, width, missingMode)
+			}
+
+			ints = append(ints, refs[g]+raw)
+			missing = append(missing, isMissing)
+		}
+	}
+
+	if len(ints) != m.NumValues {
+		return nil, fmt.Errorf("decoded %d values, expected %d", len(ints), m.NumValues)
+	}
+
+	if order > 0 {
+		undoSpatialDifferencing(ints, missing, order, first, minv)
+	}
+
+	values := make([]float64, len(ints))
+	for i, x := range ints {
+		if missing[i] {
+			values[i] = math.NaN()
+			continue
+		}
+		values[i] = p.value(x)
+	}
+
+	return values, nil
+}
+
+// isMissingCode 判断打包值是否为缺测编码：主缺测值为全 1，次缺测值为全 1 减 1
+func isMissingCode(v int64, bits int, mode uint8) bool {
+	if mode == 0 || bits == 0 {
+		return false
+	}
+
+	all := int64(1)<<bits - 1
+	return v == all || (mode == 2 && v == all-1)
+}
+
+// undoSpatialDifferencing 还原一阶或二阶空间差分，缺测点不参与差分
+func undoSpatialDifferencing(ints []int64, missing []bool, order int, first [2]int64, minv int64) {
+	var prev [2]int64 // prev[0] 为前一个值，prev[1] 为再前一个值
+	n := 0
+	for i := range ints {
+		if missing[i] {
+			continue
+		}
+
+		switch {
+		case n < order:
+			ints[i] = first[n]
+		case order == 1:
+			ints[i] = ints[i] + minv + prev[0]
+		default:
+			ints[i] = ints[i] + minv + 2*prev[0] - prev[1]
+		}
+
+		prev[1], prev[0] = prev[0], ints[i]
+		n++
+	}
+}
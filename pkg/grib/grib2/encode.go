@@ -0,0 +1,332 @@
+package grib2
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/gaussian"
+	"github.com/scorix/walg/pkg/grib/internal/bitio"
+)
+
+// Field 待编码的场
+type Field struct {
+	Discipline    uint8
+	Centre        uint16
+	SubCentre     uint16
+	ReferenceTime time.Time
+
+	Category      uint8
+	Number        uint8
+	ForecastTime  time.Duration
+	FirstSurface  Surface // Value 为 NaN 时编码为缺测
+	SecondSurface Surface
+
+	Grid     grids.Grid
+	ScanMode grids.ScanMode
+	// Values 按网格索引排列的值，NaN 表示缺测，缺测点通过位图编码
+	Values []float64
+}
+
+// Packing 简单打包参数
+// Bits 为每个值的位数，为 0 时按 DecimalScale 保留的精度自动确定位数；
+// 两者同时指定时先按 10^DecimalScale 缩放，再按 Bits 位量化
+type Packing struct {
+	Bits         int
+	DecimalScale int
+}
+
+// Encode 将场编码为一条 GRIB2 消息写入 w
+func Encode(w io.Writer, f *Field, p Packing) error {
+	msg, err := EncodeMessage(f, p)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(msg)
+	return err
+}
+
+// EncodeMessage 将场编码为一条 GRIB2 消息
+func EncodeMessage(f *Field, p Packing) ([]byte, error) {
+	if f.Grid == nil {
+		return nil, errors.New("no grid")
+	}
+	if len(f.Values) != f.Grid.Size() {
+		return nil, fmt.Errorf("%d values for %d grid points", len(f.Values), f.Grid.Size())
+	}
+	if p.Bits < 0 || p.Bits > 32 {
+		return nil, fmt.Errorf("invalid bit depth: %d", p.Bits)
+	}
+
+	gds, err := gridSection(f.Grid, f.ScanMode)
+	if err != nil {
+		return nil, err
+	}
+
+	pds, err := productSection(f)
+	if err != nil {
+		return nil, err
+	}
+
+	valid := make([]float64, 0, len(f.Values))
+	for _, v := range f.Values {
+		if !math.IsNaN(v) {
+			valid = append(valid, v)
+		}
+	}
+
+	drs, packed, err := simplePack(valid, p)
+	if err != nil {
+		return nil, err
+	}
+
+	sections := [][]byte{identificationSection(f), gds, pds, drs, bitmapSection(f.Values, len(valid)), newSection(7, packed)}
+
+	total := 16 + 4
+	for _, s := range sections {
+		total += len(s)
+	}
+
+	msg := make([]byte, 0, total)
+	msg = append(msg, 'G', 'R', 'I', 'B', 0, 0, f.Discipline, 2)
+	msg = putUint32(putUint32(msg, uint32(uint64(total)>>32)), uint32(total))
+	for _, s := range sections {
+		msg = append(msg, s...)
+	}
+	return append(msg, '7', '7', '7', '7'), nil
+}
+
+// newSection 为段内容加上长度和段号
+func newSection(num byte, body []byte) []byte {
+	sec := putUint32(make([]byte, 0, len(body)+5), uint32(len(body)+5))
+	return append(append(sec, num), body...)
+}
+
+func identificationSection(f *Field) []byte {
+	t := f.ReferenceTime.UTC()
+
+	b := putUint16(nil, f.Centre)
+	b = putUint16(b, f.SubCentre)
+	// 主表版本 2，本地表 0，参考时间为预报起始时间
+	b = append(b, 2, 0, 1)
+	b = putUint16(b, uint16(t.Year()))
+	b = append(b, byte(t.Month()), byte(t.Day()), byte(t.Hour()), byte(t.Minute()), byte(t.Second()))
+	// 业务产品，预报产品
+	b = append(b, 0, 1)
+	return newSection(1, b)
+}
+
+// gridSection 根据网格生成第 3 段，规则高斯网格使用模板 3.40，其余使用模板 3.0
+func gridSection(g grids.Grid, mode grids.ScanMode) ([]byte, error) {
+	lats, lons := g.Latitudes(), g.Longitudes()
+	ni, nj := len(lons), len(lats)
+	if ni < 1 || nj < 1 || ni*nj != g.Size() {
+		return nil, fmt.Errorf("grid %dx%d with %d points: %w", ni, nj, g.Size(), ErrUnsupported)
+	}
+
+	if _, ok := g.(grids.GeographicLocator); ok {
+		return nil, fmt.Errorf("grid with non-geographic coordinates: %w", ErrUnsupported)
+	}
+
+	template := uint16(0)
+	if _, ok := gaussian.RegularNumber(g); ok {
+		template = 40
+	} else if !evenlySpaced(lons) || !evenlySpaced(lats) {
+		return nil, fmt.Errorf("irregularly spaced grid: %w", ErrUnsupported)
+	}
+
+	// 首末点按扫描方向确定
+	la1, la2 := lats[0], lats[nj-1]
+	if mode.IsPositiveJ() {
+		la1, la2 = la2, la1
+	}
+	lo1, lo2 := lons[0], lons[ni-1]
+	if mode.IsNegativeI() {
+		lo1, lo2 = lo2, lo1
+	}
+
+	var di, dj float64
+	if ni > 1 {
+		di = math.Abs(lons[1] - lons[0])
+	}
+	if nj > 1 {
+		dj = math.Abs(lats[0] - lats[1])
+	}
+
+	micro := func(v float64) int64 { return int64(math.Round(v * 1e6)) }
+	lon := func(v float64) int64 { return micro(math.Mod(math.Mod(v, 360)+360, 360)) }
+
+	b := []byte{0}
+	b = putUint32(b, uint32(g.Size()))
+	b = append(b, 0, 0)
+	b = putUint16(b, template)
+	// 地球形状：半径 6371229 米的球体
+	b = append(b, 6, 0)
+	b = putUint32(b, 0)
+	b = append(b, 0)
+	b = putUint32(b, 0)
+	b = append(b, 0)
+	b = putUint32(b, 0)
+	b = putUint32(b, uint32(ni))
+	b = putUint32(b, uint32(nj))
+	// 基本角度为 0，角度单位为 1e-6 度
+	b = putUint32(b, 0)
+	b = putUint32(b, 0xffffffff)
+	b = putInt32(b, micro(la1))
+	b = putUint32(b, uint32(lon(lo1)))
+	b = append(b, 0x30)
+	b = putInt32(b, micro(la2))
+	b = putUint32(b, uint32(lon(lo2)))
+	b = putUint32(b, uint32(micro(di)))
+	if template == 40 {
+		b = putUint32(b, uint32(nj/2))
+	} else {
+		b = putUint32(b, uint32(micro(dj)))
+	}
+	b = append(b, ScanModeFlags(mode))
+
+	return newSection(3, b), nil
+}
+
+// evenlySpaced 判断坐标是否等间隔，误差不超过模板 3.0 的角度精度 1e-6 度
+func evenlySpaced(v []float64) bool {
+	for i := 2; i < len(v); i++ {
+		if math.Abs((v[i]-v[i-1])-(v[1]-v[0])) > 1e-6 {
+			return false
+		}
+	}
+	return true
+}
+
+// productSection 生成模板 4.0 的第 4 段
+func productSection(f *Field) ([]byte, error) {
+	unit, value, err := forecastTime(f.ForecastTime)
+	if err != nil {
+		return nil, err
+	}
+
+	b := putUint16(nil, 0)
+	b = putUint16(b, 0)
+	// 参数类别、编号，生成过程类型为预报
+	b = append(b, f.Category, f.Number, 2, 0, 0)
+	b = putUint16(b, 0)
+	b = append(b, 0, unit)
+	b = putInt32(b, value)
+	b = appendSurface(b, f.FirstSurface)
+	b = appendSurface(b, f.SecondSurface)
+	return newSection(4, b), nil
+}
+
+// forecastTime 选择能精确表示预报时效的最大时间单位
+func forecastTime(d time.Duration) (uint8, int64, error) {
+	for _, u := range []struct {
+		code uint8
+		unit time.Duration
+	}{{1, time.Hour}, {0, time.Minute}, {13, time.Second}} {
+		if d%u.unit == 0 {
+			v := int64(d / u.unit)
+			if v > math.MaxInt32 || v < -math.MaxInt32 {
+				break
+			}
+			return u.code, v, nil
+		}
+	}
+
+	return 0, 0, fmt.Errorf("forecast time %s cannot be encoded", d)
+}
+
+// appendSurface 以 类型 比例因子 比例值 编码固定面，比例因子取能精确表示的最小值
+func appendSurface(b []byte, s Surface) []byte {
+	if math.IsNaN(s.Value) {
+		return putUint32(append(b, s.Type, 0xff), 0xffffffff)
+	}
+
+	scale := 0
+	for ; scale < 9; scale++ {
+		v := s.Value * math.Pow10(scale)
+		if math.Abs(v-math.Round(v)) < 1e-9*math.Max(1, math.Abs(v)) {
+			break
+		}
+	}
+
+	b = append(b, s.Type, byte(scale))
+	return putInt32(b, int64(math.Round(s.Value*math.Pow10(scale))))
+}
+
+// simplePack 按模板 5.0 打包有效值，返回第 5 段和第 7 段的数据
+func simplePack(values []float64, p Packing) ([]byte, []byte, error) {
+	scale := math.Pow10(p.DecimalScale)
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo = math.Min(lo, v*scale)
+		hi = math.Max(hi, v*scale)
+	}
+	if len(values) == 0 {
+		lo, hi = 0, 0
+	}
+	if math.IsInf(lo, 0) || math.IsInf(hi, 0) {
+		return nil, nil, errors.New("values must be finite")
+	}
+
+	// 参考值以 float32 存储，不能大于最小值
+	ref := float32(lo)
+	if float64(ref) > lo {
+		ref = math.Nextafter32(ref, float32(math.Inf(-1)))
+	}
+	r := float64(ref)
+
+	bits, e := p.Bits, 0
+	switch {
+	case hi-r == 0:
+		bits = 0
+	case bits == 0:
+		// 按十进制精度量化
+		maxX := math.Round(hi - r)
+		bits = int(math.Ceil(math.Log2(maxX + 1)))
+		if bits > 32 {
+			return nil, nil, fmt.Errorf("decimal scale %d needs %d bits", p.DecimalScale, bits)
+		}
+	default:
+		maxX := math.Exp2(float64(bits)) - 1
+		e = int(math.Ceil(math.Log2((hi - r) / maxX)))
+	}
+
+	drs := putUint32(nil, uint32(len(values)))
+	drs = putUint16(drs, 0)
+	drs = putUint32(drs, math.Float32bits(ref))
+	drs = putInt16(drs, e)
+	drs = putInt16(drs, p.DecimalScale)
+	drs = append(drs, byte(bits), 0)
+
+	bw := &bitio.Writer{}
+	if bits > 0 {
+		step := math.Ldexp(1, e)
+		maxX := math.Exp2(float64(bits)) - 1
+		for _, v := range values {
+			x := math.Round((v*scale - r) / step)
+			bw.Write(uint64(math.Min(math.Max(x, 0), maxX)), bits)
+		}
+	}
+
+	return newSection(5, drs), bw.Bytes(), nil
+}
+
+// bitmapSection 有缺测值时生成位图，否则生成不带位图的第 6 段
+func bitmapSection(values []float64, valid int) []byte {
+	if valid == len(values) {
+		return newSection(6, []byte{255})
+	}
+
+	bitmap := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if !math.IsNaN(v) {
+			bitmap[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return newSection(6, append([]byte{0}, bitmap...))
+}
@@ -0,0 +1,160 @@
+package grib2_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/gaussian"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+	"github.com/scorix/walg/pkg/geo/grids/rotated"
+	"github.com/scorix/walg/pkg/grib/grib2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testField(grid grids.Grid, mode grids.ScanMode) *grib2.Field {
+	values := make([]float64, grid.Size())
+	for i := range values {
+		lat, lon, _ := grids.GridPoint(grid, i, mode)
+		values[i] = 273.15 + lat/3 + math.Sin(lon*math.Pi/180)
+	}
+
+	return &grib2.Field{
+		Discipline:    0,
+		Centre:        98,
+		ReferenceTime: ref,
+		Category:      0,
+		Number:        0,
+		ForecastTime:  90 * time.Minute,
+		FirstSurface:  grib2.Surface{Type: 100, Value: 85000},
+		SecondSurface: grib2.Surface{Type: 255, Value: math.NaN()},
+		Grid:          grid,
+		ScanMode:      mode,
+		Values:        values,
+	}
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		grid    grids.Grid
+		mode    grids.ScanMode
+		packing grib2.Packing
+		delta   float64
+	}{
+		{name: "decimal scale", grid: latlon.NewLatLonGrid(10, 20, 100, 115, 0.5, 0.5), packing: grib2.Packing{DecimalScale: 2}, delta: 0.005},
+		{name: "bit depth", grid: latlon.NewLatLonGrid(10, 20, 100, 115, 0.5, 0.5), packing: grib2.Packing{Bits: 16}, delta: 1e-3},
+		{name: "south to north", grid: latlon.NewLatLonGrid(-5, 5, -10, 10, 1, 1), mode: grids.ScanModePositiveJ, packing: grib2.Packing{Bits: 12}, delta: 0.01},
+		{name: "consecutive j, -i", grid: latlon.NewLatLonGrid(0, 3, 0, 4, 1, 1), mode: grids.ScanModeConsecutiveJ | grids.ScanModeNegativeI, packing: grib2.Packing{DecimalScale: 3}, delta: 5e-4},
+		{name: "global gaussian", grid: gaussian.NewRegular(8), packing: grib2.Packing{Bits: 24, DecimalScale: 1}, delta: 1e-4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := testField(tt.grid, tt.mode)
+
+			var buf bytes.Buffer
+			require.NoError(t, grib2.Encode(&buf, f, tt.packing))
+
+			msgs, err := grib2.Decode(buf.Bytes())
+			require.NoError(t, err)
+			require.Len(t, msgs, 1)
+
+			m := msgs[0]
+			assert.Equal(t, f.Centre, m.Centre)
+			assert.Equal(t, ref, m.ReferenceTime)
+			assert.Equal(t, f.ForecastTime, m.ForecastTime)
+			assert.Equal(t, f.FirstSurface, m.FirstSurface)
+			assert.Equal(t, tt.mode, m.ScanMode)
+			assert.InDeltaSlice(t, tt.grid.Latitudes(), m.Grid.Latitudes(), 1e-6)
+			assert.InDeltaSlice(t, tt.grid.Longitudes(), m.Grid.Longitudes(), 1e-6)
+
+			values, err := m.Values()
+			require.NoError(t, err)
+			assert.InDeltaSlice(t, f.Values, values, tt.delta)
+		})
+	}
+}
+
+func TestEncode_Bitmap(t *testing.T) {
+	f := testField(latlon.NewLatLonGrid(0, 2, 0, 2, 1, 1), 0)
+	f.Values[1] = math.NaN()
+	f.Values[7] = math.NaN()
+
+	msg, err := grib2.EncodeMessage(f, grib2.Packing{DecimalScale: 2})
+	require.NoError(t, err)
+
+	msgs, err := grib2.Decode(msg)
+	require.NoError(t, err)
+	assert.Equal(t, 7, msgs[0].NumValues)
+
+	values, err := msgs[0].Values()
+	require.NoError(t, err)
+	for i, v := range f.Values {
+		if math.IsNaN(v) {
+			assert.True(t, math.IsNaN(values[i]))
+			continue
+		}
+		assert.InDelta(t, v, values[i], 0.005)
+	}
+
+	// 全部缺测
+	for i := range f.Values {
+		f.Values[i] = math.NaN()
+	}
+	msg, err = grib2.EncodeMessage(f, grib2.Packing{})
+	require.NoError(t, err)
+	msgs, err = grib2.Decode(msg)
+	require.NoError(t, err)
+	values, err = msgs[0].Values()
+	require.NoError(t, err)
+	assert.True(t, math.IsNaN(values[4]))
+}
+
+func TestEncode_ConstantAndErrors(t *testing.T) {
+	f := testField(latlon.NewLatLonGrid(0, 1, 0, 1, 1, 1), 0)
+	for i := range f.Values {
+		f.Values[i] = -1.5
+	}
+
+	msg, err := grib2.EncodeMessage(f, grib2.Packing{Bits: 8})
+	require.NoError(t, err)
+	msgs, err := grib2.Decode(msg)
+	require.NoError(t, err)
+	values, err := msgs[0].Values()
+	require.NoError(t, err)
+	assert.Equal(t, []float64{-1.5, -1.5, -1.5, -1.5}, values)
+
+	f.Values = f.Values[:3]
+	_, err = grib2.EncodeMessage(f, grib2.Packing{})
+	assert.Error(t, err)
+
+	f = testField(latlon.NewLatLonGrid(0, 1, 0, 1, 1, 1), 0)
+	_, err = grib2.EncodeMessage(f, grib2.Packing{Bits: 40})
+	assert.Error(t, err)
+
+	f.ForecastTime = 1500 * time.Millisecond
+	_, err = grib2.EncodeMessage(f, grib2.Packing{})
+	assert.Error(t, err)
+}
+
+// irregularGrid 经度不等间隔的网格
+type irregularGrid struct{}
+
+func (irregularGrid) Size() int                                     { return 6 }
+func (irregularGrid) Latitudes() []float64                          { return []float64{1, 0} }
+func (irregularGrid) Longitudes() []float64                         { return []float64{0, 1, 3} }
+func (irregularGrid) GetNearestIndex(lat, lon float64) (int, int)   { return 0, 0 }
+func (irregularGrid) GuessNearestIndex(lat, lon float64) (int, int) { return 0, 0 }
+
+func TestEncode_UnsupportedGrids(t *testing.T) {
+	_, err := grib2.EncodeMessage(testField(irregularGrid{}, 0), grib2.Packing{})
+	assert.ErrorIs(t, err, grib2.ErrUnsupported)
+
+	rot := rotated.NewRotated(latlon.NewLatLonGrid(-10, 10, -10, 10, 1, 1), -40, 10, 0)
+	_, err = grib2.EncodeMessage(testField(rot, 0), grib2.Packing{})
+	assert.ErrorIs(t, err, grib2.ErrUnsupported)
+}
@@ -0,0 +1,318 @@
+// Package grib2 实现了 GRIB2 格式的纯 Go 解码
+// 支持的网格模板为 3.0（经纬度网格）和 3.40（高斯网格），
+// 支持的数据模板为 5.0（简单打包）、5.2（复杂打包）和 5.3（带空间差分的复杂打包）
+package grib2
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+)
+
+var (
+	// ErrNotGRIB2 数据不是 GRIB2 消息
+	ErrNotGRIB2 = errors.New("not a GRIB2 message")
+	// ErrUnsupported 不支持的模板或特性
+	ErrUnsupported = errors.New("unsupported GRIB2 feature")
+)
+
+// Surface 固定面（表 4.5），Value 已按比例因子换算
+type Surface struct {
+	Type  uint8
+	Value float64
+}
+
+// Message 一个 GRIB2 场
+// 一条 GRIB2 消息可以包含多个场，每个场对应一个 Message，共享的段在各个 Message 中重复引用
+type Message struct {
+	Discipline    uint8
+	Centre        uint16
+	SubCentre     uint16
+	ReferenceTime time.Time
+
+	GridTemplate uint16
+	Grid         grids.Grid
+	ScanMode     grids.ScanMode
+
+	ProductTemplate uint16
+	Category        uint8
+	Number          uint8
+	ForecastTime    time.Duration
+	FirstSurface    Surface
+	SecondSurface   Surface
+
+	DataTemplate uint16
+	// NumPoints 网格点数，NumValues 实际打包的值个数（有位图时小于网格点数）
+	NumPoints int
+	NumValues int
+
+	drs    []byte // 第 5 段
+	bitmap []byte // 第 6 段位图，没有位图时为 nil
+	data   []byte // 第 7 段数据
+}
+
+// ValidTime 返回有效时间，即起报时间加预报时效
+func (m *Message) ValidTime() time.Time {
+	return m.ReferenceTime.Add(m.ForecastTime)
+}
+
+// maxMessageLength 允许的最大消息长度，第 0 段的长度字段为 64 位，
+// 超过该长度的消息视为数据损坏，避免一次性申请过大的内存
+const maxMessageLength = 1 << 31
+
+// ReadMessage 从 r 读取下一条 GRIB2 消息中的所有场，r 中没有更多消息时返回 io.EOF
+// 消息之间的非 GRIB 字节被跳过
+func ReadMessage(r io.Reader) ([]*Message, error) {
+	header, err := findHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if header[7] != 2 {
+		return nil, fmt.Errorf("edition %d: %w", header[7], ErrNotGRIB2)
+	}
+
+	total := uint64At(header, 8)
+	if total < 16+4 || total > maxMessageLength {
+		return nil, fmt.Errorf("invalid message length %d: %w", total, ErrNotGRIB2)
+	}
+
+	body := make([]byte, total-16)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("read message: %w", err)
+	}
+
+	return parseMessage(header[6], body)
+}
+
+// Decode 解码 data 中的所有 GRIB2 消息
+func Decode(data []byte) ([]*Message, error) {
+	r := bytes.NewReader(data)
+
+	var msgs []*Message
+	for {
+		m, err := ReadMessage(r)
+		if errors.Is(err, io.EOF) {
+			return msgs, nil
+		}
+		if err != nil {
+			return msgs, err
+		}
+		msgs = append(msgs, m...)
+	}
+}
+
+// findHeader 查找 "GRIB" 标识并返回第 0 段
+func findHeader(r io.Reader) ([]byte, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r, header[:4]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+
+	for !bytes.Equal(header[:4], []byte("GRIB")) {
+		copy(header, header[1:4])
+		if _, err := io.ReadFull(r, header[3:4]); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+	}
+
+	if _, err := io.ReadFull(r, header[4:]); err != nil {
+		return nil, fmt.Errorf("read indicator section: %w", err)
+	}
+
+	return header, nil
+}
+
+// parseMessage 解析第 1 段到第 8 段，body 不含第 0 段
+func parseMessage(discipline uint8, body []byte) ([]*Message, error) {
+	var (
+		msgs    []*Message
+		cur     = &Message{Discipline: discipline}
+		bitmap  []byte
+		hasGrid bool
+	)
+
+	off := 0
+	for {
+		if off+4 <= len(body) && bytes.Equal(body[off:off+4], []byte("7777")) {
+			return msgs, nil
+		}
+		if off+5 > len(body) {
+			return nil, fmt.Errorf("truncated message at offset %d", off+16)
+		}
+
+		length := int(uint32At(body, off))
+		if length < 5 || off+length > len(body) {
+			return nil, fmt.Errorf("invalid section length %d at offset %d", length, off+16)
+		}
+
+		sec := body[off : off+length]
+		off += length
+
+		var err error
+		switch sec[4] {
+		case 1:
+			err = cur.parseIdentification(sec)
+		case 2:
+			// 本地使用段，忽略
+		case 3:
+			err = cur.parseGrid(sec)
+			hasGrid = true
+		case 4:
+			err = cur.parseProduct(sec)
+		case 5:
+			err = cur.parseDataRepresentation(sec)
+		case 6:
+			bitmap, err = parseBitmap(sec, bitmap)
+			cur.bitmap = bitmap
+		case 7:
+			if !hasGrid || cur.drs == nil {
+				return nil, errors.New("data section before grid or data representation section")
+			}
+			cur.data = sec[5:]
+			msgs = append(msgs, cur)
+
+			// 同一消息中的后续场沿用此前的段
+			next := *cur
+			next.bitmap, next.data = nil, nil
+			cur = &next
+		default:
+			err = fmt.Errorf("unknown section %d", sec[4])
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (m *Message) parseIdentification(sec []byte) error {
+	if len(sec) < 21 {
+		return fmt.Errorf("section 1 too short: %d", len(sec))
+	}
+
+	m.Centre = uint16At(sec, 5)
+	m.SubCentre = uint16At(sec, 7)
+	m.ReferenceTime = time.Date(int(uint16At(sec, 12)), time.Month(sec[14]), int(sec[15]),
+		int(sec[16]), int(sec[17]), int(sec[18]), 0, time.UTC)
+	return nil
+}
+
+func (m *Message) parseProduct(sec []byte) error {
+	if len(sec) < 9 {
+		return fmt.Errorf("section 4 too short: %d", len(sec))
+	}
+
+	m.ProductTemplate = uint16At(sec, 7)
+
+	// 模板 4.0 至 4.15 共享前 25 个字节
+	if m.ProductTemplate > 15 || len(sec) < 34 {
+		return nil
+	}
+
+	m.Category = sec[9]
+	m.Number = sec[10]
+
+	unit, err := timeUnit(sec[17])
+	if err != nil {
+		return err
+	}
+	m.ForecastTime = time.Duration(int32At(sec, 18)) * unit
+	m.FirstSurface = surface(sec[22:28])
+	m.SecondSurface = surface(sec[28:34])
+	return nil
+}
+
+// timeUnit 表 4.4 时间单位
+func timeUnit(code uint8) (time.Duration, error) {
+	switch code {
+	case 0:
+		return time.Minute, nil
+	case 1:
+		return time.Hour, nil
+	case 2:
+		return 24 * time.Hour, nil
+	case 10:
+		return 3 * time.Hour, nil
+	case 11:
+		return 6 * time.Hour, nil
+	case 12:
+		return 12 * time.Hour, nil
+	case 13:
+		return time.Second, nil
+	default:
+		return 0, fmt.Errorf("time unit %d: %w", code, ErrUnsupported)
+	}
+}
+
+// surface 解析 类型(1) 比例因子(1) 比例值(4) 表示的固定面
+func surface(b []byte) Surface {
+	s := Surface{Type: b[0], Value: math.NaN()}
+	if b[1] == 0xff && uint32At(b, 2) == 0xffffffff {
+		return s
+	}
+
+	s.Value = float64(int32At(b, 2)) / math.Pow10(int(intN(b[1:2])))
+	return s
+}
+
+func (m *Message) parseDataRepresentation(sec []byte) error {
+	if len(sec) < 11 {
+		return fmt.Errorf("section 5 too short: %d", len(sec))
+	}
+
+	m.NumValues = int(uint32At(sec, 5))
+	m.DataTemplate = uint16At(sec, 9)
+
+	switch m.DataTemplate {
+	case 0:
+		if len(sec) < 21 {
+			return fmt.Errorf("template 5.0 too short: %d", len(sec))
+		}
+	case 2:
+		if len(sec) < 47 {
+			return fmt.Errorf("template 5.2 too short: %d", len(sec))
+		}
+	case 3:
+		if len(sec) < 49 {
+			return fmt.Errorf("template 5.3 too short: %d", len(sec))
+		}
+	default:
+		return fmt.Errorf("data representation template 5.%d: %w", m.DataTemplate, ErrUnsupported)
+	}
+
+	m.drs = sec
+	return nil
+}
+
+// parseBitmap 解析第 6 段，prev 为同一消息中此前定义的位图
+func parseBitmap(sec []byte, prev []byte) ([]byte, error) {
+	if len(sec) < 6 {
+		return nil, fmt.Errorf("section 6 too short: %d", len(sec))
+	}
+
+	switch sec[5] {
+	case 0:
+		return sec[6:], nil
+	case 254:
+		if prev == nil {
+			return nil, errors.New("previously defined bitmap is missing")
+		}
+		return prev, nil
+	case 255:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("predefined bitmap %d: %w", sec[5], ErrUnsupported)
+	}
+}
@@ -0,0 +1,389 @@
+package grib2_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/gaussian"
+	"github.com/scorix/walg/pkg/geo/grids/interpolators"
+	"github.com/scorix/walg/pkg/grib/grib2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 以下辅助函数按 GRIB2 规范手工拼装消息
+
+func u16(v int) []byte { return binary.BigEndian.AppendUint16(nil, uint16(v)) }
+func u32(v int) []byte { return binary.BigEndian.AppendUint32(nil, uint32(v)) }
+
+// s32 符号-数值表示的 4 字节整数
+func s32(v int) []byte {
+	if v < 0 {
+		return u32(-v | 0x80000000)
+	}
+	return u32(v)
+}
+
+func section(num byte, parts ...[]byte) []byte {
+	body := bytes.Join(parts, nil)
+	return append(append(u32(len(body)+5), num), body...)
+}
+
+func message(sections ...[]byte) []byte {
+	body := bytes.Join(sections, nil)
+	total := 16 + len(body) + 4
+	msg := append([]byte("GRIB\x00\x00\x00\x02"), binary.BigEndian.AppendUint64(nil, uint64(total))...)
+	return append(append(msg, body...), "7777"...)
+}
+
+func identification(ref time.Time) []byte {
+	return section(1, u16(38), u16(0), []byte{2, 0, 1}, u16(ref.Year()),
+		[]byte{byte(ref.Month()), byte(ref.Day()), byte(ref.Hour()), byte(ref.Minute()), byte(ref.Second()), 0, 1})
+}
+
+// latLonSection 模板 3.0，角度单位为度
+func latLonSection(ni, nj int, la1, lo1, la2, lo2, di, dj float64, scan byte) []byte {
+	deg := func(v float64) []byte { return s32(int(math.Round(v * 1e6))) }
+	return section(3, []byte{0}, u32(ni*nj), []byte{0, 0}, u16(0),
+		[]byte{6, 0}, u32(0), []byte{0}, u32(0), []byte{0}, u32(0),
+		u32(ni), u32(nj), u32(0), u32(0xffffffff),
+		deg(la1), deg(lo1), []byte{0x30}, deg(la2), deg(lo2), deg(di), deg(dj), []byte{scan})
+}
+
+// gaussianSection 模板 3.40 全球规则高斯网格
+func gaussianSection(n int, g grids.Grid) []byte {
+	lats, lons := g.Latitudes(), g.Longitudes()
+	deg := func(v float64) []byte { return s32(int(math.Round(v * 1e6))) }
+	return section(3, []byte{0}, u32(8*n*n), []byte{0, 0}, u16(40),
+		[]byte{6, 0}, u32(0), []byte{0}, u32(0), []byte{0}, u32(0),
+		u32(4*n), u32(2*n), u32(0), u32(0xffffffff),
+		deg(lats[0]), deg(lons[0]), []byte{0x30}, deg(lats[len(lats)-1]), deg(lons[len(lons)-1]),
+		deg(lons[1]-lons[0]), u32(n), []byte{0})
+}
+
+// product 模板 4.0，预报时效单位为小时，第一固定面为 2 米高度
+func product(category, number byte, hours int) []byte {
+	return section(4, u16(0), u16(0), []byte{category, number, 2, 0, 0}, u16(0), []byte{0, 1},
+		s32(hours), []byte{103, 0}, u32(2), []byte{255, 255}, u32(0xffffffff))
+}
+
+func float32Bytes(v float32) []byte { return u32(int(math.Float32bits(v))) }
+
+func simplePacking(n int, ref float32, e, d, bits int) []byte {
+	return section(5, u32(n), u16(0), float32Bytes(ref), s16(e), s16(d), []byte{byte(bits), 0})
+}
+
+func s16(v int) []byte {
+	if v < 0 {
+		return u16(-v | 0x8000)
+	}
+	return u16(v)
+}
+
+func noBitmap() []byte { return section(6, []byte{255}) }
+
+func data(b ...byte) []byte { return section(7, b) }
+
+var ref = time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+func TestDecode_SimplePacking(t *testing.T) {
+	// 2x3 网格，北到南、西到东扫描，X = 0..5，Y = (100 + X) / 10
+	msg := message(
+		identification(ref),
+		latLonSection(3, 2, 11, 100, 10, 102, 1, 1, 0),
+		product(0, 0, 6),
+		simplePacking(6, 100, 0, 1, 4),
+		noBitmap(),
+		data(0x01, 0x23, 0x45),
+	)
+
+	msgs, err := grib2.Decode(msg)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+
+	m := msgs[0]
+	assert.Equal(t, uint16(38), m.Centre)
+	assert.Equal(t, ref, m.ReferenceTime)
+	assert.Equal(t, 6*time.Hour, m.ForecastTime)
+	assert.Equal(t, ref.Add(6*time.Hour), m.ValidTime())
+	assert.Equal(t, grib2.Surface{Type: 103, Value: 2}, m.FirstSurface)
+	assert.Equal(t, []float64{11, 10}, m.Grid.Latitudes())
+	assert.Equal(t, []float64{100, 101, 102}, m.Grid.Longitudes())
+	assert.Equal(t, grids.ScanMode(0), m.ScanMode)
+
+	values, err := m.Values()
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{10, 10.1, 10.2, 10.3, 10.4, 10.5}, values, 1e-5)
+
+	r, err := grib2.NewReader(msgs)
+	require.NoError(t, err)
+	v, err := r.ReadValueAt(0, grids.GridIndex(r.Grid(), 10, 101, r.ScanMode()))
+	require.NoError(t, err)
+	assert.InDelta(t, 10.4, v, 1e-5)
+
+	gi := grids.NewGridInterpolator(r, r.Grid(), r.ScanMode(), &interpolators.BilinearInterpolator{})
+	v, err = gi.InterpolateAt(0, 10.5, 100.5)
+	require.NoError(t, err)
+	assert.InDelta(t, 10.2, v, 1e-5)
+}
+
+func TestDecode_ScanModeAndBitmap(t *testing.T) {
+	// 南到北扫描（GRIB 标志 0x40），位图 101101
+	msg := message(
+		identification(ref),
+		latLonSection(3, 2, 10, 100, 11, 102, 1, 1, 0x40),
+		product(0, 0, 0),
+		simplePacking(4, 0, 0, 0, 8),
+		section(6, []byte{0}, []byte{0xb4}),
+		data(1, 2, 3, 4),
+	)
+
+	msgs, err := grib2.Decode(msg)
+	require.NoError(t, err)
+	m := msgs[0]
+	assert.Equal(t, grids.ScanModePositiveJ, m.ScanMode)
+	assert.Equal(t, uint8(0x40), grib2.ScanModeFlags(m.ScanMode))
+
+	values, err := m.Values()
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, values[0])
+	assert.True(t, math.IsNaN(values[1]))
+	assert.Equal(t, []float64{2, 3}, values[2:4])
+	assert.True(t, math.IsNaN(values[4]))
+	assert.Equal(t, 4.0, values[5])
+
+	// 第一个点是西南角
+	r, err := grib2.NewReader(msgs)
+	require.NoError(t, err)
+	v, err := r.ReadValueAt(0, grids.GridIndex(r.Grid(), 10, 100, r.ScanMode()))
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, v)
+}
+
+func TestDecode_ComplexPacking(t *testing.T) {
+	// 两组：[5 5 5] 位宽 0；[7 8 缺测 6] 参考值 6、位宽 2
+	drs := section(5, u32(7), u16(2), float32Bytes(0), s16(0), s16(0), []byte{4, 0},
+		[]byte{1, 1}, u32(0), u32(0), u32(2), []byte{0, 2}, u32(3), []byte{1}, u32(4), []byte{1})
+
+	msg := message(
+		identification(ref),
+		latLonSection(7, 1, 10, 100, 10, 106, 1, 1, 0),
+		product(0, 0, 0),
+		drs,
+		noBitmap(),
+		data(0x56, 0x20, 0x40, 0x6c),
+	)
+
+	msgs, err := grib2.Decode(msg)
+	require.NoError(t, err)
+
+	values, err := msgs[0].Values()
+	require.NoError(t, err)
+	assert.Equal(t, []float64{5, 5, 5, 7, 8}, values[:5])
+	assert.True(t, math.IsNaN(values[5]))
+	assert.Equal(t, 6.0, values[6])
+}
+
+func TestDecode_CorruptLengths(t *testing.T) {
+	// 第 0 段的消息长度超出上限
+	msg := message(identification(ref))
+	binary.BigEndian.PutUint64(msg[8:], 1<<40)
+	_, err := grib2.Decode(msg)
+	assert.ErrorIs(t, err, grib2.ErrNotGRIB2)
+
+	// 位宽为 0 的组长度远超值个数
+	drs := section(5, u32(7), u16(2), float32Bytes(0), s16(0), s16(0), []byte{4, 0},
+		[]byte{1, 1}, u32(0), u32(0), u32(2), []byte{0, 2}, u32(1<<30), []byte{1}, u32(4), []byte{1})
+	msgs, err := grib2.Decode(message(
+		identification(ref),
+		latLonSection(7, 1, 10, 100, 10, 106, 1, 1, 0),
+		product(0, 0, 0),
+		drs,
+		noBitmap(),
+		data(0x56, 0x20, 0x40, 0x6c),
+	))
+	require.NoError(t, err)
+	_, err = msgs[0].Values()
+	assert.Error(t, err)
+
+	// 位宽超过 64
+	drs = section(5, u32(7), u16(2), float32Bytes(0), s16(0), s16(0), []byte{4, 0},
+		[]byte{1, 1}, u32(0), u32(0), u32(2), []byte{100, 2}, u32(3), []byte{1}, u32(4), []byte{1})
+	msgs, err = grib2.Decode(message(
+		identification(ref),
+		latLonSection(7, 1, 10, 100, 10, 106, 1, 1, 0),
+		product(0, 0, 0),
+		drs,
+		noBitmap(),
+		data(0x56, 0x20, 0x40, 0x6c),
+	))
+	require.NoError(t, err)
+	_, err = msgs[0].Values()
+	assert.Error(t, err)
+}
+
+func TestDecode_CorruptValueCount(t *testing.T) {
+	// 第 5 段声明的值个数超过网格点数
+	msgs, err := grib2.Decode(message(
+		identification(ref),
+		latLonSection(3, 2, 11, 100, 10, 102, 1, 1, 0),
+		product(0, 0, 0),
+		simplePacking(1<<30, 0, 0, 0, 0),
+		noBitmap(),
+		data(),
+	))
+	require.NoError(t, err)
+	_, err = msgs[0].Values()
+	assert.Error(t, err)
+
+	// 数据段不足以容纳所有值
+	msgs, err = grib2.Decode(message(
+		identification(ref),
+		latLonSection(3, 2, 11, 100, 10, 102, 1, 1, 0),
+		product(0, 0, 0),
+		simplePacking(6, 0, 0, 0, 16),
+		noBitmap(),
+		data(1, 2),
+	))
+	require.NoError(t, err)
+	_, err = msgs[0].Values()
+	assert.Error(t, err)
+}
+
+func TestDecode_SpatialDifferencing(t *testing.T) {
+	// 原始整数 10 12 15 19 25，二阶差分 1 1 2，最小值 1
+	drs := section(5, u32(5), u16(3), float32Bytes(0), s16(0), s16(1), []byte{1, 0},
+		[]byte{1, 0}, u32(0), u32(0), u32(1), []byte{1, 0}, u32(0), []byte{1}, u32(5), []byte{0}, []byte{2, 2})
+
+	msg := message(
+		identification(ref),
+		latLonSection(5, 1, 10, 100, 10, 104, 1, 1, 0),
+		product(0, 0, 0),
+		drs,
+		noBitmap(),
+		data(0x00, 0x0a, 0x00, 0x0c, 0x00, 0x01, 0x00, 0x08),
+	)
+
+	msgs, err := grib2.Decode(msg)
+	require.NoError(t, err)
+
+	values, err := msgs[0].Values()
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{1.0, 1.2, 1.5, 1.9, 2.5}, values, 1e-9)
+}
+
+func TestDecode_Gaussian(t *testing.T) {
+	g := gaussian.NewRegular(2)
+	msg := message(
+		identification(ref),
+		gaussianSection(2, g),
+		product(0, 0, 0),
+		simplePacking(32, 273.15, 0, 0, 0),
+		noBitmap(),
+		data(),
+	)
+
+	msgs, err := grib2.Decode(msg)
+	require.NoError(t, err)
+	assert.Same(t, g, msgs[0].Grid)
+
+	values, err := msgs[0].Values()
+	require.NoError(t, err)
+	require.Len(t, values, 32)
+	assert.InDelta(t, 273.15, values[17], 1e-4)
+}
+
+func TestDecode_MultipleFieldsAndMessages(t *testing.T) {
+	// 一条消息包含两个场，第二条消息前有无关字节
+	first := message(
+		identification(ref),
+		latLonSection(2, 2, 11, 100, 10, 101, 1, 1, 0),
+		product(0, 0, 0),
+		simplePacking(4, 0, 0, 0, 8),
+		noBitmap(),
+		data(1, 2, 1, 2),
+		product(0, 0, 3),
+		simplePacking(4, 0, 0, 0, 8),
+		noBitmap(),
+		data(3, 4, 3, 4),
+	)
+	second := message(
+		identification(ref),
+		latLonSection(2, 2, 11, 100, 10, 101, 1, 1, 0),
+		product(0, 0, 6),
+		simplePacking(4, 0, 0, 0, 8),
+		noBitmap(),
+		data(5, 6, 5, 6),
+	)
+
+	msgs, err := grib2.Decode(append(append(first, "junk"...), second...))
+	require.NoError(t, err)
+	require.Len(t, msgs, 3)
+
+	r, err := grib2.NewReader(msgs)
+	require.NoError(t, err)
+
+	axis := r.TimeAxis()
+	require.Equal(t, 3, axis.Len())
+	assert.Equal(t, ref.Add(6*time.Hour), axis.Time(2))
+
+	dst := make([]float64, 4)
+	for step, want := range [][]float64{{1, 2, 1, 2}, {3, 4, 3, 4}, {5, 6, 5, 6}} {
+		require.NoError(t, r.ReadField(step, dst))
+		assert.Equal(t, want, dst)
+	}
+
+	// 按时间插值
+	gi := grids.NewGridInterpolator(r, r.Grid(), r.ScanMode(), nil)
+	v, err := grids.NewTemporalInterpolator(gi, nil, grids.TemporalLinear).InterpolateAtTime(ref.Add(time.Hour), 10, 100)
+	require.NoError(t, err)
+	assert.InDelta(t, 1+2.0/3, v, 1e-9)
+}
+
+func TestDecode_Errors(t *testing.T) {
+	_, err := grib2.Decode([]byte("GRIB\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x20"))
+	assert.ErrorIs(t, err, grib2.ErrNotGRIB2)
+
+	msg := message(
+		identification(ref),
+		section(3, []byte{0}, u32(4), []byte{0, 0}, u16(30), make([]byte, 60)),
+		product(0, 0, 0),
+		simplePacking(4, 0, 0, 0, 8),
+		noBitmap(),
+		data(1, 2, 3, 4),
+	)
+	_, err = grib2.Decode(msg)
+	assert.ErrorIs(t, err, grib2.ErrUnsupported)
+
+	// 只有一行且没有给出纬向格距时无法确定网格
+	msg = message(
+		identification(ref),
+		latLonSection(3, 1, 10, 100, 10, 102, 1, 0, 0),
+		product(0, 0, 0),
+		simplePacking(3, 0, 0, 0, 8),
+		noBitmap(),
+		data(1, 2, 3),
+	)
+	_, err = grib2.Decode(msg)
+	assert.ErrorIs(t, err, grib2.ErrUnsupported)
+
+	// 格距与首末点推算的点数不一致
+	msg = message(
+		identification(ref),
+		latLonSection(3, 2, 11, 100, 10, 102, 0.5, 1, 0),
+		product(0, 0, 0),
+		simplePacking(6, 0, 0, 0, 8),
+		noBitmap(),
+		data(1, 2, 3, 4, 5, 6),
+	)
+	_, err = grib2.Decode(msg)
+	assert.Error(t, err)
+
+	msgs, err := grib2.Decode(nil)
+	require.NoError(t, err)
+	assert.Empty(t, msgs)
+}
@@ -0,0 +1,155 @@
+package grib2
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/gaussian"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+)
+
+// ScanModeFromFlags 将 GRIB 代码表 3.4 的扫描方式标志转换为 ScanMode
+// GRIB 中第 1 位为最高位，walg 的 ScanMode 中第 1 位为最低位
+func ScanModeFromFlags(flags uint8) grids.ScanMode {
+	return grids.ScanMode(bits.Reverse8(flags))
+}
+
+// ScanModeFlags 将 ScanMode 转换为 GRIB 代码表 3.4 的扫描方式标志
+func ScanModeFlags(mode grids.ScanMode) uint8 {
+	return bits.Reverse8(uint8(mode))
+}
+
+// gridDefinition 模板 3.0 和 3.40 的公共部分
+type gridDefinition struct {
+	ni, nj       int
+	la1, lo1     float64
+	la2, lo2     float64
+	di, dj       float64 // 模板 3.40 中 dj 无意义
+	n            int     // 模板 3.40 中极点到赤道之间的纬圈数
+	scanningMode uint8
+}
+
+func (m *Message) parseGrid(sec []byte) error {
+	if len(sec) < 14 {
+		return fmt.Errorf("section 3 too short: %d", len(sec))
+	}
+
+	m.NumPoints = int(uint32At(sec, 6))
+	m.GridTemplate = uint16At(sec, 12)
+
+	if sec[5] != 0 {
+		return fmt.Errorf("grid definition source %d: %w", sec[5], ErrUnsupported)
+	}
+	if sec[10] != 0 {
+		return fmt.Errorf("quasi-regular grid: %w", ErrUnsupported)
+	}
+
+	switch m.GridTemplate {
+	case 0, 40:
+	default:
+		return fmt.Errorf("grid definition template 3.%d: %w", m.GridTemplate, ErrUnsupported)
+	}
+
+	if len(sec) < 72 {
+		return fmt.Errorf("template 3.%d too short: %d", m.GridTemplate, len(sec))
+	}
+
+	// 角度单位：基本角度为 0 或缺测时为 1e-6 度
+	unit := 1e-6
+	if basic, sub := uint32At(sec, 38), uint32At(sec, 42); basic != 0 && basic != 0xffffffff && sub != 0 && sub != 0xffffffff {
+		unit = float64(basic) / float64(sub)
+	}
+
+	def := gridDefinition{
+		ni:           int(uint32At(sec, 30)),
+		nj:           int(uint32At(sec, 34)),
+		la1:          float64(int32At(sec, 46)) * unit,
+		lo1:          float64(int32At(sec, 50)) * unit,
+		la2:          float64(int32At(sec, 55)) * unit,
+		lo2:          float64(int32At(sec, 59)) * unit,
+		di:           increment(uint32At(sec, 63), unit),
+		scanningMode: sec[71],
+	}
+	if m.GridTemplate == 40 {
+		def.n = int(uint32At(sec, 67))
+	} else {
+		def.dj = increment(uint32At(sec, 67), unit)
+	}
+
+	if def.ni*def.nj != m.NumPoints {
+		return fmt.Errorf("grid %dx%d does not match %d points", def.ni, def.nj, m.NumPoints)
+	}
+
+	m.ScanMode = ScanModeFromFlags(def.scanningMode)
+	if m.ScanMode&(grids.ScanModeOddOffset|grids.ScanModeEvenOffset|grids.ScanModeJOffset|grids.ScanModeOffsetPoints) != 0 {
+		return fmt.Errorf("scanning mode %08b: %w", def.scanningMode, ErrUnsupported)
+	}
+
+	var err error
+	if m.GridTemplate == 40 {
+		m.Grid, err = gaussianGrid(def)
+	} else {
+		m.Grid, err = latLonGrid(def)
+	}
+	if err != nil {
+		return err
+	}
+
+	if m.Grid.Size() != m.NumPoints {
+		return fmt.Errorf("grid of %d points does not match %d points", m.Grid.Size(), m.NumPoints)
+	}
+	return nil
+}
+
+// increment 换算格距，缺测时返回 0，由首末点推算
+func increment(raw uint32, unit float64) float64 {
+	if raw == 0xffffffff {
+		return 0
+	}
+	return float64(raw) * unit
+}
+
+// latLonGrid 根据模板 3.0 创建经纬度网格
+func latLonGrid(def gridDefinition) (grids.Grid, error) {
+	if def.ni < 1 || def.nj < 1 {
+		return nil, fmt.Errorf("invalid grid size %dx%d", def.ni, def.nj)
+	}
+
+	// 西边界为 -i 扫描时的终点经度
+	west, east := def.lo1, def.lo2
+	if ScanModeFromFlags(def.scanningMode).IsNegativeI() {
+		west, east = east, west
+	}
+	if east < west {
+		east += 360
+	}
+	// 跨越本初子午线的区域使用负经度表示西经
+	if east > 360 {
+		west, east = west-360, east-360
+	}
+
+	di, dj := def.di, def.dj
+	if def.ni > 1 && di <= 0 {
+		di = (east - west) / float64(def.ni-1)
+	}
+	if def.nj > 1 && dj <= 0 {
+		dj = math.Abs(def.la2-def.la1) / float64(def.nj-1)
+	}
+	// 只有一行或一列且没有给出格距，或者首末点重合时，无法确定格距
+	if !(di > 0) || !(dj > 0) {
+		return nil, fmt.Errorf("grid %dx%d with increments %g, %g: %w", def.ni, def.nj, di, dj, ErrUnsupported)
+	}
+
+	return latlon.NewLatLonGrid(math.Min(def.la1, def.la2), math.Max(def.la1, def.la2), west, east, dj, di), nil
+}
+
+// gaussianGrid 根据模板 3.40 创建高斯网格，目前只支持全球规则高斯网格
+func gaussianGrid(def gridDefinition) (grids.Grid, error) {
+	if def.n < 1 || def.ni != 4*def.n || def.nj != 2*def.n {
+		return nil, fmt.Errorf("gaussian grid %dx%d with N=%d: %w", def.ni, def.nj, def.n, ErrUnsupported)
+	}
+
+	return gaussian.NewRegular(def.n), nil
+}
@@ -0,0 +1,29 @@
+package grib2
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/scorix/walg/pkg/grib"
+)
+
+// NewReader 将一组场作为 ValueReader 使用，第 i 个时间步对应第 i 个场
+// 所有场必须位于相同的网格和扫描方式上
+func NewReader(msgs []*Message) (*grib.Reader, error) {
+	if len(msgs) == 0 {
+		return nil, errors.New("no messages")
+	}
+
+	times := make([]time.Time, len(msgs))
+	for i, m := range msgs {
+		if m.Grid != msgs[0].Grid || m.ScanMode != msgs[0].ScanMode {
+			return nil, fmt.Errorf("message %d is on a different grid", i)
+		}
+		times[i] = m.ValidTime()
+	}
+
+	return grib.NewReader(msgs[0].Grid, msgs[0].ScanMode, times, func(step int) ([]float64, error) {
+		return msgs[step].Values()
+	}), nil
+}
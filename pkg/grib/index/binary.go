@@ -0,0 +1,127 @@
+package index
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// binaryMagic 二进制索引的文件标识
+const binaryMagic = "WALGGIDX"
+
+// binaryVersion 二进制索引的格式版本
+const binaryVersion = 1
+
+// ErrInvalidIndex 二进制索引格式错误
+var ErrInvalidIndex = errors.New("invalid binary index")
+
+// record 二进制索引中一条记录的定长部分，之后是网格描述
+type record struct {
+	Message       uint32
+	Field         uint16
+	Edition       uint8
+	Discipline    uint8
+	Offset        int64
+	Length        int64
+	ReferenceTime int64 // Unix 秒
+	ForecastTime  int64 // 纳秒
+	Category      uint8
+	Parameter     uint8
+	LevelType     uint8
+	_             uint8
+	Level         uint64 // float64 的位
+	GridLength    uint16
+}
+
+// WriteBinary 以紧凑的二进制格式写入索引，所有字段按大端序保存
+func (ix *Index) WriteBinary(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	bw.WriteString(binaryMagic)
+	binary.Write(bw, binary.BigEndian, uint16(binaryVersion))
+	binary.Write(bw, binary.BigEndian, uint32(len(ix.Entries)))
+
+	for _, e := range ix.Entries {
+		if len(e.Grid) > math.MaxUint16 {
+			return fmt.Errorf("grid description of message %d is too long", e.Message)
+		}
+
+		rec := record{
+			Message:       uint32(e.Message),
+			Field:         uint16(e.Field),
+			Edition:       e.Edition,
+			Discipline:    e.Discipline,
+			Offset:        e.Offset,
+			Length:        e.Length,
+			ReferenceTime: e.ReferenceTime.Unix(),
+			ForecastTime:  int64(e.ForecastTime),
+			Category:      e.Category,
+			Parameter:     e.Parameter,
+			LevelType:     e.LevelType,
+			Level:         math.Float64bits(e.Level),
+			GridLength:    uint16(len(e.Grid)),
+		}
+		if err := binary.Write(bw, binary.BigEndian, &rec); err != nil {
+			return err
+		}
+		bw.WriteString(e.Grid)
+	}
+
+	return bw.Flush()
+}
+
+// ReadBinary 读取 WriteBinary 写入的索引
+func ReadBinary(r io.Reader) (*Index, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, len(binaryMagic)+6)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("%w: read header: %w", ErrInvalidIndex, err)
+	}
+	if string(header[:len(binaryMagic)]) != binaryMagic {
+		return nil, fmt.Errorf("%w: bad magic", ErrInvalidIndex)
+	}
+	if v := binary.BigEndian.Uint16(header[len(binaryMagic):]); v != binaryVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidIndex, v)
+	}
+	count := binary.BigEndian.Uint32(header[len(binaryMagic)+2:])
+
+	ix := &Index{Entries: make([]Entry, 0, min(count, 1<<16))}
+	for i := range count {
+		var rec record
+		if err := binary.Read(br, binary.BigEndian, &rec); err != nil {
+			return nil, fmt.Errorf("%w: read entry %d: %w", ErrInvalidIndex, i, err)
+		}
+
+		if rec.Offset < 0 || rec.Length < 0 || rec.Length > maxMessageLength {
+			return nil, fmt.Errorf("%w: entry %d has offset %d and length %d", ErrInvalidIndex, i, rec.Offset, rec.Length)
+		}
+
+		grid := make([]byte, rec.GridLength)
+		if _, err := io.ReadFull(br, grid); err != nil {
+			return nil, fmt.Errorf("%w: read entry %d: %w", ErrInvalidIndex, i, err)
+		}
+
+		ix.Entries = append(ix.Entries, Entry{
+			Message:       int(rec.Message),
+			Field:         int(rec.Field),
+			Offset:        rec.Offset,
+			Length:        rec.Length,
+			Edition:       rec.Edition,
+			ReferenceTime: time.Unix(rec.ReferenceTime, 0).UTC(),
+			ForecastTime:  time.Duration(rec.ForecastTime),
+			Discipline:    rec.Discipline,
+			Category:      rec.Category,
+			Parameter:     rec.Parameter,
+			LevelType:     rec.LevelType,
+			Level:         math.Float64frombits(rec.Level),
+			Grid:          string(grid),
+		})
+	}
+
+	return ix, nil
+}
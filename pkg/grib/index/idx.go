@@ -0,0 +1,244 @@
+package index
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// grib2Names 常用 GRIB2 参数的缩写，键为 学科、类别、编号
+var grib2Names = map[[3]uint8]string{
+	{0, 0, 0}:  "TMP",
+	{0, 0, 6}:  "DPT",
+	{0, 1, 0}:  "SPFH",
+	{0, 1, 1}:  "RH",
+	{0, 1, 8}:  "APCP",
+	{0, 2, 2}:  "UGRD",
+	{0, 2, 3}:  "VGRD",
+	{0, 2, 22}: "GUST",
+	{0, 3, 0}:  "PRES",
+	{0, 3, 1}:  "PRMSL",
+	{0, 3, 5}:  "HGT",
+	{0, 6, 1}:  "TCDC",
+}
+
+// grib1Names WMO 标准参数表（表 2，版本 1 至 3）中常用参数的缩写
+var grib1Names = map[uint8]string{
+	1:  "PRES",
+	2:  "PRMSL",
+	7:  "HGT",
+	11: "TMP",
+	17: "DPT",
+	33: "UGRD",
+	34: "VGRD",
+	52: "RH",
+	61: "APCP",
+	71: "TCDC",
+}
+
+// Name 返回参数缩写，未知参数返回 var<学科>_<类别>_<编号> 或 var<参数表>_<参数>
+func (e Entry) Name() string {
+	if e.Edition == 1 {
+		if name, ok := grib1Names[e.Parameter]; ok && e.Category <= 3 {
+			return name
+		}
+		return fmt.Sprintf("var%d_%d", e.Category, e.Parameter)
+	}
+
+	if name, ok := grib2Names[[3]uint8{e.Discipline, e.Category, e.Parameter}]; ok {
+		return name
+	}
+	return fmt.Sprintf("var%d_%d_%d", e.Discipline, e.Category, e.Parameter)
+}
+
+// LevelName 返回层次描述，例如 "850 mb"、"2 m above ground"
+func (e Entry) LevelName() string {
+	surface, isobaric, msl, height := uint8(1), uint8(100), uint8(101), uint8(103)
+	pa := e.Level
+	if e.Edition == 1 {
+		msl, height = 102, 105
+		pa = e.Level * 100
+	}
+
+	switch e.LevelType {
+	case surface:
+		return "surface"
+	case isobaric:
+		return strconv.FormatFloat(pa/100, 'g', -1, 64) + " mb"
+	case msl:
+		return "mean sea level"
+	case height:
+		return strconv.FormatFloat(e.Level, 'g', -1, 64) + " m above ground"
+	default:
+		return fmt.Sprintf("level %d %s", e.LevelType, strconv.FormatFloat(e.Level, 'g', -1, 64))
+	}
+}
+
+// StepName 返回预报时效描述，例如 "anl"、"6 hour fcst"
+func (e Entry) StepName() string {
+	switch {
+	case e.ForecastTime == 0:
+		return "anl"
+	case e.ForecastTime%time.Hour == 0:
+		return fmt.Sprintf("%d hour fcst", e.ForecastTime/time.Hour)
+	default:
+		return fmt.Sprintf("%d min fcst", e.ForecastTime/time.Minute)
+	}
+}
+
+// String 返回 .idx 格式的一行，不含换行符
+func (e Entry) String() string {
+	return e.line(e.Field > 1)
+}
+
+func (e Entry) line(multi bool) string {
+	num := strconv.Itoa(e.Message)
+	if multi {
+		num += "." + strconv.Itoa(e.Field)
+	}
+
+	return fmt.Sprintf("%s:%d:d=%s:%s:%s:%s:", num, e.Offset,
+		e.ReferenceTime.UTC().Format("2006010215"), e.Name(), e.LevelName(), e.StepName())
+}
+
+// WriteIdx 以 NOAA .idx 文本格式写入索引，包含多个场的消息使用 "消息.场" 编号
+func (ix *Index) WriteIdx(w io.Writer) error {
+	fields := map[int]int{}
+	for _, e := range ix.Entries {
+		fields[e.Message]++
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, e := range ix.Entries {
+		if _, err := fmt.Fprintln(bw, e.line(fields[e.Message] > 1)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+var stepPattern = regexp.MustCompile(`^(?:(\d+)-)?(\d+) (hour|min|day) (?:[a-z]+ )?fcst$`)
+
+// ParseIdx 解析 NOAA .idx 文本格式的索引
+// 消息长度由下一条消息的位置推算，最后一条消息的长度为 0，读取时从消息头获取；
+// 参数、层次按 GRIB2 的编码识别常用的缩写和描述，无法识别时相应的编码为 0；
+// 文本中没有版本信息，Edition 为 0，读取时从消息头获取
+func ParseIdx(r io.Reader) (*Index, error) {
+	ix := &Index{}
+
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+
+		e, err := parseLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		ix.Entries = append(ix.Entries, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	// 长度为到下一条不同位置的距离
+	for i := range ix.Entries {
+		for j := i + 1; j < len(ix.Entries); j++ {
+			if ix.Entries[j].Offset != ix.Entries[i].Offset {
+				ix.Entries[i].Length = ix.Entries[j].Offset - ix.Entries[i].Offset
+				break
+			}
+		}
+	}
+
+	return ix, nil
+}
+
+func parseLine(text string) (Entry, error) {
+	parts := strings.Split(text, ":")
+	if len(parts) < 6 {
+		return Entry{}, fmt.Errorf("malformed index line %q", text)
+	}
+
+	e := Entry{Field: 1}
+
+	num, field, _ := strings.Cut(parts[0], ".")
+	var err error
+	if e.Message, err = strconv.Atoi(num); err != nil {
+		return Entry{}, fmt.Errorf("message number %q: %w", parts[0], err)
+	}
+	if field != "" {
+		if e.Field, err = strconv.Atoi(field); err != nil {
+			return Entry{}, fmt.Errorf("field number %q: %w", parts[0], err)
+		}
+	}
+
+	if e.Offset, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+		return Entry{}, fmt.Errorf("offset %q: %w", parts[1], err)
+	}
+
+	date, ok := strings.CutPrefix(parts[2], "d=")
+	if !ok {
+		return Entry{}, fmt.Errorf("reference time %q", parts[2])
+	}
+	if e.ReferenceTime, err = time.Parse("2006010215", date); err != nil {
+		return Entry{}, fmt.Errorf("reference time %q: %w", parts[2], err)
+	}
+
+	for key, name := range grib2Names {
+		if name == parts[3] {
+			e.Discipline, e.Category, e.Parameter = key[0], key[1], key[2]
+		}
+	}
+	if n, _ := fmt.Sscanf(parts[3], "var%d_%d_%d", &e.Discipline, &e.Category, &e.Parameter); n > 0 && n < 3 {
+		e.Discipline, e.Category, e.Parameter = 0, 0, 0
+	}
+
+	e.LevelType, e.Level = parseLevel(parts[4])
+
+	if parts[5] != "anl" {
+		m := stepPattern.FindStringSubmatch(parts[5])
+		if m == nil {
+			return Entry{}, fmt.Errorf("forecast step %q", parts[5])
+		}
+
+		v, _ := strconv.Atoi(m[2])
+		unit := map[string]time.Duration{"hour": time.Hour, "min": time.Minute, "day": 24 * time.Hour}[m[3]]
+		e.ForecastTime = time.Duration(v) * unit
+	}
+
+	return e, nil
+}
+
+// parseLevel 识别常用的层次描述，返回 GRIB2 的固定面类型和值
+func parseLevel(s string) (uint8, float64) {
+	switch {
+	case s == "surface":
+		return 1, 0
+	case s == "mean sea level":
+		return 101, 0
+	case strings.HasSuffix(s, " mb"):
+		v, err := strconv.ParseFloat(strings.TrimSuffix(s, " mb"), 64)
+		if err == nil {
+			return 100, v * 100
+		}
+	case strings.HasSuffix(s, " m above ground"):
+		v, err := strconv.ParseFloat(strings.TrimSuffix(s, " m above ground"), 64)
+		if err == nil {
+			return 103, v
+		}
+	}
+
+	var t uint8
+	var v float64
+	if n, _ := fmt.Sscanf(s, "level %d %g", &t, &v); n == 2 {
+		return t, v
+	}
+	return 0, 0
+}
@@ -0,0 +1,198 @@
+// Package index 为 GRIB 文件建立消息索引，并按字节范围读取需要的消息
+// 索引可以保存为 NOAA 风格的 .idx 文本或紧凑的二进制格式
+package index
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/grib/grib1"
+	"github.com/scorix/walg/pkg/grib/grib2"
+)
+
+// Entry 一个场的索引记录
+// GRIB1 没有学科和参数类别，Category 记录参数表版本
+type Entry struct {
+	Message int   // 消息序号，从 1 开始
+	Field   int   // 消息内场的序号，从 1 开始，GRIB2 一条消息可以包含多个场
+	Offset  int64 // 消息在文件中的起始位置
+	Length  int64 // 消息长度，为 0 时读取时从消息头获取
+	Edition uint8
+
+	ReferenceTime time.Time
+	ForecastTime  time.Duration
+
+	Discipline uint8
+	Category   uint8
+	Parameter  uint8
+	LevelType  uint8
+	Level      float64
+
+	Grid string // 网格描述，例如 "3.0 360x181"
+}
+
+// Index 一个文件中所有场的索引
+type Index struct {
+	Entries []Entry
+}
+
+// Filter 返回满足条件的记录
+func (ix *Index) Filter(f func(e Entry) bool) []Entry {
+	var out []Entry
+	for _, e := range ix.Entries {
+		if f(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Match 返回 .idx 文本行与正则表达式匹配的记录，用法与 wgrib2 -match 相同，例如 ":TMP:2 m above ground:"
+func (ix *Index) Match(re *regexp.Regexp) []Entry {
+	return ix.Filter(func(e Entry) bool { return re.MatchString(e.String()) })
+}
+
+// Build 扫描 r 中的所有消息建立索引，只解析各段的元数据，不解码数据
+// 消息之间的非 GRIB 字节被跳过
+func Build(r io.ReaderAt, size int64) (*Index, error) {
+	ix := &Index{}
+
+	off := int64(0)
+	for msg := 1; ; msg++ {
+		start, err := findMessage(r, off, size)
+		if errors.Is(err, io.EOF) {
+			return ix, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		edition, length, err := messageHeader(r, start)
+		if err != nil {
+			return nil, err
+		}
+
+		if length < 16 || length > size-start {
+			return nil, fmt.Errorf("message %d at %d: invalid length %d", msg, start, length)
+		}
+
+		data := make([]byte, length)
+		if _, err := r.ReadAt(data, start); err != nil {
+			return nil, fmt.Errorf("read message %d at %d: %w", msg, start, err)
+		}
+
+		entries, err := describe(data, edition)
+		if err != nil {
+			return nil, fmt.Errorf("message %d at %d: %w", msg, start, err)
+		}
+		for i := range entries {
+			entries[i].Message = msg
+			entries[i].Field = i + 1
+			entries[i].Offset = start
+			entries[i].Length = length
+		}
+		ix.Entries = append(ix.Entries, entries...)
+
+		off = start + length
+	}
+}
+
+// findMessage 从 off 开始查找下一个 "GRIB" 标识
+func findMessage(r io.ReaderAt, off, size int64) (int64, error) {
+	const chunk = 4096
+	buf := make([]byte, chunk+3)
+
+	for off < size {
+		n, err := r.ReadAt(buf[:min(int64(len(buf)), size-off)], off)
+		if n < 4 {
+			if err == nil || errors.Is(err, io.EOF) {
+				return 0, io.EOF
+			}
+			return 0, err
+		}
+
+		if i := bytes.Index(buf[:n], []byte("GRIB")); i >= 0 {
+			return off + int64(i), nil
+		}
+		off += int64(n - 3)
+	}
+
+	return 0, io.EOF
+}
+
+// messageHeader 读取 off 处消息的版本和长度
+func messageHeader(r io.ReaderAt, off int64) (uint8, int64, error) {
+	header := make([]byte, 16)
+	if _, err := r.ReadAt(header[:8], off); err != nil {
+		return 0, 0, fmt.Errorf("read header at %d: %w", off, err)
+	}
+	if !bytes.Equal(header[:4], []byte("GRIB")) {
+		return 0, 0, fmt.Errorf("no GRIB message at %d", off)
+	}
+
+	switch header[7] {
+	case 1:
+		return 1, int64(header[4])<<16 | int64(header[5])<<8 | int64(header[6]), nil
+	case 2:
+		if _, err := r.ReadAt(header[8:], off+8); err != nil {
+			return 0, 0, fmt.Errorf("read header at %d: %w", off, err)
+		}
+		var length int64
+		for _, b := range header[8:16] {
+			length = length<<8 | int64(b)
+		}
+		return 2, length, nil
+	default:
+		return 0, 0, fmt.Errorf("unknown GRIB edition %d at %d", header[7], off)
+	}
+}
+
+// describe 解析消息的元数据，生成各个场的索引记录
+func describe(data []byte, edition uint8) ([]Entry, error) {
+	if edition == 1 {
+		m, err := grib1.ReadMessage(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return []Entry{{
+			Edition:       1,
+			ReferenceTime: m.ReferenceTime,
+			ForecastTime:  m.ForecastTime,
+			Category:      m.TableVersion,
+			Parameter:     m.Parameter,
+			LevelType:     m.LevelType,
+			Level:         float64(m.Level),
+			Grid:          gridString(fmt.Sprint(m.GridType), m.Grid),
+		}}, nil
+	}
+
+	msgs, err := grib2.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, len(msgs))
+	for i, m := range msgs {
+		entries[i] = Entry{
+			Edition:       2,
+			ReferenceTime: m.ReferenceTime,
+			ForecastTime:  m.ForecastTime,
+			Discipline:    m.Discipline,
+			Category:      m.Category,
+			Parameter:     m.Number,
+			LevelType:     m.FirstSurface.Type,
+			Level:         m.FirstSurface.Value,
+			Grid:          gridString(fmt.Sprintf("3.%d", m.GridTemplate), m.Grid),
+		}
+	}
+	return entries, nil
+}
+
+func gridString(template string, g grids.Grid) string {
+	return fmt.Sprintf("%s %dx%d", template, len(g.Longitudes()), len(g.Latitudes()))
+}
@@ -0,0 +1,219 @@
+package index_test
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+	"github.com/scorix/walg/pkg/grib/grib2"
+	"github.com/scorix/walg/pkg/grib/index"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var ref = time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+// countingReaderAt 记录读取的字节数
+type countingReaderAt struct {
+	*bytes.Reader
+	read int
+}
+
+func (r *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := r.Reader.ReadAt(p, off)
+	r.read += n
+	return n, err
+}
+
+// testFile 生成包含三个场的 GRIB2 文件，消息之间插入无关字节
+func testFile(t *testing.T) []byte {
+	t.Helper()
+
+	grid := latlon.NewLatLonGrid(-90, 90, 0, 359, 1, 1)
+	fields := []struct {
+		category, number uint8
+		surface          grib2.Surface
+		step             time.Duration
+	}{
+		{0, 0, grib2.Surface{Type: 103, Value: 2}, 0},
+		{0, 0, grib2.Surface{Type: 103, Value: 2}, 6 * time.Hour},
+		{2, 2, grib2.Surface{Type: 100, Value: 85000}, 6 * time.Hour},
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("junk")
+	for i, f := range fields {
+		values := make([]float64, grid.Size())
+		for j := range values {
+			values[j] = float64(i*1000 + j%1000)
+		}
+
+		err := grib2.Encode(&buf, &grib2.Field{
+			ReferenceTime: ref,
+			Category:      f.category,
+			Number:        f.number,
+			ForecastTime:  f.step,
+			FirstSurface:  f.surface,
+			SecondSurface: grib2.Surface{Type: 255, Value: math.NaN()},
+			Grid:          grid,
+			Values:        values,
+		}, grib2.Packing{Bits: 16})
+		require.NoError(t, err)
+		buf.WriteString("pad")
+	}
+
+	return buf.Bytes()
+}
+
+func TestBuild(t *testing.T) {
+	data := testFile(t)
+
+	ix, err := index.Build(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Len(t, ix.Entries, 3)
+
+	assert.EqualValues(t, 4, ix.Entries[0].Offset)
+	for i, e := range ix.Entries {
+		assert.Equal(t, i+1, e.Message)
+		assert.Equal(t, "GRIB", string(data[e.Offset:e.Offset+4]))
+		assert.Equal(t, "7777", string(data[e.Offset+e.Length-4:e.Offset+e.Length]))
+		assert.Equal(t, "3.0 360x181", e.Grid)
+	}
+
+	assert.Equal(t, "1:4:d=2024070100:TMP:2 m above ground:anl:", ix.Entries[0].String())
+	assert.Equal(t, "3:"+strconv.FormatInt(ix.Entries[2].Offset, 10)+":d=2024070100:UGRD:850 mb:6 hour fcst:", ix.Entries[2].String())
+
+	matched := ix.Match(regexp.MustCompile(`:TMP:2 m above ground:`))
+	assert.Len(t, matched, 2)
+
+	// 消息头声明的长度超出文件
+	_, err = index.Build(bytes.NewReader(data[:len(data)-10]), int64(len(data)-10))
+	assert.Error(t, err)
+
+	// 记录的长度超出文件
+	e := ix.Entries[0]
+	e.Length = 1 << 40
+	_, err = index.ReadEntry(bytes.NewReader(data), e)
+	assert.Error(t, err)
+}
+
+func TestIdx_RoundTrip(t *testing.T) {
+	data := testFile(t)
+
+	ix, err := index.Build(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, ix.WriteIdx(&buf))
+
+	parsed, err := index.ParseIdx(&buf)
+	require.NoError(t, err)
+	require.Len(t, parsed.Entries, 3)
+
+	for i, e := range parsed.Entries {
+		want := ix.Entries[i]
+		assert.Equal(t, want.Offset, e.Offset)
+		assert.Equal(t, want.ReferenceTime, e.ReferenceTime)
+		assert.Equal(t, want.ForecastTime, e.ForecastTime)
+		assert.Equal(t, want.Category, e.Category)
+		assert.Equal(t, want.Parameter, e.Parameter)
+		assert.Equal(t, want.LevelType, e.LevelType)
+		assert.Equal(t, want.Level, e.Level)
+	}
+
+	// 长度由下一条记录推算，包含消息之间的无关字节，最后一条未知
+	assert.Equal(t, ix.Entries[1].Offset-ix.Entries[0].Offset, parsed.Entries[0].Length)
+	assert.Zero(t, parsed.Entries[2].Length)
+
+	m, err := index.ReadGRIB2(bytes.NewReader(data), parsed.Entries[2])
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, m.Category)
+}
+
+func TestParseIdx_MultiField(t *testing.T) {
+	text := `1:0:d=2024070100:TMP:surface:anl:
+2.1:1000:d=2024070100:UGRD:10 m above ground:0-6 hour acc fcst:
+2.2:1000:d=2024070100:VGRD:10 m above ground:6 hour fcst:
+3:2500:d=2024070106:var0_19_0:level 200 0:90 min fcst:
+`
+	ix, err := index.ParseIdx(strings.NewReader(text))
+	require.NoError(t, err)
+	require.Len(t, ix.Entries, 4)
+
+	assert.EqualValues(t, 1000, ix.Entries[0].Length)
+	assert.EqualValues(t, 1500, ix.Entries[1].Length)
+	assert.EqualValues(t, 1500, ix.Entries[2].Length)
+	assert.Equal(t, 2, ix.Entries[2].Field)
+	assert.Equal(t, 6*time.Hour, ix.Entries[1].ForecastTime)
+	assert.EqualValues(t, 3, ix.Entries[2].Parameter)
+	assert.EqualValues(t, 19, ix.Entries[3].Category)
+	assert.EqualValues(t, 200, ix.Entries[3].LevelType)
+	assert.Equal(t, 90*time.Minute, ix.Entries[3].ForecastTime)
+
+	var buf bytes.Buffer
+	require.NoError(t, ix.WriteIdx(&buf))
+	// 累计时段只保留结束时刻
+	assert.Equal(t, strings.Replace(text, "0-6 hour acc fcst", "6 hour fcst", 1), buf.String())
+
+	_, err = index.ParseIdx(strings.NewReader("1:0:bad\n"))
+	assert.Error(t, err)
+}
+
+func TestBinary_RoundTrip(t *testing.T) {
+	data := testFile(t)
+
+	ix, err := index.Build(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, ix.WriteBinary(&buf))
+
+	read, err := index.ReadBinary(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, ix.Entries, read.Entries)
+
+	_, err = index.ReadBinary(strings.NewReader("NOTANIDX"))
+	assert.ErrorIs(t, err, index.ErrInvalidIndex)
+
+	// 记录数超出实际内容
+	corrupt := bytes.Clone(buf.Bytes())
+	corrupt[10], corrupt[11], corrupt[12], corrupt[13] = 0xff, 0xff, 0xff, 0xff
+	_, err = index.ReadBinary(bytes.NewReader(corrupt))
+	assert.ErrorIs(t, err, index.ErrInvalidIndex)
+
+	// 消息长度为负数
+	ix.Entries[0].Length = -1
+	buf.Reset()
+	require.NoError(t, ix.WriteBinary(&buf))
+	_, err = index.ReadBinary(bytes.NewReader(buf.Bytes()))
+	assert.ErrorIs(t, err, index.ErrInvalidIndex)
+}
+
+func TestNewReader(t *testing.T) {
+	data := testFile(t)
+
+	ix, err := index.Build(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	r := &countingReaderAt{Reader: bytes.NewReader(data)}
+	tmp := ix.Match(regexp.MustCompile(`:TMP:`))
+	reader, err := index.NewReader(r, tmp)
+	require.NoError(t, err)
+
+	// 只读取了第一条消息
+	assert.EqualValues(t, tmp[0].Length, r.read)
+	assert.Equal(t, 2, reader.Len())
+	assert.Equal(t, ref.Add(6*time.Hour), reader.TimeAxis().Time(1))
+
+	idx := grids.GridIndex(reader.Grid(), 0, 0, reader.ScanMode())
+	v, err := reader.ReadValueAt(1, idx)
+	require.NoError(t, err)
+	assert.InDelta(t, 1000+float64(idx%1000), v, 0.1)
+	assert.EqualValues(t, tmp[0].Length+tmp[1].Length, r.read)
+}
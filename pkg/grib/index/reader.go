@@ -0,0 +1,148 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/grib"
+	"github.com/scorix/walg/pkg/grib/grib1"
+	"github.com/scorix/walg/pkg/grib/grib2"
+)
+
+// maxMessageLength 允许的最大消息长度，超过该长度的记录视为索引或消息头损坏
+const maxMessageLength = 1 << 31
+
+// ReadEntry 只读取记录所在消息的字节，长度未知时先读取消息头
+// r 实现了 Size() int64（如 *io.SectionReader、*bytes.Reader）时，消息不能超出 r 的范围
+func ReadEntry(r io.ReaderAt, e Entry) ([]byte, error) {
+	length := e.Length
+	if length == 0 {
+		var err error
+		if _, length, err = messageHeader(r, e.Offset); err != nil {
+			return nil, err
+		}
+	}
+
+	limit := int64(maxMessageLength)
+	if s, ok := r.(interface{ Size() int64 }); ok {
+		limit = min(limit, s.Size()-e.Offset)
+	}
+	if e.Offset < 0 || length < 0 || length > limit {
+		return nil, fmt.Errorf("message %d at %d: invalid length %d", e.Message, e.Offset, length)
+	}
+
+	data := make([]byte, length)
+	if _, err := r.ReadAt(data, e.Offset); err != nil {
+		return nil, fmt.Errorf("read message %d at %d: %w", e.Message, e.Offset, err)
+	}
+	return data, nil
+}
+
+// ReadGRIB2 读取并解析记录对应的 GRIB2 场
+func ReadGRIB2(r io.ReaderAt, e Entry) (*grib2.Message, error) {
+	data, err := ReadEntry(r, e)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs, err := grib2.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	field := max(e.Field, 1)
+	if field > len(msgs) {
+		return nil, fmt.Errorf("message %d has %d fields, want field %d", e.Message, len(msgs), field)
+	}
+	return msgs[field-1], nil
+}
+
+// ReadGRIB1 读取并解析记录对应的 GRIB1 消息
+func ReadGRIB1(r io.ReaderAt, e Entry) (*grib1.Message, error) {
+	data, err := ReadEntry(r, e)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs, err := grib1.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("no GRIB1 message at %d", e.Offset)
+	}
+	return msgs[0], nil
+}
+
+// NewReader 将一组记录作为 ValueReader 使用，第 i 个时间步对应第 i 条记录
+// 创建时只读取第一条记录以确定网格，其余的场在首次使用时按字节范围读取；
+// 记录的网格描述必须相同，解码时网格或扫描方式不同会返回错误
+func NewReader(r io.ReaderAt, entries []Entry) (*grib.Reader, error) {
+	if len(entries) == 0 {
+		return nil, errors.New("no entries")
+	}
+
+	times := make([]time.Time, len(entries))
+	for i, e := range entries {
+		if e.Grid != entries[0].Grid {
+			return nil, fmt.Errorf("entry %d is on a different grid", i)
+		}
+		times[i] = e.ReferenceTime.Add(e.ForecastTime)
+	}
+
+	first, err := readField(r, entries[0])
+	if err != nil {
+		return nil, err
+	}
+
+	reader := grib.NewReader(first.grid, first.mode, times, func(step int) ([]float64, error) {
+		if step == 0 {
+			return first.values()
+		}
+
+		f, err := readField(r, entries[step])
+		if err != nil {
+			return nil, err
+		}
+		if f.mode != first.mode || f.grid.Size() != first.grid.Size() {
+			return nil, fmt.Errorf("entry %d is on a different grid", step)
+		}
+		return f.values()
+	})
+	return reader, nil
+}
+
+// field 一个已解析的场，GRIB1 与 GRIB2 共用
+type field struct {
+	grid   grids.Grid
+	mode   grids.ScanMode
+	values func() ([]float64, error)
+}
+
+// readField 读取记录对应的场，版本未知时（例如来自 .idx 文本）从消息头获取
+func readField(r io.ReaderAt, e Entry) (field, error) {
+	if e.Edition == 0 {
+		edition, length, err := messageHeader(r, e.Offset)
+		if err != nil {
+			return field{}, err
+		}
+		e.Edition, e.Length = edition, length
+	}
+
+	if e.Edition == 1 {
+		m, err := ReadGRIB1(r, e)
+		if err != nil {
+			return field{}, err
+		}
+		return field{m.Grid, m.ScanMode, m.Values}, nil
+	}
+
+	m, err := ReadGRIB2(r, e)
+	if err != nil {
+		return field{}, err
+	}
+	return field{m.Grid, m.ScanMode, m.Values}, nil
+}
@@ -0,0 +1,87 @@
+// Package bitio 按位读写大端序数据，供 GRIB 打包数据使用
+package bitio
+
+import "errors"
+
+// ErrShortData 数据不足
+var ErrShortData = errors.New("unexpected end of packed data")
+
+// ErrBitWidth 位宽超出 0 到 64 的范围
+var ErrBitWidth = errors.New("bit width out of range")
+
+// Reader 按位读取大端序数据
+type Reader struct {
+	data []byte
+	pos  int // 位偏移
+}
+
+// NewReader 创建按位读取器
+func NewReader(data []byte) *Reader {
+	return &Reader{data: data}
+}
+
+// Read 读取 n 位无符号整数，n 超出 0 到 64 的范围时返回 ErrBitWidth
+func (b *Reader) Read(n int) (uint64, error) {
+	if n < 0 || n > 64 {
+		return 0, ErrBitWidth
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	if b.pos+n > len(b.data)*8 {
+		return 0, ErrShortData
+	}
+
+	var v uint64
+	for n > 0 {
+		byteIdx, bitIdx := b.pos/8, b.pos%8
+		avail := 8 - bitIdx
+		take := min(avail, n)
+
+		chunk := uint64(b.data[byteIdx]>>(avail-take)) & (1<<take - 1)
+		v = v<<take | chunk
+
+		b.pos += take
+		n -= take
+	}
+
+	return v, nil
+}
+
+// Align 跳到下一个字节边界
+func (b *Reader) Align() {
+	b.pos = (b.pos + 7) / 8 * 8
+}
+
+// Seek 跳到第 pos 位
+func (b *Reader) Seek(pos int) {
+	b.pos = pos
+}
+
+// Writer 按位写入大端序数据
+type Writer struct {
+	data []byte
+	n    int // 已写入的位数
+}
+
+// Write 写入 v 的低 n 位
+func (b *Writer) Write(v uint64, n int) {
+	for n > 0 {
+		if b.n%8 == 0 {
+			b.data = append(b.data, 0)
+		}
+		free := 8 - b.n%8
+		take := min(free, n)
+
+		chunk := byte(v>>(n-take)) & byte(1<<take-1)
+		b.data[len(b.data)-1] |= chunk << (free - take)
+
+		b.n += take
+		n -= take
+	}
+}
+
+// Bytes 返回已写入的数据，最后一个字节不足的位为 0
+func (b *Writer) Bytes() []byte {
+	return b.data
+}
@@ -0,0 +1,121 @@
+// Package grib 包含 GRIB1 与 GRIB2 解码共用的读取器
+package grib
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+)
+
+// Reader 将一组同一网格上的场作为 ValueReader 使用，第 i 个时间步对应第 i 个场
+// 场在首次读取时解码并缓存，可以被多个 goroutine 同时使用
+type Reader struct {
+	grid   grids.Grid
+	mode   grids.ScanMode
+	times  grids.TimeSteps
+	decode func(step int) ([]float64, error)
+
+	mu     sync.Mutex
+	values [][]float64
+}
+
+// NewReader 创建读取器，times 为各个场的有效时间，decode 解码第 step 个场
+func NewReader(grid grids.Grid, mode grids.ScanMode, times []time.Time, decode func(step int) ([]float64, error)) *Reader {
+	return &Reader{
+		grid:   grid,
+		mode:   mode,
+		times:  times,
+		decode: decode,
+		values: make([][]float64, len(times)),
+	}
+}
+
+// Grid 返回场所在的网格
+func (r *Reader) Grid() grids.Grid {
+	return r.grid
+}
+
+// ScanMode 返回场的扫描方式
+func (r *Reader) ScanMode() grids.ScanMode {
+	return r.mode
+}
+
+// Len 返回场的个数
+func (r *Reader) Len() int {
+	return len(r.times)
+}
+
+// TimeAxis 返回由各个场的有效时间组成的时间轴
+func (r *Reader) TimeAxis() grids.TimeAxis {
+	return r.times
+}
+
+// field 返回第 timeStep 个场解码后的值
+func (r *Reader) field(timeStep int) ([]float64, error) {
+	if timeStep < 0 || timeStep >= len(r.values) {
+		return nil, fmt.Errorf("invalid time step: %d", timeStep)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.values[timeStep] == nil {
+		values, err := r.decode(timeStep)
+		if err != nil {
+			return nil, fmt.Errorf("decode message %d: %w", timeStep, err)
+		}
+		if len(values) != r.grid.Size() {
+			return nil, fmt.Errorf("message %d has %d values for %d grid points", timeStep, len(values), r.grid.Size())
+		}
+		r.values[timeStep] = values
+	}
+
+	return r.values[timeStep], nil
+}
+
+func (r *Reader) ReadValueAt(timeStep, gridIndex int) (float64, error) {
+	values, err := r.field(timeStep)
+	if err != nil {
+		return 0, err
+	}
+	if gridIndex < 0 || gridIndex >= len(values) {
+		return 0, fmt.Errorf("invalid grid index: %d", gridIndex)
+	}
+
+	return values[gridIndex], nil
+}
+
+func (r *Reader) ReadValuesAt(timeStep int, indices []int, dst []float64) error {
+	if len(dst) < len(indices) {
+		return fmt.Errorf("destination too short: %d < %d", len(dst), len(indices))
+	}
+
+	values, err := r.field(timeStep)
+	if err != nil {
+		return err
+	}
+
+	for i, idx := range indices {
+		if idx < 0 || idx >= len(values) {
+			return fmt.Errorf("invalid grid index: %d", idx)
+		}
+		dst[i] = values[idx]
+	}
+
+	return nil
+}
+
+func (r *Reader) ReadField(timeStep int, dst []float64) error {
+	values, err := r.field(timeStep)
+	if err != nil {
+		return err
+	}
+	if len(dst) < len(values) {
+		return fmt.Errorf("destination too short: %d < %d", len(dst), len(values))
+	}
+
+	copy(dst, values)
+	return nil
+}
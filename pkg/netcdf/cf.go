@@ -0,0 +1,248 @@
+package netcdf
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/gaussian"
+)
+
+// Conventions 写出文件的 CF 版本
+const Conventions = "CF-1.8"
+
+// defaultFloatFill NetCDF 单精度浮点数的默认填充值
+const defaultFloatFill = float32(9.96921e36)
+
+// int16Fill 打包为 int16 时的填充值，有效值范围为 [-32766, 32766]
+const int16Fill = int16(-32767)
+
+// Field 要写出的一个变量
+// Values 在 WriteGrid 中按 [时间步][网格索引] 组织，在 WriteTimeSeries 中按 [站点][时间步] 组织；
+// Pack 为 true 时按数据范围打包为 int16，否则写为单精度浮点数；缺测值为 NaN
+type Field struct {
+	Name         string
+	StandardName string
+	LongName     string
+	Units        string
+	Pack         bool
+	Values       [][]float64
+}
+
+// attributes 生成变量的 CF 属性和存储类型，打包参数由全部值的范围确定
+// clamp 将物理值限制在打包后的有效范围内，避免舍入误差使有效值打包为填充值
+func (f *Field) attributes() (t Type, attrs []Attribute, clamp func(float64) float64) {
+	if f.StandardName != "" {
+		attrs = append(attrs, NewAttribute("standard_name", f.StandardName))
+	}
+	if f.LongName != "" {
+		attrs = append(attrs, NewAttribute("long_name", f.LongName))
+	}
+	if f.Units != "" {
+		attrs = append(attrs, NewAttribute("units", f.Units))
+	}
+
+	if !f.Pack {
+		return Float, append(attrs, NewAttribute("_FillValue", defaultFloatFill)), func(x float64) float64 { return x }
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, values := range f.Values {
+		for _, x := range values {
+			if !math.IsNaN(x) {
+				lo, hi = math.Min(lo, x), math.Max(hi, x)
+			}
+		}
+	}
+
+	scale, offset := 1.0, 0.0
+	if lo <= hi {
+		offset = (lo + hi) / 2
+		if hi > lo {
+			scale = (hi - lo) / (2 * 32766)
+		}
+	}
+
+	limit := float64(-int16Fill - 1)
+	clamp = func(x float64) float64 {
+		return math.Max(offset-limit*scale, math.Min(offset+limit*scale, x))
+	}
+
+	return Short, append(attrs,
+		NewAttribute("scale_factor", scale),
+		NewAttribute("add_offset", offset),
+		NewAttribute("_FillValue", int16Fill),
+	), clamp
+}
+
+// timeUnits 选择能精确表示所有时间的最大单位，以第一个时间为参考时间
+func timeUnits(times []time.Time) (string, []float64, error) {
+	unit := time.Hour
+	for _, t := range times {
+		for t.Sub(times[0])%unit != 0 {
+			unit = map[time.Duration]time.Duration{time.Hour: time.Minute, time.Minute: time.Second}[unit]
+			if unit == 0 {
+				return "", nil, fmt.Errorf("time %s is not a whole second", t)
+			}
+		}
+	}
+
+	units, err := FormatTimeUnits(unit, times[0])
+	if err != nil {
+		return "", nil, err
+	}
+
+	values := make([]float64, len(times))
+	for i, t := range times {
+		values[i] = float64(t.Sub(times[0]) / unit)
+	}
+	return units, values, nil
+}
+
+// addTime 定义时间维度和坐标变量
+func addTime(w *Writer, times []time.Time) error {
+	if len(times) == 0 {
+		return fmt.Errorf("no time steps")
+	}
+	units, values, err := timeUnits(times)
+	if err != nil {
+		return err
+	}
+
+	w.AddDimension("time", len(times)).AddVariable("time", Double, []string{"time"}, values,
+		NewAttribute("standard_name", "time"),
+		NewAttribute("units", units),
+		NewAttribute("calendar", "standard"),
+		NewAttribute("axis", "T"),
+	)
+	return nil
+}
+
+// WriteGrid 将网格上的场写为 CF 格式，变量维度为 (time, latitude, longitude)
+// 数据按纬度从北到南、经度从西到东重新排列；规则高斯网格的纬度变量带有 gaussian 属性，值为 N
+func WriteGrid(out io.Writer, grid grids.Grid, mode grids.ScanMode, times []time.Time, fields ...Field) error {
+	lats, lons := grid.Latitudes(), grid.Longitudes()
+	nj, ni := len(lats), len(lons)
+
+	w := NewWriter().AddAttribute(NewAttribute("Conventions", Conventions))
+	if err := addTime(w, times); err != nil {
+		return err
+	}
+
+	latAttrs := []Attribute{
+		NewAttribute("standard_name", "latitude"),
+		NewAttribute("units", "degrees_north"),
+		NewAttribute("axis", "Y"),
+	}
+	if n, ok := gaussian.RegularNumber(grid); ok {
+		latAttrs = append(latAttrs, NewAttribute("gaussian", int32(n)))
+	}
+
+	w.AddDimension("latitude", nj).AddDimension("longitude", ni).
+		AddVariable("latitude", Double, []string{"latitude"}, lats, latAttrs...).
+		AddVariable("longitude", Double, []string{"longitude"}, lons,
+			NewAttribute("standard_name", "longitude"),
+			NewAttribute("units", "degrees_east"),
+			NewAttribute("axis", "X"),
+		)
+
+	// 网格索引到输出顺序的映射
+	order := make([]int, grid.Size())
+	for j := range nj {
+		for i := range ni {
+			order[j*ni+i] = grids.GridIndexFromIndices(grid, j, i, mode)
+		}
+	}
+
+	for _, f := range fields {
+		if len(f.Values) != len(times) {
+			return fmt.Errorf("field %s has %d time steps, want %d", f.Name, len(f.Values), len(times))
+		}
+
+		t, attrs, clamp := f.attributes()
+		values := make([]float64, 0, len(times)*grid.Size())
+		for step, field := range f.Values {
+			if len(field) != grid.Size() {
+				return fmt.Errorf("field %s has %d values at step %d for %d grid points", f.Name, len(field), step, grid.Size())
+			}
+			for _, idx := range order {
+				values = append(values, clamp(field[idx]))
+			}
+		}
+
+		w.AddVariable(f.Name, t, []string{"time", "latitude", "longitude"}, values, attrs...)
+	}
+
+	_, err := w.WriteTo(out)
+	return err
+}
+
+// Station 站点
+type Station struct {
+	ID       string
+	Lat, Lon float64
+}
+
+// WriteTimeSeries 将站点时间序列写为 CF 离散采样几何的 timeSeries 格式（正交多维表示）
+// 变量维度为 (station, time)，站点编号写入带 cf_role 属性的 station_name 变量
+func WriteTimeSeries(out io.Writer, stations []Station, times []time.Time, fields ...Field) error {
+	if len(stations) == 0 {
+		return fmt.Errorf("no stations")
+	}
+
+	w := NewWriter().AddAttribute(
+		NewAttribute("Conventions", Conventions),
+		NewAttribute("featureType", "timeSeries"),
+	)
+	if err := addTime(w, times); err != nil {
+		return err
+	}
+
+	ids := make([]string, len(stations))
+	lats := make([]float64, len(stations))
+	lons := make([]float64, len(stations))
+	for i, s := range stations {
+		ids[i], lats[i], lons[i] = s.ID, s.Lat, s.Lon
+	}
+	strlen := max(1, len(slices.MaxFunc(ids, func(a, b string) int { return len(a) - len(b) })))
+
+	w.AddDimension("station", len(stations)).AddDimension("name_strlen", strlen).
+		AddTextVariable("station_name", []string{"station", "name_strlen"}, ids,
+			NewAttribute("cf_role", "timeseries_id"),
+			NewAttribute("long_name", "station name"),
+		).
+		AddVariable("lat", Double, []string{"station"}, lats,
+			NewAttribute("standard_name", "latitude"),
+			NewAttribute("units", "degrees_north"),
+		).
+		AddVariable("lon", Double, []string{"station"}, lons,
+			NewAttribute("standard_name", "longitude"),
+			NewAttribute("units", "degrees_east"),
+		)
+
+	for _, f := range fields {
+		if len(f.Values) != len(stations) {
+			return fmt.Errorf("field %s has %d stations, want %d", f.Name, len(f.Values), len(stations))
+		}
+
+		t, attrs, clamp := f.attributes()
+		values := make([]float64, 0, len(stations)*len(times))
+		for i, series := range f.Values {
+			if len(series) != len(times) {
+				return fmt.Errorf("field %s has %d time steps at station %s, want %d", f.Name, len(series), stations[i].ID, len(times))
+			}
+			for _, x := range series {
+				values = append(values, clamp(x))
+			}
+		}
+
+		attrs = append(attrs, NewAttribute("coordinates", "time lat lon station_name"))
+		w.AddVariable(f.Name, t, []string{"station", "time"}, values, attrs...)
+	}
+
+	_, err := w.WriteTo(out)
+	return err
+}
@@ -0,0 +1,80 @@
+package netcdf
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/scorix/walg/pkg/cf"
+	"github.com/scorix/walg/pkg/geo/grids"
+)
+
+// isCoordinate 判断变量是否为纬度或经度坐标，见 cf.IsCoordinate
+func isCoordinate(v *Variable, axis string) bool {
+	standardName, _ := v.Attribute("standard_name")
+	units, _ := v.Attribute("units")
+	return cf.IsCoordinate(axis, v.Name, standardName.Text(), units.Text())
+}
+
+// coordinate 返回与维度同名的一维坐标变量
+func (f *File) coordinate(d Dimension) (*Variable, bool) {
+	v, ok := f.Variable(d.Name)
+	if !ok || len(v.Dimensions) != 1 || v.Dimensions[0].Name != d.Name {
+		return nil, false
+	}
+	return v, true
+}
+
+// Grid 根据变量最后两维的纬度、经度坐标变量创建网格，并推算数据对应的扫描方式
+// 最后两维为 (纬度, 经度) 时 i 方向连续，为 (经度, 纬度) 时 j 方向连续
+func (v *Variable) Grid() (grids.Grid, grids.ScanMode, error) {
+	n := len(v.Dimensions)
+	if n < 2 {
+		return nil, 0, fmt.Errorf("variable %s has fewer than 2 dimensions", v.Name)
+	}
+
+	y, okY := v.file.coordinate(v.Dimensions[n-2])
+	x, okX := v.file.coordinate(v.Dimensions[n-1])
+	if !okY || !okX {
+		return nil, 0, fmt.Errorf("variable %s has no coordinate variables for %s and %s", v.Name, v.Dimensions[n-2].Name, v.Dimensions[n-1].Name)
+	}
+
+	var mode grids.ScanMode
+	switch {
+	case isCoordinate(y, "latitude") && isCoordinate(x, "longitude"):
+	case isCoordinate(y, "longitude") && isCoordinate(x, "latitude"):
+		x, y = y, x
+		mode |= grids.ScanModeConsecutiveJ
+	default:
+		return nil, 0, fmt.Errorf("variable %s: last two dimensions are not latitude and longitude", v.Name)
+	}
+
+	lats, err := y.Values()
+	if err != nil {
+		return nil, 0, err
+	}
+	lons, err := x.Values()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	grid, m, err := GridFromCoordinates(lats, lons)
+	if err != nil {
+		return nil, 0, fmt.Errorf("variable %s: %w", v.Name, err)
+	}
+	return grid, mode | m, nil
+}
+
+// GridFromCoordinates 根据一维纬度、经度坐标创建网格，返回 i 方向连续时的扫描方式，见 cf.GridFromCoordinates
+// 不支持的坐标返回的错误同时匹配 ErrUnsupported 和 cf.ErrUnsupported
+func GridFromCoordinates(lats, lons []float64) (grids.Grid, grids.ScanMode, error) {
+	grid, mode, err := cf.GridFromCoordinates(lats, lons)
+	return grid, mode, cfError(err)
+}
+
+// cfError 为 cf 包不支持的约定加上 ErrUnsupported，保持 errors.Is(err, ErrUnsupported) 的行为
+func cfError(err error) error {
+	if errors.Is(err, cf.ErrUnsupported) && !errors.Is(err, ErrUnsupported) {
+		return fmt.Errorf("%w: %w", ErrUnsupported, err)
+	}
+	return err
+}
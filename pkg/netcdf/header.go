@@ -0,0 +1,276 @@
+package netcdf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// 文件头中的标签
+const (
+	tagAbsent    = 0x00
+	tagDimension = 0x0a
+	tagVariable  = 0x0b
+	tagAttribute = 0x0c
+)
+
+// streaming 记录数未知的流式文件
+const streaming = 0xffffffff
+
+// headerReader 顺序读取文件头，出错后的读取都返回零值，错误保存在 err 中
+type headerReader struct {
+	r       *bufio.Reader
+	version int
+	err     error
+}
+
+func (h *headerReader) read(n int) []byte {
+	if h.err != nil {
+		return make([]byte, n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(h.r, b); err != nil {
+		h.err = fmt.Errorf("read header: %w", err)
+	}
+	return b
+}
+
+func (h *headerReader) uint32() uint32 {
+	return binary.BigEndian.Uint32(h.read(4))
+}
+
+// nonNeg 读取非负整数，CDF-5 中为 64 位
+func (h *headerReader) nonNeg() int64 {
+	if h.version == 5 {
+		v := int64(binary.BigEndian.Uint64(h.read(8)))
+		if v < 0 && h.err == nil {
+			h.err = fmt.Errorf("%w: negative count", ErrNotNetCDF)
+		}
+		return v
+	}
+	return int64(h.uint32())
+}
+
+// offset 读取文件位置，CDF-1 中为 32 位
+func (h *headerReader) offset() int64 {
+	if h.version == 1 {
+		return int64(h.uint32())
+	}
+	return int64(binary.BigEndian.Uint64(h.read(8)))
+}
+
+// padded 读取 n 个字节以及补齐到 4 字节的填充
+func (h *headerReader) padded(n int64) []byte {
+	if n < 0 || n > 1<<30 {
+		if h.err == nil {
+			h.err = fmt.Errorf("%w: invalid length %d", ErrNotNetCDF, n)
+		}
+		return nil
+	}
+	b := h.read(int(n + (4-n%4)%4))
+	return b[:n]
+}
+
+func (h *headerReader) name() string {
+	return string(h.padded(h.nonNeg()))
+}
+
+// list 读取列表的标签和元素个数，列表为空时返回 0
+func (h *headerReader) list(tag uint32) int64 {
+	t, n := h.uint32(), h.nonNeg()
+	if h.err == nil && t != tag && (t != tagAbsent || n != 0) {
+		h.err = fmt.Errorf("%w: unexpected tag %#x", ErrNotNetCDF, t)
+	}
+	return n
+}
+
+func (h *headerReader) attributes() []Attribute {
+	n := h.list(tagAttribute)
+
+	var attrs []Attribute
+	for i := int64(0); i < n && h.err == nil; i++ {
+		name := h.name()
+		t := Type(h.uint32())
+		count := h.nonNeg()
+		if t.Size() == 0 {
+			if h.err == nil {
+				h.err = fmt.Errorf("%w: attribute %s has type %s", ErrUnsupported, name, t)
+			}
+			break
+		}
+		data := h.padded(count * int64(t.Size()))
+		if h.err != nil {
+			break
+		}
+		attrs = append(attrs, Attribute{Name: name, Type: t, Value: decodeValues(t, data)})
+	}
+	return attrs
+}
+
+// decodeValues 将大端序数据解码为对应类型的切片，Char 解码为去掉末尾空字符的字符串
+func decodeValues(t Type, data []byte) any {
+	n := len(data) / t.Size()
+	switch t {
+	case Char:
+		end := len(data)
+		for end > 0 && data[end-1] == 0 {
+			end--
+		}
+		return string(data[:end])
+	case Byte:
+		return decodeSlice(data, n, func(b []byte) int8 { return int8(b[0]) })
+	case UByte:
+		return decodeSlice(data, n, func(b []byte) uint8 { return b[0] })
+	case Short:
+		return decodeSlice(data, n, func(b []byte) int16 { return int16(binary.BigEndian.Uint16(b)) })
+	case UShort:
+		return decodeSlice(data, n, binary.BigEndian.Uint16)
+	case Int:
+		return decodeSlice(data, n, func(b []byte) int32 { return int32(binary.BigEndian.Uint32(b)) })
+	case UInt:
+		return decodeSlice(data, n, binary.BigEndian.Uint32)
+	case Int64:
+		return decodeSlice(data, n, func(b []byte) int64 { return int64(binary.BigEndian.Uint64(b)) })
+	case UInt64:
+		return decodeSlice(data, n, binary.BigEndian.Uint64)
+	case Float:
+		return decodeSlice(data, n, func(b []byte) float32 { return math.Float32frombits(binary.BigEndian.Uint32(b)) })
+	case Double:
+		return decodeSlice(data, n, func(b []byte) float64 { return math.Float64frombits(binary.BigEndian.Uint64(b)) })
+	default:
+		return nil
+	}
+}
+
+func decodeSlice[T any](data []byte, n int, decode func([]byte) T) []T {
+	out := make([]T, n)
+	size := len(data) / max(n, 1)
+	for i := range out {
+		out[i] = decode(data[i*size:])
+	}
+	return out
+}
+
+// decodeFloat64 将一个大端序值解码为 float64
+func decodeFloat64(t Type, b []byte) float64 {
+	switch t {
+	case Byte:
+		return float64(int8(b[0]))
+	case Char, UByte:
+		return float64(b[0])
+	case Short:
+		return float64(int16(binary.BigEndian.Uint16(b)))
+	case UShort:
+		return float64(binary.BigEndian.Uint16(b))
+	case Int:
+		return float64(int32(binary.BigEndian.Uint32(b)))
+	case UInt:
+		return float64(binary.BigEndian.Uint32(b))
+	case Int64:
+		return float64(int64(binary.BigEndian.Uint64(b)))
+	case UInt64:
+		return float64(binary.BigEndian.Uint64(b))
+	case Float:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	case Double:
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	default:
+		return math.NaN()
+	}
+}
+
+// parseHeader 解析文件头并计算各变量的数据位置
+func (f *File) parseHeader() error {
+	h := &headerReader{r: bufio.NewReader(io.NewSectionReader(f.r, 0, math.MaxInt64))}
+
+	magic := h.read(4)
+	if h.err != nil || string(magic[:3]) != "CDF" {
+		return ErrNotNetCDF
+	}
+	switch magic[3] {
+	case 1, 2, 5:
+		h.version = int(magic[3])
+	default:
+		return fmt.Errorf("%w: version %d", ErrNotNetCDF, magic[3])
+	}
+	f.Version = h.version
+
+	numRecs := h.nonNeg()
+	if numRecs == streaming && h.version != 5 {
+		return fmt.Errorf("%w: streaming record count", ErrUnsupported)
+	}
+	f.NumRecs = int(numRecs)
+
+	ndims := h.list(tagDimension)
+	for i := int64(0); i < ndims && h.err == nil; i++ {
+		d := Dimension{Name: h.name(), Len: int(h.nonNeg())}
+		if d.Len == 0 {
+			d.Len, d.Unlimited = f.NumRecs, true
+		}
+		f.Dimensions = append(f.Dimensions, d)
+	}
+
+	f.Attributes = h.attributes()
+
+	nvars := h.list(tagVariable)
+	for i := int64(0); i < nvars && h.err == nil; i++ {
+		v := &Variable{file: f, Name: h.name()}
+
+		n := h.nonNeg()
+		for j := int64(0); j < n && h.err == nil; j++ {
+			id := h.nonNeg()
+			if id >= int64(len(f.Dimensions)) {
+				return fmt.Errorf("%w: variable %s has invalid dimension id %d", ErrNotNetCDF, v.Name, id)
+			}
+			v.Dimensions = append(v.Dimensions, f.Dimensions[id])
+		}
+		v.Attributes = h.attributes()
+		v.Type = Type(h.uint32())
+		h.nonNeg() // vsize 对大变量不准确，由维度计算
+		v.begin = h.offset()
+
+		if h.err == nil && v.Type.Size() == 0 {
+			return fmt.Errorf("%w: variable %s has type %s", ErrUnsupported, v.Name, v.Type)
+		}
+		v.record = len(v.Dimensions) > 0 && v.Dimensions[0].Unlimited
+		v.initUnpack()
+		v.initDefaultFill()
+		f.Variables = append(f.Variables, v)
+	}
+
+	if h.err != nil {
+		return h.err
+	}
+
+	// 记录大小为所有记录变量补齐后的大小之和，只有一个记录变量时不补齐
+	var records []*Variable
+	for _, v := range f.Variables {
+		if v.record {
+			records = append(records, v)
+			f.recSize += padded(v.sliceSize())
+		}
+	}
+	if len(records) == 1 {
+		f.recSize = records[0].sliceSize()
+	}
+
+	return nil
+}
+
+// sliceSize 返回变量（记录变量为一条记录）的字节数
+func (v *Variable) sliceSize() int64 {
+	size := int64(v.Type.Size())
+	for i, d := range v.Dimensions {
+		if i == 0 && v.record {
+			continue
+		}
+		size *= int64(d.Len)
+	}
+	return size
+}
+
+func padded(n int64) int64 {
+	return n + (4-n%4)%4
+}
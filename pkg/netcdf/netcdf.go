@@ -0,0 +1,275 @@
+// Package netcdf 实现了 NetCDF 经典格式（CDF-1）、64 位偏移格式（CDF-2）和 64 位数据格式（CDF-5）的纯 Go 读写
+// 不支持基于 HDF5 的 NetCDF-4 格式；WriteGrid、WriteTimeSeries 按 CF 约定写出网格场和站点时间序列
+package netcdf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+var (
+	// ErrNotNetCDF 数据不是 NetCDF 经典格式
+	ErrNotNetCDF = errors.New("not a NetCDF classic file")
+	// ErrUnsupported 不支持的格式或特性
+	ErrUnsupported = errors.New("unsupported NetCDF feature")
+)
+
+// Type 变量和属性的数据类型（nc_type）
+type Type uint32
+
+const (
+	Byte   Type = 1
+	Char   Type = 2
+	Short  Type = 3
+	Int    Type = 4
+	Float  Type = 5
+	Double Type = 6
+	// 以下类型只在 CDF-5 中使用
+	UByte  Type = 7
+	UShort Type = 8
+	UInt   Type = 9
+	Int64  Type = 10
+	UInt64 Type = 11
+)
+
+// Size 返回一个值占用的字节数，未知类型返回 0
+func (t Type) Size() int {
+	switch t {
+	case Byte, Char, UByte:
+		return 1
+	case Short, UShort:
+		return 2
+	case Int, Float, UInt:
+		return 4
+	case Double, Int64, UInt64:
+		return 8
+	default:
+		return 0
+	}
+}
+
+func (t Type) String() string {
+	names := []string{"", "byte", "char", "short", "int", "float", "double", "ubyte", "ushort", "uint", "int64", "uint64"}
+	if int(t) < len(names) && t > 0 {
+		return names[t]
+	}
+	return fmt.Sprintf("type(%d)", uint32(t))
+}
+
+// Dimension 维度，Unlimited 为记录维度，长度为记录数
+type Dimension struct {
+	Name      string
+	Len       int
+	Unlimited bool
+}
+
+// Attribute 属性
+// Value 为 Char 类型时是 string，其他类型是对应的切片，例如 []int16、[]float32、[]float64
+type Attribute struct {
+	Name  string
+	Type  Type
+	Value any
+}
+
+// Text 返回字符属性的值，其他类型返回空字符串
+func (a Attribute) Text() string {
+	s, _ := a.Value.(string)
+	return s
+}
+
+// Float64s 将数值属性转换为 float64，字符属性返回 nil
+func (a Attribute) Float64s() []float64 {
+	switch v := a.Value.(type) {
+	case []int8:
+		return toFloat64s(v)
+	case []uint8:
+		return toFloat64s(v)
+	case []int16:
+		return toFloat64s(v)
+	case []uint16:
+		return toFloat64s(v)
+	case []int32:
+		return toFloat64s(v)
+	case []uint32:
+		return toFloat64s(v)
+	case []int64:
+		return toFloat64s(v)
+	case []uint64:
+		return toFloat64s(v)
+	case []float32:
+		return toFloat64s(v)
+	case []float64:
+		return slices.Clone(v)
+	default:
+		return nil
+	}
+}
+
+func toFloat64s[T int8 | uint8 | int16 | uint16 | int32 | uint32 | int64 | uint64 | float32](v []T) []float64 {
+	out := make([]float64, len(v))
+	for i, x := range v {
+		out[i] = float64(x)
+	}
+	return out
+}
+
+// attribute 按名称查找属性
+func attribute(attrs []Attribute, name string) (Attribute, bool) {
+	i := slices.IndexFunc(attrs, func(a Attribute) bool { return a.Name == name })
+	if i < 0 {
+		return Attribute{}, false
+	}
+	return attrs[i], true
+}
+
+// File 一个打开的 NetCDF 文件，数据在读取时按需通过 io.ReaderAt 获取
+type File struct {
+	// Version 1 为经典格式，2 为 64 位偏移格式，5 为 64 位数据格式
+	Version    int
+	NumRecs    int
+	Dimensions []Dimension
+	Attributes []Attribute
+	Variables  []*Variable
+
+	r       io.ReaderAt
+	recSize int64 // 一条记录中所有记录变量的字节数
+}
+
+// Open 解析文件头，r 在 File 使用期间必须保持可用
+func Open(r io.ReaderAt) (*File, error) {
+	f := &File{r: r}
+	if err := f.parseHeader(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Attribute 按名称查找全局属性
+func (f *File) Attribute(name string) (Attribute, bool) {
+	return attribute(f.Attributes, name)
+}
+
+// Variable 按名称查找变量
+func (f *File) Variable(name string) (*Variable, bool) {
+	i := slices.IndexFunc(f.Variables, func(v *Variable) bool { return v.Name == name })
+	if i < 0 {
+		return nil, false
+	}
+	return f.Variables[i], true
+}
+
+// Dimension 按名称查找维度
+func (f *File) Dimension(name string) (Dimension, bool) {
+	i := slices.IndexFunc(f.Dimensions, func(d Dimension) bool { return d.Name == name })
+	if i < 0 {
+		return Dimension{}, false
+	}
+	return f.Dimensions[i], true
+}
+
+// Variable 变量
+type Variable struct {
+	Name       string
+	Type       Type
+	Dimensions []Dimension
+	Attributes []Attribute
+
+	file   *File
+	begin  int64 // 数据起始位置，记录变量为第一条记录中的位置
+	record bool  // 第一维是否为记录维度
+
+	// 解包参数
+	scale, offset float64
+	fill          []float64
+}
+
+// Attribute 按名称查找变量属性
+func (v *Variable) Attribute(name string) (Attribute, bool) {
+	return attribute(v.Attributes, name)
+}
+
+// Shape 返回各维度的长度，记录维度的长度为记录数
+func (v *Variable) Shape() []int {
+	shape := make([]int, len(v.Dimensions))
+	for i, d := range v.Dimensions {
+		shape[i] = d.Len
+	}
+	if v.record {
+		shape[0] = v.file.NumRecs
+	}
+	return shape
+}
+
+// IsRecord 第一维是否为记录维度
+func (v *Variable) IsRecord() bool {
+	return v.record
+}
+
+// initUnpack 从属性中读取 scale_factor、add_offset、_FillValue 和 missing_value
+func (v *Variable) initUnpack() {
+	v.scale, v.offset = 1, 0
+	if a, ok := v.Attribute("scale_factor"); ok {
+		if s := a.Float64s(); len(s) > 0 {
+			v.scale = s[0]
+		}
+	}
+	if a, ok := v.Attribute("add_offset"); ok {
+		if s := a.Float64s(); len(s) > 0 {
+			v.offset = s[0]
+		}
+	}
+	for _, name := range []string{"_FillValue", "missing_value"} {
+		if a, ok := v.Attribute(name); ok {
+			v.fill = append(v.fill, a.Float64s()...)
+		}
+	}
+}
+
+// initDefaultFill 没有 _FillValue 属性时，把类型的默认填充值（NC_FILL_*）视为缺测
+// 读取文件时使用；写入时缺测值仍需显式的 _FillValue
+func (v *Variable) initDefaultFill() {
+	if _, ok := v.Attribute("_FillValue"); ok {
+		return
+	}
+	if fill, ok := defaultFill(v.Type); ok {
+		v.fill = append(v.fill, fill)
+	}
+}
+
+// defaultFill 返回类型的默认填充值
+// 与 NetCDF-C 库一致，byte 和 char 类型不使用默认填充值
+func defaultFill(t Type) (float64, bool) {
+	switch t {
+	case Short:
+		return -32767, true
+	case Int:
+		return -2147483647, true
+	case Float:
+		return float64(float32(9.9692099683868690e+36)), true
+	case Double:
+		return 9.9692099683868690e+36, true
+	case UByte:
+		return 255, true
+	case UShort:
+		return 65535, true
+	case UInt:
+		return 4294967295, true
+	case Int64:
+		return -9223372036854775806, true
+	case UInt64:
+		return 18446744073709551614, true
+	default:
+		return 0, false
+	}
+}
+
+// unpack 将存储值转换为物理值，填充值转换为 NaN
+func (v *Variable) unpack(raw float64) float64 {
+	if math.IsNaN(raw) || slices.Contains(v.fill, raw) {
+		return math.NaN()
+	}
+	return raw*v.scale + v.offset
+}
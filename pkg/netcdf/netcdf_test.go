@@ -0,0 +1,342 @@
+package netcdf_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/gaussian"
+	"github.com/scorix/walg/pkg/netcdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 以下辅助函数手工构造 NetCDF 经典格式文件
+
+type testAttr struct {
+	name  string
+	typ   netcdf.Type
+	value []byte
+}
+
+type testVar struct {
+	name    string
+	dims    []int
+	typ     netcdf.Type
+	attrs   []testAttr
+	data    []byte   // 非记录变量的数据
+	records [][]byte // 记录变量每条记录的数据
+}
+
+type testDim struct {
+	name string
+	len  int // 0 为记录维度
+}
+
+func be(v any) []byte {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.BigEndian, v)
+	return buf.Bytes()
+}
+
+func text(name, s string) testAttr { return testAttr{name, netcdf.Char, []byte(s)} }
+
+func pad(b []byte) []byte { return append(b, make([]byte, (4-len(b)%4)%4)...) }
+
+func buildFile(version byte, numRecs int, dims []testDim, gatts []testAttr, vars []testVar) []byte {
+	nonNeg := func(v int) []byte {
+		if version == 5 {
+			return be(uint64(v))
+		}
+		return be(uint32(v))
+	}
+	name := func(s string) []byte { return append(nonNeg(len(s)), pad([]byte(s))...) }
+	attrs := func(as []testAttr) []byte {
+		if len(as) == 0 {
+			return append(be(uint32(0)), nonNeg(0)...)
+		}
+		b := append(be(uint32(0x0c)), nonNeg(len(as))...)
+		for _, a := range as {
+			b = append(b, name(a.name)...)
+			b = append(b, be(uint32(a.typ))...)
+			b = append(b, nonNeg(len(a.value)/a.typ.Size())...)
+			b = append(b, pad(a.value)...)
+		}
+		return b
+	}
+
+	header := func(begins []int64, recSize int) []byte {
+		b := append([]byte("CDF"), version)
+		b = append(b, nonNeg(numRecs)...)
+		b = append(b, be(uint32(0x0a))...)
+		b = append(b, nonNeg(len(dims))...)
+		for _, d := range dims {
+			b = append(b, name(d.name)...)
+			b = append(b, nonNeg(d.len)...)
+		}
+		b = append(b, attrs(gatts)...)
+		b = append(b, be(uint32(0x0b))...)
+		b = append(b, nonNeg(len(vars))...)
+		for i, v := range vars {
+			b = append(b, name(v.name)...)
+			b = append(b, nonNeg(len(v.dims))...)
+			for _, d := range v.dims {
+				b = append(b, nonNeg(d)...)
+			}
+			b = append(b, attrs(v.attrs)...)
+			b = append(b, be(uint32(v.typ))...)
+			b = append(b, nonNeg(recSize)...)
+			if version == 1 {
+				b = append(b, be(uint32(begins[i]))...)
+			} else {
+				b = append(b, be(uint64(begins[i]))...)
+			}
+		}
+		return b
+	}
+
+	begins := make([]int64, len(vars))
+	off := int64(len(header(begins, 0)))
+
+	var body []byte
+	for i, v := range vars {
+		if v.records == nil {
+			begins[i] = off + int64(len(body))
+			body = append(body, pad(v.data)...)
+		}
+	}
+
+	recStart := off + int64(len(body))
+	var nrec int
+	for i, v := range vars {
+		if v.records != nil {
+			begins[i] = recStart + int64(nrec)
+			nrec += len(pad(v.records[0]))
+		}
+	}
+	for r := range numRecs {
+		for _, v := range vars {
+			if v.records != nil {
+				body = append(body, pad(v.records[r])...)
+			}
+		}
+	}
+
+	return append(header(begins, 0), body...)
+}
+
+var ref = time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+// era5File 模拟 ERA5 的 (time, latitude, longitude) 压缩存储
+func era5File(version byte) []byte {
+	lats := []float32{10, 5, 0}
+	lons := []float32{100, 101, 102, 103}
+
+	t2m := make([][]byte, 2)
+	for r := range t2m {
+		vals := make([]int16, 12)
+		for i := range vals {
+			vals[i] = int16(r*1000 + i*10)
+		}
+		vals[5] = -32767
+		t2m[r] = be(vals)
+	}
+
+	return buildFile(version, 2,
+		[]testDim{{"time", 0}, {"latitude", 3}, {"longitude", 4}},
+		[]testAttr{text("Conventions", "CF-1.6")},
+		[]testVar{
+			{name: "time", dims: []int{0}, typ: netcdf.Int, attrs: []testAttr{
+				text("units", "hours since 2024-07-01 00:00:00.0"), text("calendar", "gregorian"),
+			}, records: [][]byte{be(int32(0)), be(int32(6))}},
+			{name: "latitude", dims: []int{1}, typ: netcdf.Float, attrs: []testAttr{text("units", "degrees_north")}, data: be(lats)},
+			{name: "longitude", dims: []int{2}, typ: netcdf.Float, attrs: []testAttr{text("units", "degrees_east")}, data: be(lons)},
+			{name: "t2m", dims: []int{0, 1, 2}, typ: netcdf.Short, attrs: []testAttr{
+				{"scale_factor", netcdf.Double, be(0.01)},
+				{"add_offset", netcdf.Double, be(273.15)},
+				{"_FillValue", netcdf.Short, be(int16(-32767))},
+				text("units", "K"),
+			}, records: t2m},
+		})
+}
+
+func TestOpen(t *testing.T) {
+	for _, version := range []byte{1, 2, 5} {
+		data := era5File(version)
+
+		f, err := netcdf.Open(bytes.NewReader(data))
+		require.NoError(t, err, "version %d", version)
+
+		assert.Equal(t, int(version), f.Version)
+		assert.Equal(t, 2, f.NumRecs)
+		require.Len(t, f.Dimensions, 3)
+		assert.True(t, f.Dimensions[0].Unlimited)
+
+		conv, ok := f.Attribute("Conventions")
+		require.True(t, ok)
+		assert.Equal(t, "CF-1.6", conv.Text())
+
+		v, ok := f.Variable("t2m")
+		require.True(t, ok)
+		assert.True(t, v.IsRecord())
+		assert.Equal(t, []int{2, 3, 4}, v.Shape())
+
+		got, err := v.Value([]int{1, 2, 3})
+		require.NoError(t, err)
+		assert.InDelta(t, 273.15+0.01*(1000+110), got, 1e-9)
+
+		got, err = v.Value([]int{0, 1, 1})
+		require.NoError(t, err)
+		assert.True(t, math.IsNaN(got))
+
+		_, err = v.Value([]int{2, 0, 0})
+		assert.Error(t, err)
+
+		tv, _ := f.Variable("time")
+		times, err := tv.Times()
+		require.NoError(t, err)
+		assert.Equal(t, []time.Time{ref, ref.Add(6 * time.Hour)}, times)
+	}
+
+	_, err := netcdf.Open(bytes.NewReader([]byte("\x89HDF\r\n\x1a\n")))
+	assert.ErrorIs(t, err, netcdf.ErrNotNetCDF)
+}
+
+func TestReader(t *testing.T) {
+	f, err := netcdf.Open(bytes.NewReader(era5File(2)))
+	require.NoError(t, err)
+	v, _ := f.Variable("t2m")
+
+	r, err := netcdf.NewReader(v)
+	require.NoError(t, err)
+
+	dims := r.Dimensions()
+	require.Len(t, dims, 1)
+	assert.Equal(t, grids.DimTime, dims[0].Name)
+	assert.Equal(t, []float64{0, 6}, dims[0].Coords)
+	assert.Equal(t, ref.Add(6*time.Hour), r.TimeAxis().Time(1))
+
+	assert.Equal(t, grids.ScanModePositiveI|grids.ScanModeNegativeJ, r.ScanMode())
+	assert.Equal(t, []float64{10, 5, 0}, r.Grid().Latitudes())
+	assert.Equal(t, []float64{100, 101, 102, 103}, r.Grid().Longitudes())
+
+	// 网格索引与数据顺序一致
+	idx := grids.GridIndex(r.Grid(), 0, 101, r.ScanMode())
+	got, err := r.ReadValue([]int{1}, idx)
+	require.NoError(t, err)
+	assert.InDelta(t, 273.15+0.01*(1000+90), got, 1e-9)
+
+	field := make([]float64, r.Grid().Size())
+	require.NoError(t, r.ReadField([]int{0}, field))
+	assert.InDelta(t, 273.15+0.01*40, field[4], 1e-9)
+	assert.True(t, math.IsNaN(field[5]))
+
+	slice, err := grids.SliceReader(r, grids.DimTime, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, grids.ReaderTimeAxis(slice))
+}
+
+func TestGrid_ConsecutiveJAndGaussian(t *testing.T) {
+	g := gaussian.NewRegular(2)
+	lats := make([]float64, 4)
+	for i, lat := range g.Latitudes() {
+		lats[3-i] = lat // 南到北
+	}
+	lons := make([]float64, 8)
+	for i := range lons {
+		lons[i] = float64(i) * 45
+	}
+
+	// 数据为 (lon, lat)，按 lat 连续
+	values := make([]float64, 32)
+	for i := range values {
+		values[i] = float64(i)
+	}
+
+	data := buildFile(5, 0,
+		[]testDim{{"lat", 4}, {"lon", 8}},
+		nil,
+		[]testVar{
+			{name: "lat", dims: []int{0}, typ: netcdf.Double, data: be(lats)},
+			{name: "lon", dims: []int{1}, typ: netcdf.Double, data: be(lons)},
+			{name: "z", dims: []int{1, 0}, typ: netcdf.Double, data: be(values)},
+		})
+
+	f, err := netcdf.Open(bytes.NewReader(data))
+	require.NoError(t, err)
+	v, _ := f.Variable("z")
+
+	r, err := netcdf.NewReader(v)
+	require.NoError(t, err)
+	assert.Same(t, g, r.Grid())
+	assert.Equal(t, grids.ScanModeConsecutiveJ|grids.ScanModePositiveJ, r.ScanMode())
+
+	// 北端、经度 90 度：lon 下标 2，lat 下标 3（南到北）
+	idx := grids.GridIndex(r.Grid(), g.Latitudes()[0], 90, r.ScanMode())
+	got, err := r.ReadValue(nil, idx)
+	require.NoError(t, err)
+	assert.Equal(t, float64(2*4+3), got)
+}
+
+func TestDefaultFill(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := netcdf.NewWriter().
+		AddDimension("x", 2).
+		AddVariable("f", netcdf.Float, []string{"x"}, []float64{1, 9.9692099683868690e+36}).
+		AddVariable("s", netcdf.Short, []string{"x"}, []float64{1, -32767}).
+		AddVariable("b", netcdf.Byte, []string{"x"}, []float64{1, -127}).
+		AddVariable("i", netcdf.Int, []string{"x"}, []float64{1, -2147483647}, netcdf.NewAttribute("_FillValue", int32(-999))).
+		WriteTo(&buf)
+	require.NoError(t, err)
+
+	f, err := netcdf.Open(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	for name, missing := range map[string]bool{"f": true, "s": true, "b": false, "i": false} {
+		v, _ := f.Variable(name)
+		values, err := v.Values()
+		require.NoError(t, err, name)
+		assert.Equal(t, 1.0, values[0], name)
+		assert.Equal(t, missing, math.IsNaN(values[1]), name)
+	}
+}
+
+func TestGridFromCoordinates(t *testing.T) {
+	grid, mode, err := netcdf.GridFromCoordinates([]float64{-1, -0.5, 0}, []float64{359.5, 359, 358.5})
+	require.NoError(t, err)
+	assert.Equal(t, grids.ScanModeNegativeI|grids.ScanModePositiveJ, mode)
+	assert.Equal(t, []float64{0, -0.5, -1}, grid.Latitudes())
+	assert.Equal(t, []float64{358.5, 359, 359.5}, grid.Longitudes())
+
+	_, _, err = netcdf.GridFromCoordinates([]float64{0, 1, 3}, []float64{0, 1})
+	assert.ErrorIs(t, err, netcdf.ErrUnsupported)
+
+	_, _, err = netcdf.GridFromCoordinates([]float64{0, 1}, []float64{0, 2, 1})
+	assert.ErrorIs(t, err, netcdf.ErrUnsupported)
+}
+
+func TestParseTimeUnits(t *testing.T) {
+	tests := []struct {
+		units string
+		ref   time.Time
+		unit  time.Duration
+	}{
+		{"hours since 1900-01-01 00:00:00.0", time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC), time.Hour},
+		{"days since 2000-1-1", time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), 24 * time.Hour},
+		{"seconds since 1970-01-01T00:00:00Z", time.Unix(0, 0).UTC(), time.Second},
+		{"minutes since 2024-07-01 06:00 UTC", ref.Add(6 * time.Hour), time.Minute},
+	}
+
+	for _, tt := range tests {
+		got, unit, err := netcdf.ParseTimeUnits(tt.units)
+		require.NoError(t, err, tt.units)
+		assert.Equal(t, tt.ref, got, tt.units)
+		assert.Equal(t, tt.unit, unit, tt.units)
+	}
+
+	_, _, err := netcdf.ParseTimeUnits("K")
+	assert.Error(t, err)
+}
@@ -0,0 +1,119 @@
+package netcdf
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/scorix/walg/pkg/cf"
+	"github.com/scorix/walg/pkg/geo/grids"
+)
+
+// Reader 将一个变量作为多维读取器使用，变量的最后两维为纬度、经度
+// 其余维度按 cf.DimensionName 命名；
+// 有可解析的时间坐标时实现 grids.TimeAxisProvider
+type Reader struct {
+	variable *Variable
+	grid     grids.Grid
+	mode     grids.ScanMode
+	dims     []grids.Dimension
+	axis     grids.TimeAxis
+}
+
+var _ grids.MultiValueReader = (*Reader)(nil)
+
+// NewReader 创建变量的读取器
+func NewReader(v *Variable) (*Reader, error) {
+	grid, mode, err := v.Grid()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reader{variable: v, grid: grid, mode: mode}
+
+	shape := v.Shape()
+	for i, d := range v.Dimensions[:len(v.Dimensions)-2] {
+		dim := grids.Dimension{Name: cf.DimensionName(d.Name, ""), Size: shape[i]}
+
+		if c, ok := v.file.coordinate(d); ok {
+			axis, _ := c.Attribute("axis")
+			dim.Name = cf.DimensionName(d.Name, axis.Text())
+			if a, ok := c.Attribute("units"); ok {
+				dim.Units = a.Text()
+			}
+			if dim.Coords, err = c.Values(); err != nil {
+				return nil, err
+			}
+
+			if dim.Name == grids.DimTime {
+				if times, err := c.Times(); err == nil {
+					r.axis = grids.TimeSteps(times)
+				}
+			}
+		}
+
+		if slices.ContainsFunc(r.dims, func(o grids.Dimension) bool { return o.Name == dim.Name }) {
+			return nil, fmt.Errorf("variable %s has duplicate dimension %s", v.Name, dim.Name)
+		}
+		r.dims = append(r.dims, dim)
+	}
+
+	return r, nil
+}
+
+// Variable 返回读取的变量
+func (r *Reader) Variable() *Variable {
+	return r.variable
+}
+
+// Grid 返回由坐标变量创建的网格
+func (r *Reader) Grid() grids.Grid {
+	return r.grid
+}
+
+// ScanMode 返回数据对应的扫描方式
+func (r *Reader) ScanMode() grids.ScanMode {
+	return r.mode
+}
+
+func (r *Reader) Dimensions() []grids.Dimension {
+	return r.dims
+}
+
+// TimeAxis 返回时间坐标对应的时间轴，没有时间维度或无法解析时返回 nil
+func (r *Reader) TimeAxis() grids.TimeAxis {
+	return r.axis
+}
+
+func (r *Reader) ReadValue(index []int, gridIndex int) (float64, error) {
+	full, err := r.index(index, gridIndex)
+	if err != nil {
+		return 0, err
+	}
+	return r.variable.Value(full)
+}
+
+// ReadField 读取 index 处整个场的值，dst 的长度必须等于网格点数
+func (r *Reader) ReadField(index []int, dst []float64) error {
+	if len(dst) != r.grid.Size() {
+		return fmt.Errorf("dst length %d does not match grid size %d", len(dst), r.grid.Size())
+	}
+
+	full, err := r.index(index, 0)
+	if err != nil {
+		return err
+	}
+	return r.variable.read(full, dst)
+}
+
+// index 将维度索引和网格索引转换为变量的索引，数据按扫描方式排列，网格索引即最后两维的线性下标
+func (r *Reader) index(index []int, gridIndex int) ([]int, error) {
+	if len(index) != len(r.dims) {
+		return nil, fmt.Errorf("expected %d dimension indices, got %d", len(r.dims), len(index))
+	}
+	if gridIndex < 0 || gridIndex >= r.grid.Size() {
+		return nil, fmt.Errorf("invalid grid index: %d", gridIndex)
+	}
+
+	n := r.variable.Shape()[len(r.dims)+1]
+	return append(slices.Clone(index), gridIndex/n, gridIndex%n), nil
+}
@@ -0,0 +1,41 @@
+package netcdf
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/scorix/walg/pkg/cf"
+)
+
+// ParseTimeUnits 解析 CF 时间单位，例如 "hours since 1900-01-01 00:00:00"，见 cf.ParseTimeUnits
+// 返回参考时间（UTC）和一个单位对应的时长
+func ParseTimeUnits(units string) (time.Time, time.Duration, error) {
+	ref, unit, err := cf.ParseTimeUnits(units)
+	return ref, unit, cfError(err)
+}
+
+// FormatTimeUnits 生成 CF 时间单位，例如 FormatTimeUnits(time.Hour, ref) 返回 "hours since 2024-07-01 00:00:00"
+func FormatTimeUnits(unit time.Duration, ref time.Time) (string, error) {
+	units, err := cf.FormatTimeUnits(unit, ref)
+	return units, cfError(err)
+}
+
+// Times 将时间坐标变量的值按 units、calendar 属性转换为时间，见 cf.Times
+func (v *Variable) Times() ([]time.Time, error) {
+	units, ok := v.Attribute("units")
+	if !ok {
+		return nil, fmt.Errorf("variable %s has no units", v.Name)
+	}
+	calendar, _ := v.Attribute("calendar")
+
+	values, err := v.Values()
+	if err != nil {
+		return nil, err
+	}
+
+	times, err := cf.Times(values, units.Text(), calendar.Text())
+	if err != nil {
+		return nil, fmt.Errorf("variable %s: %w", v.Name, cfError(err))
+	}
+	return times, nil
+}
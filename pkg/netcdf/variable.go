@@ -0,0 +1,111 @@
+package netcdf
+
+import (
+	"fmt"
+	"math"
+)
+
+// Value 读取指定索引处的值，按 scale_factor、add_offset 解包，填充值返回 NaN
+func (v *Variable) Value(index []int) (float64, error) {
+	var dst [1]float64
+	if err := v.read(index, dst[:]); err != nil {
+		return math.NaN(), err
+	}
+	return dst[0], nil
+}
+
+// Values 读取变量的全部值并解包，按维度的行优先顺序排列
+func (v *Variable) Values() ([]float64, error) {
+	shape := v.Shape()
+	count := 1
+	for _, n := range shape {
+		count *= n
+	}
+	dst := make([]float64, count)
+	if count == 0 {
+		return dst, nil
+	}
+
+	if !v.record {
+		return dst, v.read(make([]int, len(shape)), dst)
+	}
+
+	per := count / shape[0]
+	index := make([]int, len(shape))
+	for rec := range shape[0] {
+		index[0] = rec
+		if err := v.read(index, dst[rec*per:(rec+1)*per]); err != nil {
+			return nil, err
+		}
+	}
+	return dst, nil
+}
+
+// read 从 index 开始读取 len(dst) 个连续的值并解包
+// 记录变量的连续范围不能跨越记录
+func (v *Variable) read(index []int, dst []float64) error {
+	shape := v.Shape()
+	if len(index) != len(shape) {
+		return fmt.Errorf("variable %s has %d dimensions, got %d indices", v.Name, len(shape), len(index))
+	}
+
+	// 记录内（或非记录变量中）的线性下标
+	var linear, inner int64 = 0, 1
+	for i := len(shape) - 1; i >= 0; i-- {
+		if index[i] < 0 || index[i] >= shape[i] {
+			return fmt.Errorf("index %d out of range for dimension %s of size %d", index[i], v.Dimensions[i].Name, shape[i])
+		}
+		if i == 0 && v.record {
+			break
+		}
+		linear += int64(index[i]) * inner
+		inner *= int64(shape[i])
+	}
+	if linear+int64(len(dst)) > inner {
+		return fmt.Errorf("read of %d values at %v exceeds variable %s", len(dst), index, v.Name)
+	}
+
+	size := int64(v.Type.Size())
+	off := v.begin + linear*size
+	if v.record {
+		off += int64(index[0]) * v.file.recSize
+	}
+
+	buf := make([]byte, int64(len(dst))*size)
+	if _, err := v.file.r.ReadAt(buf, off); err != nil {
+		return fmt.Errorf("read variable %s at %d: %w", v.Name, off, err)
+	}
+
+	for i := range dst {
+		dst[i] = v.unpack(decodeFloat64(v.Type, buf[int64(i)*size:]))
+	}
+	return nil
+}
+
+// Strings 读取字符变量，最后一维为字符串长度，去掉末尾的空字符
+func (v *Variable) Strings() ([]string, error) {
+	if v.Type != Char {
+		return nil, fmt.Errorf("variable %s has type %s, not char", v.Name, v.Type)
+	}
+
+	shape := v.Shape()
+	if len(shape) == 0 || v.record {
+		return nil, fmt.Errorf("%w: char variable %s with shape %v", ErrUnsupported, v.Name, shape)
+	}
+
+	count := 1
+	for _, n := range shape {
+		count *= n
+	}
+	buf := make([]byte, count)
+	if _, err := v.file.r.ReadAt(buf, v.begin); err != nil {
+		return nil, fmt.Errorf("read variable %s at %d: %w", v.Name, v.begin, err)
+	}
+
+	strlen := shape[len(shape)-1]
+	out := make([]string, 0, count/max(strlen, 1))
+	for i := 0; i+strlen <= len(buf) && strlen > 0; i += strlen {
+		out = append(out, decodeValues(Char, buf[i:i+strlen]).(string))
+	}
+	return out, nil
+}
@@ -0,0 +1,461 @@
+package netcdf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+// Writer 在内存中定义维度、属性和变量，由 WriteTo 一次写出 NetCDF 经典格式文件
+// 不支持记录维度，所有变量都按定义顺序连续存放
+type Writer struct {
+	version int
+	dims    []Dimension
+	attrs   []Attribute
+	vars    []*outputVariable
+	err     error
+}
+
+// outputVariable 待写出的变量，数据已编码为大端序
+type outputVariable struct {
+	Variable
+	dimIDs []int
+	data   []byte
+}
+
+// NewWriter 创建写入器，默认使用 64 位偏移格式（CDF-2）
+func NewWriter() *Writer {
+	return &Writer{version: 2}
+}
+
+// WithVersion 设置格式版本，1 为经典格式，2 为 64 位偏移格式，5 为 64 位数据格式
+func (w *Writer) WithVersion(version int) *Writer {
+	w.version = version
+	return w
+}
+
+// NewAttribute 创建属性，类型由 value 推断
+// value 可以是 string、数值或数值切片，不支持的类型在写出时返回错误
+func NewAttribute(name string, value any) Attribute {
+	switch v := value.(type) {
+	case int8:
+		value = []int8{v}
+	case uint8:
+		value = []uint8{v}
+	case int16:
+		value = []int16{v}
+	case uint16:
+		value = []uint16{v}
+	case int32:
+		value = []int32{v}
+	case int:
+		value = []int32{int32(v)}
+	case uint32:
+		value = []uint32{v}
+	case int64:
+		value = []int64{v}
+	case uint64:
+		value = []uint64{v}
+	case float32:
+		value = []float32{v}
+	case float64:
+		value = []float64{v}
+	}
+
+	return Attribute{Name: name, Type: typeOf(value), Value: value}
+}
+
+// typeOf 返回属性值对应的类型，不支持的类型返回 0
+func typeOf(value any) Type {
+	switch value.(type) {
+	case string:
+		return Char
+	case []int8:
+		return Byte
+	case []uint8:
+		return UByte
+	case []int16:
+		return Short
+	case []uint16:
+		return UShort
+	case []int32:
+		return Int
+	case []uint32:
+		return UInt
+	case []int64:
+		return Int64
+	case []uint64:
+		return UInt64
+	case []float32:
+		return Float
+	case []float64:
+		return Double
+	default:
+		return 0
+	}
+}
+
+// AddDimension 定义维度
+func (w *Writer) AddDimension(name string, n int) *Writer {
+	switch {
+	case n <= 0:
+		w.fail(fmt.Errorf("dimension %s has invalid length %d", name, n))
+	case slices.ContainsFunc(w.dims, func(d Dimension) bool { return d.Name == name }):
+		w.fail(fmt.Errorf("duplicate dimension %s", name))
+	default:
+		w.dims = append(w.dims, Dimension{Name: name, Len: n})
+	}
+	return w
+}
+
+// AddAttribute 添加全局属性
+func (w *Writer) AddAttribute(attrs ...Attribute) *Writer {
+	w.attrs = append(w.attrs, attrs...)
+	return w
+}
+
+// AddVariable 定义变量并写入数据，values 为物理值，按维度的行优先顺序排列
+// 有 scale_factor、add_offset 属性时按 (x - add_offset) / scale_factor 打包，整数类型四舍五入；
+// NaN 写为 _FillValue，没有 _FillValue 时浮点类型保留 NaN，整数类型返回错误
+func (w *Writer) AddVariable(name string, t Type, dims []string, values []float64, attrs ...Attribute) *Writer {
+	v, err := w.newVariable(name, t, dims, attrs)
+	if err != nil {
+		w.fail(err)
+		return w
+	}
+	if len(values) != v.count() {
+		w.fail(fmt.Errorf("variable %s has %d values for %d elements", name, len(values), v.count()))
+		return w
+	}
+	if t == Char {
+		w.fail(fmt.Errorf("variable %s: use AddTextVariable for char variables", name))
+		return w
+	}
+
+	v.initUnpack()
+	v.data = make([]byte, 0, len(values)*t.Size())
+	for i, x := range values {
+		raw, err := v.pack(x)
+		if err != nil {
+			w.fail(fmt.Errorf("variable %s at %d: %w", name, i, err))
+			return w
+		}
+		v.data = encodeFloat64(v.data, t, raw)
+	}
+
+	w.vars = append(w.vars, v)
+	return w
+}
+
+// AddTextVariable 定义字符变量，最后一维为字符串长度，过长的字符串返回错误
+func (w *Writer) AddTextVariable(name string, dims []string, values []string, attrs ...Attribute) *Writer {
+	v, err := w.newVariable(name, Char, dims, attrs)
+	if err != nil {
+		w.fail(err)
+		return w
+	}
+	if len(dims) == 0 {
+		w.fail(fmt.Errorf("variable %s has no string length dimension", name))
+		return w
+	}
+
+	strlen := v.Dimensions[len(dims)-1].Len
+	if len(values)*strlen != v.count() {
+		w.fail(fmt.Errorf("variable %s has %d strings for %d elements", name, len(values), v.count()/strlen))
+		return w
+	}
+
+	v.data = make([]byte, v.count())
+	for i, s := range values {
+		if len(s) > strlen {
+			w.fail(fmt.Errorf("variable %s: string %q longer than %d", name, s, strlen))
+			return w
+		}
+		copy(v.data[i*strlen:], s)
+	}
+
+	w.vars = append(w.vars, v)
+	return w
+}
+
+func (w *Writer) newVariable(name string, t Type, dims []string, attrs []Attribute) (*outputVariable, error) {
+	if t.Size() == 0 {
+		return nil, fmt.Errorf("variable %s has type %s", name, t)
+	}
+	if slices.ContainsFunc(w.vars, func(v *outputVariable) bool { return v.Name == name }) {
+		return nil, fmt.Errorf("duplicate variable %s", name)
+	}
+
+	v := &outputVariable{Variable: Variable{Name: name, Type: t, Attributes: attrs}}
+	for _, d := range dims {
+		id := slices.IndexFunc(w.dims, func(o Dimension) bool { return o.Name == d })
+		if id < 0 {
+			return nil, fmt.Errorf("variable %s uses unknown dimension %s", name, d)
+		}
+		v.dimIDs = append(v.dimIDs, id)
+		v.Dimensions = append(v.Dimensions, w.dims[id])
+	}
+	return v, nil
+}
+
+func (v *outputVariable) count() int {
+	n := 1
+	for _, d := range v.Dimensions {
+		n *= d.Len
+	}
+	return n
+}
+
+// pack 将物理值转换为存储值，是 unpack 的逆运算
+func (v *Variable) pack(x float64) (float64, error) {
+	if math.IsNaN(x) {
+		if len(v.fill) > 0 {
+			return v.fill[0], nil
+		}
+		if v.Type == Float || v.Type == Double {
+			return x, nil
+		}
+		return 0, errors.New("missing value without _FillValue")
+	}
+
+	raw := (x - v.offset) / v.scale
+	if v.Type != Float && v.Type != Double {
+		raw = math.Round(raw)
+		if lo, hi := typeRange(v.Type); raw < lo || raw > hi {
+			return 0, fmt.Errorf("value %g out of range for %s", x, v.Type)
+		}
+	}
+	return raw, nil
+}
+
+// typeRange 返回整数类型的取值范围
+func typeRange(t Type) (float64, float64) {
+	bits := float64(8 * t.Size())
+	switch t {
+	case UByte, UShort, UInt, UInt64:
+		return 0, math.Pow(2, bits) - 1
+	default:
+		return -math.Pow(2, bits-1), math.Pow(2, bits-1) - 1
+	}
+}
+
+// encodeFloat64 将值按类型编码为大端序，是 decodeFloat64 的逆运算
+func encodeFloat64(b []byte, t Type, x float64) []byte {
+	switch t {
+	case Byte:
+		return append(b, byte(int8(x)))
+	case UByte, Char:
+		return append(b, byte(x))
+	case Short:
+		return binary.BigEndian.AppendUint16(b, uint16(int16(x)))
+	case UShort:
+		return binary.BigEndian.AppendUint16(b, uint16(x))
+	case Int:
+		return binary.BigEndian.AppendUint32(b, uint32(int32(x)))
+	case UInt:
+		return binary.BigEndian.AppendUint32(b, uint32(x))
+	case Int64:
+		return binary.BigEndian.AppendUint64(b, uint64(int64(x)))
+	case UInt64:
+		return binary.BigEndian.AppendUint64(b, uint64(x))
+	case Float:
+		return binary.BigEndian.AppendUint32(b, math.Float32bits(float32(x)))
+	default:
+		return binary.BigEndian.AppendUint64(b, math.Float64bits(x))
+	}
+}
+
+// encodeAttribute 编码属性值，是 decodeValues 的逆运算
+func encodeAttribute(a Attribute) ([]byte, int, error) {
+	if s, ok := a.Value.(string); ok {
+		return []byte(s), len(s), nil
+	}
+
+	values := a.Float64s()
+	if a.Type == 0 || a.Type == Char || values == nil {
+		return nil, 0, fmt.Errorf("attribute %s has unsupported value %T", a.Name, a.Value)
+	}
+
+	var b []byte
+	for _, x := range values {
+		b = encodeFloat64(b, a.Type, x)
+	}
+	return b, len(values), nil
+}
+
+func (w *Writer) fail(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// headerWriter 顺序写入文件头，与 headerReader 对应
+type headerWriter struct {
+	b       []byte
+	version int
+}
+
+func (h *headerWriter) uint32(v uint32) {
+	h.b = binary.BigEndian.AppendUint32(h.b, v)
+}
+
+func (h *headerWriter) nonNeg(v int64) {
+	if h.version == 5 {
+		h.b = binary.BigEndian.AppendUint64(h.b, uint64(v))
+		return
+	}
+	h.uint32(uint32(v))
+}
+
+func (h *headerWriter) offset(v int64) {
+	if h.version == 1 {
+		h.uint32(uint32(v))
+		return
+	}
+	h.b = binary.BigEndian.AppendUint64(h.b, uint64(v))
+}
+
+// checkType 无符号整数和 64 位整数类型只能在 CDF-5 中使用
+func (h *headerWriter) checkType(t Type) error {
+	if h.version != 5 && t >= UByte {
+		return fmt.Errorf("%w: type %s requires version 5", ErrUnsupported, t)
+	}
+	return nil
+}
+
+func (h *headerWriter) padded(b []byte) {
+	h.b = append(h.b, b...)
+	h.b = append(h.b, make([]byte, (4-len(b)%4)%4)...)
+}
+
+func (h *headerWriter) name(s string) {
+	h.nonNeg(int64(len(s)))
+	h.padded([]byte(s))
+}
+
+func (h *headerWriter) attributes(attrs []Attribute) error {
+	if len(attrs) == 0 {
+		h.uint32(tagAbsent)
+		h.nonNeg(0)
+		return nil
+	}
+
+	h.uint32(tagAttribute)
+	h.nonNeg(int64(len(attrs)))
+	for _, a := range attrs {
+		if err := h.checkType(a.Type); err != nil {
+			return fmt.Errorf("attribute %s: %w", a.Name, err)
+		}
+		data, n, err := encodeAttribute(a)
+		if err != nil {
+			return err
+		}
+		h.name(a.Name)
+		h.uint32(uint32(a.Type))
+		h.nonNeg(int64(n))
+		h.padded(data)
+	}
+	return nil
+}
+
+// header 生成文件头，begins 为各变量的数据位置
+func (w *Writer) header(begins []int64) ([]byte, error) {
+	h := &headerWriter{version: w.version}
+	h.b = append([]byte("CDF"), byte(w.version))
+	h.nonNeg(0)
+
+	if len(w.dims) == 0 {
+		h.uint32(tagAbsent)
+		h.nonNeg(0)
+	} else {
+		h.uint32(tagDimension)
+		h.nonNeg(int64(len(w.dims)))
+		for _, d := range w.dims {
+			h.name(d.Name)
+			h.nonNeg(int64(d.Len))
+		}
+	}
+
+	if err := h.attributes(w.attrs); err != nil {
+		return nil, err
+	}
+
+	if len(w.vars) == 0 {
+		h.uint32(tagAbsent)
+		h.nonNeg(0)
+		return h.b, nil
+	}
+
+	h.uint32(tagVariable)
+	h.nonNeg(int64(len(w.vars)))
+	for i, v := range w.vars {
+		h.name(v.Name)
+		h.nonNeg(int64(len(v.dimIDs)))
+		for _, id := range v.dimIDs {
+			h.nonNeg(int64(id))
+		}
+		if err := h.attributes(v.Attributes); err != nil {
+			return nil, fmt.Errorf("variable %s: %w", v.Name, err)
+		}
+		if err := h.checkType(v.Type); err != nil {
+			return nil, fmt.Errorf("variable %s: %w", v.Name, err)
+		}
+		h.uint32(uint32(v.Type))
+
+		// 超过 32 位的 vsize 按规范写为最大值，读取时由维度计算
+		vsize := padded(int64(len(v.data)))
+		if h.version != 5 && vsize > math.MaxUint32 {
+			vsize = math.MaxUint32
+		}
+		h.nonNeg(vsize)
+		h.offset(begins[i])
+	}
+	return h.b, nil
+}
+
+// WriteTo 写出文件，定义过程中的第一个错误在这里返回
+func (w *Writer) WriteTo(out io.Writer) (int64, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	switch w.version {
+	case 1, 2, 5:
+	default:
+		return 0, fmt.Errorf("%w: version %d", ErrUnsupported, w.version)
+	}
+
+	// 文件头的长度与 begins 的值无关
+	begins := make([]int64, len(w.vars))
+	header, err := w.header(begins)
+	if err != nil {
+		return 0, err
+	}
+
+	off := int64(len(header))
+	for i, v := range w.vars {
+		begins[i] = off
+		off += padded(int64(len(v.data)))
+	}
+	if w.version == 1 && len(w.vars) > 0 && begins[len(w.vars)-1] > math.MaxInt32 {
+		return 0, fmt.Errorf("%w: file too large for classic format", ErrUnsupported)
+	}
+
+	if header, err = w.header(begins); err != nil {
+		return 0, err
+	}
+
+	bw := bufio.NewWriter(out)
+	n, _ := bw.Write(header)
+	written := int64(n)
+	for _, v := range w.vars {
+		n, _ := bw.Write(v.data)
+		written += int64(n)
+		n, _ = bw.Write(make([]byte, padded(int64(len(v.data)))-int64(len(v.data))))
+		written += int64(n)
+	}
+	return written, bw.Flush()
+}
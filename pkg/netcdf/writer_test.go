@@ -0,0 +1,202 @@
+package netcdf_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/geo/grids/gaussian"
+	"github.com/scorix/walg/pkg/geo/grids/latlon"
+	"github.com/scorix/walg/pkg/netcdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gridValues(grid grids.Grid, mode grids.ScanMode, steps int) [][]float64 {
+	out := make([][]float64, steps)
+	for s := range out {
+		out[s] = make([]float64, grid.Size())
+		for i := range out[s] {
+			lat, lon, _ := grids.GridPoint(grid, i, mode)
+			out[s][i] = 280 + lat/10 + lon/100 + float64(s)
+		}
+	}
+	out[0][1] = math.NaN()
+	return out
+}
+
+func TestWriter(t *testing.T) {
+	for _, version := range []int{1, 2, 5} {
+		var buf bytes.Buffer
+		_, err := netcdf.NewWriter().WithVersion(version).
+			AddDimension("x", 3).
+			AddAttribute(netcdf.NewAttribute("title", "test"), netcdf.NewAttribute("version", version)).
+			AddVariable("a", netcdf.Int, []string{"x"}, []float64{1, -2, math.NaN()}, netcdf.NewAttribute("_FillValue", int32(-999))).
+			AddVariable("b", netcdf.Byte, []string{"x"}, []float64{-1, 0, 1}).
+			WriteTo(&buf)
+		require.NoError(t, err)
+
+		f, err := netcdf.Open(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		assert.Equal(t, version, f.Version)
+
+		title, _ := f.Attribute("title")
+		assert.Equal(t, "test", title.Text())
+
+		a, _ := f.Variable("a")
+		values, err := a.Values()
+		require.NoError(t, err)
+		assert.Equal(t, []float64{1, -2}, values[:2])
+		assert.True(t, math.IsNaN(values[2]))
+
+		b, _ := f.Variable("b")
+		values, err = b.Values()
+		require.NoError(t, err)
+		assert.Equal(t, []float64{-1, 0, 1}, values)
+	}
+
+	tests := []struct {
+		name string
+		w    *netcdf.Writer
+	}{
+		{"unknown dimension", netcdf.NewWriter().AddVariable("a", netcdf.Float, []string{"x"}, nil)},
+		{"length mismatch", netcdf.NewWriter().AddDimension("x", 2).AddVariable("a", netcdf.Float, []string{"x"}, []float64{1})},
+		{"missing without fill", netcdf.NewWriter().AddDimension("x", 1).AddVariable("a", netcdf.Short, []string{"x"}, []float64{math.NaN()})},
+		{"out of range", netcdf.NewWriter().AddDimension("x", 1).AddVariable("a", netcdf.Byte, []string{"x"}, []float64{200})},
+		{"bad attribute", netcdf.NewWriter().AddAttribute(netcdf.NewAttribute("a", struct{}{}))},
+		{"bad version", netcdf.NewWriter().WithVersion(3)},
+		{"CDF-5 attribute", netcdf.NewWriter().AddAttribute(netcdf.NewAttribute("a", uint16(1)))},
+		{"CDF-5 variable", netcdf.NewWriter().WithVersion(1).AddDimension("x", 1).AddVariable("a", netcdf.Int64, []string{"x"}, []float64{1})},
+	}
+	for _, tt := range tests {
+		_, err := tt.w.WriteTo(&bytes.Buffer{})
+		assert.Error(t, err, tt.name)
+	}
+
+	// CDF-5 支持无符号整数和 64 位整数
+	_, err := netcdf.NewWriter().WithVersion(5).AddAttribute(netcdf.NewAttribute("a", uint16(1))).
+		AddDimension("x", 1).AddVariable("b", netcdf.Int64, []string{"x"}, []float64{1}).
+		WriteTo(&bytes.Buffer{})
+	assert.NoError(t, err)
+}
+
+func TestWriteGrid(t *testing.T) {
+	times := []time.Time{ref, ref.Add(90 * time.Minute)}
+
+	tests := []struct {
+		name string
+		grid grids.Grid
+		mode grids.ScanMode
+		pack bool
+	}{
+		{"latlon", latlon.NewLatLonGrid(-10, 10, 100, 110, 5, 2.5), grids.ScanModePositiveJ, false},
+		{"packed", latlon.NewLatLonGrid(-10, 10, 100, 110, 5, 2.5), grids.ScanModeConsecutiveJ | grids.ScanModeNegativeI, true},
+		{"gaussian", gaussian.NewRegular(4), 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := gridValues(tt.grid, tt.mode, len(times))
+
+			var buf bytes.Buffer
+			err := netcdf.WriteGrid(&buf, tt.grid, tt.mode, times, netcdf.Field{
+				Name:         "t2m",
+				StandardName: "air_temperature",
+				Units:        "K",
+				Pack:         tt.pack,
+				Values:       values,
+			})
+			require.NoError(t, err)
+
+			f, err := netcdf.Open(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+
+			v, _ := f.Variable("t2m")
+			units, _ := v.Attribute("units")
+			assert.Equal(t, "K", units.Text())
+
+			tv, _ := f.Variable("time")
+			tu, _ := tv.Attribute("units")
+			assert.Equal(t, "minutes since 2024-07-01 00:00:00", tu.Text())
+
+			r, err := netcdf.NewReader(v)
+			require.NoError(t, err)
+			assert.Same(t, tt.grid, r.Grid())
+			assert.Equal(t, times[1], r.TimeAxis().Time(1))
+
+			if tt.name == "gaussian" {
+				lat, _ := f.Variable("latitude")
+				n, ok := lat.Attribute("gaussian")
+				require.True(t, ok)
+				assert.Equal(t, []float64{4}, n.Float64s())
+			}
+
+			// 按原扫描方式的网格索引比较
+			tolerance := 1e-4
+			if tt.pack {
+				tolerance = 0.001
+			}
+			for step := range times {
+				for i := range tt.grid.Size() {
+					lat, lon, _ := grids.GridPoint(tt.grid, i, tt.mode)
+					got, err := r.ReadValue([]int{step}, grids.GridIndex(r.Grid(), lat, lon, r.ScanMode()))
+					require.NoError(t, err)
+
+					if math.IsNaN(values[step][i]) {
+						assert.True(t, math.IsNaN(got))
+						continue
+					}
+					assert.InDelta(t, values[step][i], got, tolerance)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteTimeSeries(t *testing.T) {
+	times := []time.Time{ref, ref.Add(time.Hour), ref.Add(2 * time.Hour)}
+	stations := []netcdf.Station{
+		{ID: "54511", Lat: 39.8, Lon: 116.47},
+		{ID: "BJ", Lat: 40, Lon: 116},
+	}
+
+	var buf bytes.Buffer
+	err := netcdf.WriteTimeSeries(&buf, stations, times, netcdf.Field{
+		Name:   "tas",
+		Units:  "K",
+		Pack:   true,
+		Values: [][]float64{{280, 281, math.NaN()}, {290, 291, 292}},
+	})
+	require.NoError(t, err)
+
+	f, err := netcdf.Open(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	ft, _ := f.Attribute("featureType")
+	assert.Equal(t, "timeSeries", ft.Text())
+
+	names, _ := f.Variable("station_name")
+	ids, err := names.Strings()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"54511", "BJ"}, ids)
+
+	v, _ := f.Variable("tas")
+	assert.Equal(t, []int{2, 3}, v.Shape())
+	values, err := v.Values()
+	require.NoError(t, err)
+	// 取值范围的两端不会被打包为填充值
+	assert.InDelta(t, 280, values[0], 1e-3)
+	assert.InDelta(t, 281, values[1], 1e-3)
+	assert.True(t, math.IsNaN(values[2]))
+	assert.InDelta(t, 292, values[5], 1e-3)
+
+	tv, _ := f.Variable("time")
+	got, err := tv.Times()
+	require.NoError(t, err)
+	assert.Equal(t, times, got)
+
+	err = netcdf.WriteTimeSeries(&buf, stations, times, netcdf.Field{Name: "tas", Values: [][]float64{{1, 2, 3}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,485 @@
+package zarr
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// defaultCacheSize 每个数组默认缓存的数据块个数
+const defaultCacheSize = 32
+
+// arrayMetadata .zarray 的内容
+type arrayMetadata struct {
+	Format     int             `json:"zarr_format"`
+	Shape      []int           `json:"shape"`
+	Chunks     []int           `json:"chunks"`
+	DType      json.RawMessage `json:"dtype"`
+	Compressor *struct {
+		ID string `json:"id"`
+	} `json:"compressor"`
+	FillValue json.RawMessage `json:"fill_value"`
+	Order     string          `json:"order"`
+	Filters   []any           `json:"filters"`
+	Separator string          `json:"dimension_separator"`
+}
+
+// dtype 数据类型，例如 "<f4"、">i2"、"|u1"
+type dtype struct {
+	kind  byte // 'f'、'i'、'u'、'b'
+	size  int
+	order binary.ByteOrder
+}
+
+func parseDType(raw json.RawMessage) (dtype, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return dtype{}, fmt.Errorf("%w: structured dtype %s", ErrUnsupported, raw)
+	}
+	if len(s) < 3 {
+		return dtype{}, fmt.Errorf("%w: dtype %q", ErrUnsupported, s)
+	}
+
+	d := dtype{kind: s[1], order: binary.LittleEndian}
+	if s[0] == '>' {
+		d.order = binary.BigEndian
+	}
+	size, err := strconv.Atoi(s[2:])
+	if err != nil {
+		return dtype{}, fmt.Errorf("%w: dtype %q", ErrUnsupported, s)
+	}
+	d.size = size
+
+	valid := map[byte][]int{'f': {4, 8}, 'i': {1, 2, 4, 8}, 'u': {1, 2, 4, 8}, 'b': {1}}
+	if !slices.Contains(valid[d.kind], d.size) || !strings.ContainsRune("<>|", rune(s[0])) {
+		return dtype{}, fmt.Errorf("%w: dtype %q", ErrUnsupported, s)
+	}
+	return d, nil
+}
+
+// decode 解码一个元素
+func (d dtype) decode(b []byte) float64 {
+	switch d.size {
+	case 1:
+		if d.kind == 'i' {
+			return float64(int8(b[0]))
+		}
+		return float64(b[0])
+	case 2:
+		v := d.order.Uint16(b)
+		if d.kind == 'i' {
+			return float64(int16(v))
+		}
+		return float64(v)
+	case 4:
+		v := d.order.Uint32(b)
+		switch d.kind {
+		case 'f':
+			return float64(math.Float32frombits(v))
+		case 'i':
+			return float64(int32(v))
+		}
+		return float64(v)
+	default:
+		v := d.order.Uint64(b)
+		switch d.kind {
+		case 'f':
+			return math.Float64frombits(v)
+		case 'i':
+			return float64(int64(v))
+		}
+		return float64(v)
+	}
+}
+
+// parseFillValue 解析 fill_value，null 时返回 false
+func parseFillValue(raw json.RawMessage) (float64, bool, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, false, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		switch s {
+		case "NaN":
+			return math.NaN(), true, nil
+		case "Infinity":
+			return math.Inf(1), true, nil
+		case "-Infinity":
+			return math.Inf(-1), true, nil
+		}
+		return 0, false, fmt.Errorf("%w: fill_value %q", ErrUnsupported, s)
+	}
+
+	var v float64
+	if err := json.Unmarshal(raw, &v); err != nil {
+		var b bool
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return 0, false, fmt.Errorf("fill_value %s: %w", raw, err)
+		}
+		if b {
+			v = 1
+		}
+	}
+	return v, true, nil
+}
+
+// Array Zarr 数组
+// 读取的值按 .zattrs 中的 scale_factor、add_offset 解包，fill_value 以及 _FillValue、missing_value 属性对应的值返回 NaN；
+// 解码后的数据块保存在 LRU 缓存中，可以被多个 goroutine 同时使用
+type Array struct {
+	Name       string
+	Shape      []int
+	Chunks     []int
+	Attributes map[string]any
+
+	fsys       fs.FS
+	dtype      dtype
+	order      byte // 'C' 或 'F'
+	separator  string
+	compressor string
+
+	fill          float64
+	hasFill       bool
+	missing       []float64
+	scale, offset float64
+
+	cache  *chunkCache
+	loader singleflight.Group
+}
+
+func (g *Group) openArray(name string) (*Array, error) {
+	var meta arrayMetadata
+	if err := g.readJSON(path.Join(name, ".zarray"), &meta); err != nil {
+		return nil, err
+	}
+	if meta.Format != 2 {
+		return nil, fmt.Errorf("%w: zarr_format %d", ErrNotZarr, meta.Format)
+	}
+	if len(meta.Shape) != len(meta.Chunks) {
+		return nil, fmt.Errorf("shape %v and chunks %v differ in dimensions", meta.Shape, meta.Chunks)
+	}
+	if slices.ContainsFunc(meta.Chunks, func(c int) bool { return c <= 0 }) {
+		return nil, fmt.Errorf("invalid chunks %v", meta.Chunks)
+	}
+	if len(meta.Filters) > 0 {
+		return nil, fmt.Errorf("%w: filters", ErrUnsupported)
+	}
+
+	a := &Array{
+		Name:      name,
+		Shape:     meta.Shape,
+		Chunks:    meta.Chunks,
+		fsys:      g.fsys,
+		order:     'C',
+		separator: ".",
+		scale:     1,
+		cache:     newChunkCache(defaultCacheSize),
+	}
+
+	var err error
+	if a.dtype, err = parseDType(meta.DType); err != nil {
+		return nil, err
+	}
+	if a.fill, a.hasFill, err = parseFillValue(meta.FillValue); err != nil {
+		return nil, err
+	}
+
+	switch meta.Order {
+	case "", "C":
+	case "F":
+		a.order = 'F'
+	default:
+		return nil, fmt.Errorf("%w: order %q", ErrUnsupported, meta.Order)
+	}
+	if meta.Separator != "" {
+		a.separator = meta.Separator
+	}
+
+	if meta.Compressor != nil {
+		switch meta.Compressor.ID {
+		case "zlib", "gzip":
+			a.compressor = meta.Compressor.ID
+		default:
+			return nil, fmt.Errorf("%w: compressor %s", ErrUnsupported, meta.Compressor.ID)
+		}
+	}
+
+	if err := g.readAttributes(name, &a.Attributes); err != nil {
+		return nil, err
+	}
+	if v, ok := a.Attributes["scale_factor"].(float64); ok {
+		a.scale = v
+	}
+	if v, ok := a.Attributes["add_offset"].(float64); ok {
+		a.offset = v
+	}
+	for _, key := range []string{"_FillValue", "missing_value"} {
+		if v, ok := a.Attributes[key].(float64); ok {
+			a.missing = append(a.missing, v)
+		}
+	}
+
+	// 单精度数组的元素解码后与 JSON 中的双精度填充值不完全相等，按单精度舍入后再比较
+	if a.dtype.kind == 'f' && a.dtype.size == 4 {
+		a.fill = float64(float32(a.fill))
+		for i, v := range a.missing {
+			a.missing[i] = float64(float32(v))
+		}
+	}
+
+	return a, nil
+}
+
+// WithCacheSize 设置缓存的数据块个数，n 小于 1 时按 1 处理，可以在读取的同时调用
+func (a *Array) WithCacheSize(n int) *Array {
+	a.cache.resize(n)
+	return a
+}
+
+// Attribute 返回字符串属性，不存在或不是字符串时返回空字符串
+func (a *Array) Attribute(name string) string {
+	s, _ := a.Attributes[name].(string)
+	return s
+}
+
+// Dimensions 返回 xarray 写入的 _ARRAY_DIMENSIONS 属性，没有时返回 nil
+func (a *Array) Dimensions() []string {
+	raw, ok := a.Attributes["_ARRAY_DIMENSIONS"].([]any)
+	if !ok {
+		return nil
+	}
+
+	dims := make([]string, len(raw))
+	for i, d := range raw {
+		dims[i], _ = d.(string)
+	}
+	return dims
+}
+
+// Value 读取指定索引处的值
+func (a *Array) Value(index []int) (float64, error) {
+	if len(index) != len(a.Shape) {
+		return math.NaN(), fmt.Errorf("array %s has %d dimensions, got %d indices", a.Name, len(a.Shape), len(index))
+	}
+
+	chunk := make([]int, len(index))
+	inner := make([]int, len(index))
+	for i, x := range index {
+		if x < 0 || x >= a.Shape[i] {
+			return math.NaN(), fmt.Errorf("index %d out of range for dimension %d of size %d", x, i, a.Shape[i])
+		}
+		chunk[i], inner[i] = x/a.Chunks[i], x%a.Chunks[i]
+	}
+
+	values, err := a.chunk(chunk)
+	if err != nil {
+		return math.NaN(), err
+	}
+	return values[a.offsetInChunk(inner)], nil
+}
+
+// Values 读取数组的全部值，按行优先顺序排列
+// 逐个数据块读取并复制到结果中，每个数据块只解码一次
+func (a *Array) Values() ([]float64, error) {
+	count := 1
+	for _, n := range a.Shape {
+		count *= n
+	}
+	out := make([]float64, count)
+	if count == 0 {
+		return out, nil
+	}
+
+	// 结果中各维的行优先步长
+	strides := make([]int, len(a.Shape))
+	stride := 1
+	for d := len(a.Shape) - 1; d >= 0; d-- {
+		strides[d] = stride
+		stride *= a.Shape[d]
+	}
+
+	// 各维的数据块个数
+	chunks := make([]int, len(a.Shape))
+	for d, n := range a.Shape {
+		chunks[d] = (n + a.Chunks[d] - 1) / a.Chunks[d]
+	}
+
+	chunk := make([]int, len(a.Shape))
+	inner := make([]int, len(a.Shape))
+	extent := make([]int, len(a.Shape))
+	for {
+		values, err := a.chunk(chunk)
+		if err != nil {
+			return nil, err
+		}
+
+		// 边缘数据块只复制数组范围内的部分
+		base := 0
+		for d := range chunk {
+			start := chunk[d] * a.Chunks[d]
+			extent[d] = min(a.Chunks[d], a.Shape[d]-start)
+			base += start * strides[d]
+			inner[d] = 0
+		}
+		for {
+			off := base
+			for d, x := range inner {
+				off += x * strides[d]
+			}
+			out[off] = values[a.offsetInChunk(inner)]
+
+			if !nextIndex(inner, extent) {
+				break
+			}
+		}
+
+		if !nextIndex(chunk, chunks) {
+			break
+		}
+	}
+	return out, nil
+}
+
+// nextIndex 按行优先顺序将下标递增到下一个位置，遍历结束时返回 false
+func nextIndex(index, limit []int) bool {
+	for d := len(index) - 1; d >= 0; d-- {
+		index[d]++
+		if index[d] < limit[d] {
+			return true
+		}
+		index[d] = 0
+	}
+	return false
+}
+
+// offsetInChunk 返回块内下标对应的元素位置
+func (a *Array) offsetInChunk(inner []int) int {
+	off, stride := 0, 1
+	if a.order == 'F' {
+		for i := range inner {
+			off += inner[i] * stride
+			stride *= a.Chunks[i]
+		}
+		return off
+	}
+
+	for i := len(inner) - 1; i >= 0; i-- {
+		off += inner[i] * stride
+		stride *= a.Chunks[i]
+	}
+	return off
+}
+
+// chunkKey 返回数据块的文件名，例如 "0.1.2" 或 "0/1/2"
+func (a *Array) chunkKey(chunk []int) string {
+	parts := make([]string, len(chunk))
+	for i, c := range chunk {
+		parts[i] = strconv.Itoa(c)
+	}
+	if len(parts) == 0 {
+		return "0"
+	}
+	return strings.Join(parts, a.separator)
+}
+
+// chunk 返回解码后的数据块，优先从缓存读取
+func (a *Array) chunk(chunk []int) ([]float64, error) {
+	key := a.chunkKey(chunk)
+	if values, ok := a.cache.get(key); ok {
+		return values, nil
+	}
+
+	v, err, _ := a.loader.Do(key, func() (any, error) {
+		values, err := a.loadChunk(key)
+		if err != nil {
+			return nil, err
+		}
+		a.cache.put(key, values)
+		return values, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]float64), nil
+}
+
+// loadChunk 读取、解压并解码数据块，不存在的数据块全部为填充值，即 NaN
+func (a *Array) loadChunk(key string) ([]float64, error) {
+	count := 1
+	for _, c := range a.Chunks {
+		count *= c
+	}
+	values := make([]float64, count)
+
+	data, err := fs.ReadFile(a.fsys, path.Join(a.Name, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		for i := range values {
+			values[i] = math.NaN()
+		}
+		return values, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err = a.decompress(data, count*a.dtype.size); err != nil {
+		return nil, fmt.Errorf("chunk %s: %w", key, err)
+	}
+	if len(data) != count*a.dtype.size {
+		return nil, fmt.Errorf("chunk %s has %d bytes, want %d", key, len(data), count*a.dtype.size)
+	}
+
+	for i := range values {
+		values[i] = a.unpack(a.dtype.decode(data[i*a.dtype.size:]))
+	}
+	return values, nil
+}
+
+// decompress 解压数据块，解压后超过 limit 字节时返回错误，避免损坏或恶意的数据块耗尽内存
+func (a *Array) decompress(data []byte, limit int) ([]byte, error) {
+	var r io.ReadCloser
+	var err error
+	switch a.compressor {
+	case "":
+		return data, nil
+	case "zlib":
+		r, err = zlib.NewReader(bytes.NewReader(data))
+	case "gzip":
+		r, err = gzip.NewReader(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	out, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > limit {
+		return nil, fmt.Errorf("decompressed chunk exceeds %d bytes", limit)
+	}
+	return out, nil
+}
+
+// unpack 将存储值转换为物理值，填充值转换为 NaN
+func (a *Array) unpack(raw float64) float64 {
+	if math.IsNaN(raw) || (a.hasFill && raw == a.fill) || slices.Contains(a.missing, raw) {
+		return math.NaN()
+	}
+	return raw*a.scale + a.offset
+}
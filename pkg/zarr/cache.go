@@ -0,0 +1,71 @@
+package zarr
+
+import (
+	"container/list"
+	"sync"
+)
+
+// chunkCache 解码后数据块的 LRU 缓存
+type chunkCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // 最近使用的在前
+	entries  map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key    string
+	values []float64
+}
+
+func newChunkCache(capacity int) *chunkCache {
+	return &chunkCache{
+		capacity: max(capacity, 1),
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+func (c *chunkCache) get(key string) ([]float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*cacheEntry).values, true
+}
+
+func (c *chunkCache) put(key string, values []float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*cacheEntry).values = values
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, values: values})
+	c.evict()
+}
+
+// resize 修改缓存容量，超出的数据块按最近最少使用的顺序淘汰
+func (c *chunkCache) resize(capacity int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.capacity = max(capacity, 1)
+	c.evict()
+}
+
+// evict 淘汰超出容量的数据块，调用时需持有锁
+func (c *chunkCache) evict() {
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
@@ -0,0 +1,148 @@
+package zarr
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/scorix/walg/pkg/cf"
+	"github.com/scorix/walg/pkg/geo/grids"
+)
+
+// Reader 将一个数组作为多维读取器使用，维度名来自 xarray 的 _ARRAY_DIMENSIONS 属性
+// 最后两维为纬度、经度，坐标数组与维度同名；其余维度按 cf.DimensionName 命名；
+// 有可解析的时间坐标时实现 grids.TimeAxisProvider
+type Reader struct {
+	array *Array
+	grid  grids.Grid
+	mode  grids.ScanMode
+	dims  []grids.Dimension
+	axis  grids.TimeAxis
+}
+
+var _ grids.MultiValueReader = (*Reader)(nil)
+
+// NewReader 创建组中数组 name 的读取器
+func NewReader(g *Group, name string) (*Reader, error) {
+	a, err := g.Array(name)
+	if err != nil {
+		return nil, err
+	}
+
+	names := a.Dimensions()
+	n := len(names)
+	if n < 2 || n != len(a.Shape) {
+		return nil, fmt.Errorf("array %s has dimensions %v, want at least latitude and longitude", name, names)
+	}
+
+	y, err := g.coordinate(names[n-2])
+	if err != nil {
+		return nil, err
+	}
+	x, err := g.coordinate(names[n-1])
+	if err != nil {
+		return nil, err
+	}
+
+	var mode grids.ScanMode
+	switch {
+	case isCoordinate(y, cf.Latitude) && isCoordinate(x, cf.Longitude):
+	case isCoordinate(y, cf.Longitude) && isCoordinate(x, cf.Latitude):
+		x, y = y, x
+		mode |= grids.ScanModeConsecutiveJ
+	default:
+		return nil, fmt.Errorf("array %s: last two dimensions are not latitude and longitude", name)
+	}
+
+	lats, err := y.Values()
+	if err != nil {
+		return nil, err
+	}
+	lons, err := x.Values()
+	if err != nil {
+		return nil, err
+	}
+	grid, m, err := cf.GridFromCoordinates(lats, lons)
+	if err != nil {
+		return nil, fmt.Errorf("array %s: %w", name, err)
+	}
+
+	r := &Reader{array: a, grid: grid, mode: mode | m}
+
+	for i, d := range names[:n-2] {
+		dim := grids.Dimension{Name: cf.DimensionName(d, ""), Size: a.Shape[i]}
+
+		if c, err := g.coordinate(d); err == nil && len(c.Shape) == 1 && c.Shape[0] == a.Shape[i] {
+			dim.Name = cf.DimensionName(d, c.Attribute("axis"))
+			dim.Units = c.Attribute("units")
+			if dim.Coords, err = c.Values(); err != nil {
+				return nil, err
+			}
+
+			if dim.Name == grids.DimTime {
+				if times, err := cf.Times(dim.Coords, dim.Units, c.Attribute("calendar")); err == nil {
+					r.axis = grids.TimeSteps(times)
+				}
+			}
+		}
+
+		if slices.ContainsFunc(r.dims, func(o grids.Dimension) bool { return o.Name == dim.Name }) {
+			return nil, fmt.Errorf("array %s has duplicate dimension %s", name, dim.Name)
+		}
+		r.dims = append(r.dims, dim)
+	}
+
+	return r, nil
+}
+
+// coordinate 返回与维度同名的一维坐标数组
+func (g *Group) coordinate(dim string) (*Array, error) {
+	a, err := g.Array(dim)
+	if err != nil {
+		return nil, fmt.Errorf("coordinate %s: %w", dim, err)
+	}
+	if len(a.Shape) != 1 {
+		return nil, fmt.Errorf("coordinate %s has %d dimensions", dim, len(a.Shape))
+	}
+	return a, nil
+}
+
+func isCoordinate(a *Array, axis string) bool {
+	return cf.IsCoordinate(axis, a.Name, a.Attribute("standard_name"), a.Attribute("units"))
+}
+
+// Array 返回读取的数组
+func (r *Reader) Array() *Array {
+	return r.array
+}
+
+// Grid 返回由坐标数组创建的网格
+func (r *Reader) Grid() grids.Grid {
+	return r.grid
+}
+
+// ScanMode 返回数据对应的扫描方式
+func (r *Reader) ScanMode() grids.ScanMode {
+	return r.mode
+}
+
+func (r *Reader) Dimensions() []grids.Dimension {
+	return r.dims
+}
+
+// TimeAxis 返回时间坐标对应的时间轴，没有时间维度或无法解析时返回 nil
+func (r *Reader) TimeAxis() grids.TimeAxis {
+	return r.axis
+}
+
+func (r *Reader) ReadValue(index []int, gridIndex int) (float64, error) {
+	if len(index) != len(r.dims) {
+		return 0, fmt.Errorf("expected %d dimension indices, got %d", len(r.dims), len(index))
+	}
+	if gridIndex < 0 || gridIndex >= r.grid.Size() {
+		return 0, fmt.Errorf("invalid grid index: %d", gridIndex)
+	}
+
+	// 数据按扫描方式排列，网格索引即最后两维的线性下标
+	n := r.array.Shape[len(r.dims)+1]
+	return r.array.Value(append(slices.Clone(index), gridIndex/n, gridIndex%n))
+}
@@ -0,0 +1,163 @@
+// Package zarr 实现了 Zarr v2 本地目录存储的纯 Go 读取
+// 支持 .zarray、.zattrs、.zgroup 和合并元数据 .zmetadata，压缩方式只支持标准库提供的 zlib 和 gzip
+package zarr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrNotZarr 目录不是 Zarr v2 存储
+	ErrNotZarr = errors.New("not a zarr v2 store")
+	// ErrUnsupported 不支持的数据类型、压缩方式或特性
+	ErrUnsupported = errors.New("unsupported zarr feature")
+)
+
+// Group Zarr 组，包含若干数组
+type Group struct {
+	Attributes map[string]any
+
+	fsys     fs.FS
+	metadata map[string]json.RawMessage // 合并元数据，没有时为 nil
+	names    []string
+
+	mu     sync.Mutex
+	arrays map[string]*Array
+}
+
+// Open 打开目录存储中的根组
+func Open(dir string) (*Group, error) {
+	return OpenFS(os.DirFS(dir))
+}
+
+// OpenFS 打开 fsys 中的根组，优先使用合并元数据 .zmetadata
+func OpenFS(fsys fs.FS) (*Group, error) {
+	g := &Group{fsys: fsys, arrays: make(map[string]*Array)}
+
+	if data, err := fs.ReadFile(fsys, ".zmetadata"); err == nil {
+		var consolidated struct {
+			Metadata map[string]json.RawMessage `json:"metadata"`
+		}
+		if err := json.Unmarshal(data, &consolidated); err != nil {
+			return nil, fmt.Errorf("parse .zmetadata: %w", err)
+		}
+		g.metadata = consolidated.Metadata
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	var group struct {
+		Format int `json:"zarr_format"`
+	}
+	if err := g.readJSON(".zgroup", &group); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotZarr
+		}
+		return nil, err
+	}
+	if group.Format != 2 {
+		return nil, fmt.Errorf("%w: zarr_format %d", ErrNotZarr, group.Format)
+	}
+
+	if err := g.readAttributes("", &g.Attributes); err != nil {
+		return nil, err
+	}
+
+	names, err := g.arrayNames()
+	if err != nil {
+		return nil, err
+	}
+	g.names = names
+
+	return g, nil
+}
+
+// readJSON 读取元数据文件，有合并元数据时从中读取
+func (g *Group) readJSON(key string, v any) error {
+	var data []byte
+	if g.metadata != nil {
+		raw, ok := g.metadata[key]
+		if !ok {
+			return fmt.Errorf("%s: %w", key, fs.ErrNotExist)
+		}
+		data = raw
+	} else {
+		var err error
+		if data, err = fs.ReadFile(g.fsys, key); err != nil {
+			return err
+		}
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parse %s: %w", key, err)
+	}
+	return nil
+}
+
+// readAttributes 读取 dir 下的 .zattrs，不存在时为空
+func (g *Group) readAttributes(dir string, attrs *map[string]any) error {
+	err := g.readJSON(path.Join(dir, ".zattrs"), attrs)
+	if errors.Is(err, fs.ErrNotExist) {
+		*attrs = map[string]any{}
+		return nil
+	}
+	return err
+}
+
+// arrayNames 列出组中的数组：有合并元数据时包含所有子组中的数组，否则只包含根组下的数组
+func (g *Group) arrayNames() ([]string, error) {
+	var names []string
+	if g.metadata != nil {
+		for key := range g.metadata {
+			if name, ok := strings.CutSuffix(key, "/.zarray"); ok {
+				names = append(names, name)
+			}
+		}
+	} else {
+		entries, err := fs.ReadDir(g.fsys, ".")
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if _, err := fs.Stat(g.fsys, path.Join(e.Name(), ".zarray")); e.IsDir() && err == nil {
+				names = append(names, e.Name())
+			}
+		}
+	}
+
+	slices.Sort(names)
+	return names, nil
+}
+
+// Arrays 返回组中数组的名称
+func (g *Group) Arrays() []string {
+	return slices.Clone(g.names)
+}
+
+// Array 打开数组，同一数组的多次调用返回同一个 *Array，共享数据块缓存
+func (g *Group) Array(name string) (*Array, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if a, ok := g.arrays[name]; ok {
+		return a, nil
+	}
+	if !slices.Contains(g.names, name) {
+		return nil, fmt.Errorf("array %s: %w", name, fs.ErrNotExist)
+	}
+
+	a, err := g.openArray(name)
+	if err != nil {
+		return nil, fmt.Errorf("array %s: %w", name, err)
+	}
+	g.arrays[name] = a
+	return a, nil
+}
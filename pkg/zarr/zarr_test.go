@@ -0,0 +1,369 @@
+package zarr_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/scorix/walg/pkg/geo/grids"
+	"github.com/scorix/walg/pkg/zarr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingFS 记录数据块的读取次数
+type countingFS struct {
+	fs.FS
+	chunks atomic.Int32
+}
+
+func (c *countingFS) Open(name string) (fs.File, error) {
+	if !strings.HasPrefix(filepath.Base(name), ".") {
+		c.chunks.Add(1)
+	}
+	return c.FS.Open(name)
+}
+
+func writeJSON(t *testing.T, path string, v any) {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+}
+
+func compress(t *testing.T, id string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	switch id {
+	case "zlib":
+		w := zlib.NewWriter(&buf)
+		_, _ = w.Write(data)
+		require.NoError(t, w.Close())
+	case "gzip":
+		w := gzip.NewWriter(&buf)
+		_, _ = w.Write(data)
+		require.NoError(t, w.Close())
+	default:
+		return data
+	}
+	return buf.Bytes()
+}
+
+// writeArray 写入一维或多维数组，chunks 为块文件名到未压缩数据的映射
+func writeArray(t *testing.T, dir, name string, meta map[string]any, attrs map[string]any, chunks map[string][]byte) {
+	t.Helper()
+	meta["zarr_format"] = 2
+	if _, ok := meta["order"]; !ok {
+		meta["order"] = "C"
+	}
+	writeJSON(t, filepath.Join(dir, name, ".zarray"), meta)
+	writeJSON(t, filepath.Join(dir, name, ".zattrs"), attrs)
+
+	var compressor string
+	if c, ok := meta["compressor"].(map[string]any); ok {
+		compressor = c["id"].(string)
+	}
+	for key, data := range chunks {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name, key), compress(t, compressor, data), 0o644))
+	}
+}
+
+func le(v any) []byte {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, v)
+	return buf.Bytes()
+}
+
+var ref = time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+// testStore 创建 (time=2, latitude=3, longitude=4) 的 t2m 数组，块大小 (1, 2, 3)
+// 值为 time*100 + lat*10 + lon，按 int16 存储，scale_factor 为 0.5
+func testStore(t *testing.T) string {
+	dir := t.TempDir()
+	writeJSON(t, filepath.Join(dir, ".zgroup"), map[string]any{"zarr_format": 2})
+	writeJSON(t, filepath.Join(dir, ".zattrs"), map[string]any{"title": "test"})
+
+	writeArray(t, dir, "time", map[string]any{
+		"shape": []int{2}, "chunks": []int{2}, "dtype": "<i8", "fill_value": nil, "compressor": nil,
+	}, map[string]any{
+		"_ARRAY_DIMENSIONS": []string{"time"}, "units": "hours since 2024-07-01", "calendar": "proleptic_gregorian",
+	}, map[string][]byte{"0": le([]int64{0, 6})})
+
+	writeArray(t, dir, "latitude", map[string]any{
+		"shape": []int{3}, "chunks": []int{3}, "dtype": ">f8", "fill_value": "NaN", "compressor": map[string]any{"id": "gzip"},
+	}, map[string]any{
+		"_ARRAY_DIMENSIONS": []string{"latitude"}, "units": "degrees_north",
+	}, map[string][]byte{"0": func() []byte {
+		var buf bytes.Buffer
+		_ = binary.Write(&buf, binary.BigEndian, []float64{40, 39.75, 39.5})
+		return buf.Bytes()
+	}()})
+
+	writeArray(t, dir, "longitude", map[string]any{
+		"shape": []int{4}, "chunks": []int{4}, "dtype": "<f4", "fill_value": "NaN", "compressor": nil,
+	}, map[string]any{
+		"_ARRAY_DIMENSIONS": []string{"longitude"}, "units": "degrees_east",
+	}, map[string][]byte{"0": le([]float32{116, 116.25, 116.5, 116.75})})
+
+	// 各个块中的值，边缘块按完整块大小存储
+	chunks := map[string][]byte{}
+	for ct := range 2 {
+		for cy := range 2 {
+			for cx := range 2 {
+				if ct == 1 && cy == 1 && cx == 1 {
+					continue // 缺失的块
+				}
+				vals := make([]int16, 6)
+				for j := range 2 {
+					for i := range 3 {
+						y, x := cy*2+j, cx*3+i
+						vals[j*3+i] = int16(2 * (ct*100 + y*10 + x))
+						if y == 0 && x == 0 && ct == 0 {
+							vals[j*3+i] = -32767
+						}
+					}
+				}
+				chunks[fmt.Sprintf("%d.%d.%d", ct, cy, cx)] = le(vals)
+			}
+		}
+	}
+	writeArray(t, dir, "t2m", map[string]any{
+		"shape": []int{2, 3, 4}, "chunks": []int{1, 2, 3}, "dtype": "<i2", "fill_value": -32767,
+		"compressor": map[string]any{"id": "zlib", "level": 1},
+	}, map[string]any{
+		"_ARRAY_DIMENSIONS": []string{"time", "latitude", "longitude"}, "scale_factor": 0.5, "add_offset": 0.0, "units": "K",
+	}, chunks)
+
+	return dir
+}
+
+func TestArray(t *testing.T) {
+	dir := testStore(t)
+	cfs := &countingFS{FS: os.DirFS(dir)}
+
+	g, err := zarr.OpenFS(cfs)
+	require.NoError(t, err)
+	assert.Equal(t, "test", g.Attributes["title"])
+	assert.Equal(t, []string{"latitude", "longitude", "t2m", "time"}, g.Arrays())
+
+	a, err := g.Array("t2m")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"time", "latitude", "longitude"}, a.Dimensions())
+
+	for ti := range 2 {
+		for y := range 3 {
+			for x := range 4 {
+				v, err := a.Value([]int{ti, y, x})
+				require.NoError(t, err)
+
+				switch {
+				case ti == 0 && y == 0 && x == 0, ti == 1 && y == 2 && x == 3:
+					assert.True(t, math.IsNaN(v), "%d %d %d", ti, y, x)
+				default:
+					assert.Equal(t, float64(ti*100+y*10+x), v, "%d %d %d", ti, y, x)
+				}
+			}
+		}
+	}
+
+	// 每个块（包括缺失的块）只打开一次
+	assert.EqualValues(t, 8, cfs.chunks.Load())
+
+	_, err = a.Value([]int{2, 0, 0})
+	assert.Error(t, err)
+
+	// 缓存容量为 1 时来回读取两个块需要重复解压
+	a.WithCacheSize(1)
+	cfs.chunks.Store(0)
+	for range 3 {
+		_, _ = a.Value([]int{0, 0, 0})
+		_, _ = a.Value([]int{1, 0, 0})
+	}
+	assert.EqualValues(t, 6, cfs.chunks.Load())
+
+	// 读取的同时修改缓存容量
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 50 {
+				a.WithCacheSize(i + j%3)
+				_, err := a.Value([]int{j % 2, 0, 0})
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Values 逐块读取，缓存容量为 1 时每个块也只打开一次
+	a.WithCacheSize(1)
+	cfs.chunks.Store(0)
+	values, err := a.Values()
+	require.NoError(t, err)
+	require.Len(t, values, 24)
+	assert.EqualValues(t, 8, cfs.chunks.Load())
+	for i, v := range values {
+		want, err := a.Value([]int{i / 12, i / 4 % 3, i % 4})
+		require.NoError(t, err)
+		if math.IsNaN(want) {
+			assert.True(t, math.IsNaN(v), "%d", i)
+		} else {
+			assert.Equal(t, want, v, "%d", i)
+		}
+	}
+}
+
+func TestArray_Float32Fill(t *testing.T) {
+	dir := t.TempDir()
+	writeJSON(t, filepath.Join(dir, ".zgroup"), map[string]any{"zarr_format": 2})
+	// netCDF 默认的单精度填充值，按 float32 存储后与 JSON 中的双精度值不相等
+	writeArray(t, dir, "sst", map[string]any{
+		"shape": []int{3}, "chunks": []int{3}, "dtype": "<f4", "fill_value": 9.96921e36, "compressor": nil,
+	}, map[string]any{"missing_value": -1e30}, map[string][]byte{"0": le([]float32{9.96921e36, 280.5, -1e30})})
+
+	g, err := zarr.Open(dir)
+	require.NoError(t, err)
+	a, err := g.Array("sst")
+	require.NoError(t, err)
+
+	values, err := a.Values()
+	require.NoError(t, err)
+	assert.True(t, math.IsNaN(values[0]))
+	assert.Equal(t, 280.5, values[1])
+	assert.True(t, math.IsNaN(values[2]))
+}
+
+func TestArray_FortranOrder(t *testing.T) {
+	dir := t.TempDir()
+	writeJSON(t, filepath.Join(dir, ".zgroup"), map[string]any{"zarr_format": 2})
+	// 3x3 数组，块大小 2x2，值为 row*10 + col，块内按列优先存储，边缘块按完整块大小存储
+	chunks := map[string][]byte{}
+	for cy := range 2 {
+		for cx := range 2 {
+			vals := make([]int8, 4)
+			for i := range 2 {
+				for j := range 2 {
+					vals[j*2+i] = int8((cy*2+i)*10 + cx*2 + j)
+				}
+			}
+			chunks[fmt.Sprintf("%d.%d", cy, cx)] = le(vals)
+		}
+	}
+	writeArray(t, dir, "grid", map[string]any{
+		"shape": []int{3, 3}, "chunks": []int{2, 2}, "dtype": "|i1", "fill_value": nil, "compressor": nil, "order": "F",
+	}, map[string]any{}, chunks)
+
+	g, err := zarr.Open(dir)
+	require.NoError(t, err)
+	a, err := g.Array("grid")
+	require.NoError(t, err)
+
+	values, err := a.Values()
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0, 1, 2, 10, 11, 12, 20, 21, 22}, values)
+}
+
+func TestReader(t *testing.T) {
+	g, err := zarr.Open(testStore(t))
+	require.NoError(t, err)
+
+	r, err := zarr.NewReader(g, "t2m")
+	require.NoError(t, err)
+
+	dims := r.Dimensions()
+	require.Len(t, dims, 1)
+	assert.Equal(t, grids.DimTime, dims[0].Name)
+	assert.Equal(t, ref.Add(6*time.Hour), r.TimeAxis().Time(1))
+	assert.Equal(t, grids.ScanModeNegativeJ, r.ScanMode())
+	assert.Equal(t, []float64{40, 39.75, 39.5}, r.Grid().Latitudes())
+
+	idx := grids.GridIndex(r.Grid(), 39.75, 116.5, r.ScanMode())
+	v, err := r.ReadValue([]int{1}, idx)
+	require.NoError(t, err)
+	assert.Equal(t, float64(100+12), v)
+
+	slice, err := grids.SliceReader(r, grids.DimTime, nil)
+	require.NoError(t, err)
+	interp := grids.NewGridInterpolator(slice, r.Grid(), r.ScanMode(), nil)
+	assert.NotNil(t, interp.TimeAxis())
+
+	_, err = zarr.NewReader(g, "time")
+	assert.Error(t, err)
+}
+
+func TestConsolidated(t *testing.T) {
+	dir := testStore(t)
+
+	// 合并元数据，并删除各个目录中的元数据文件
+	metadata := map[string]any{}
+	require.NoError(t, filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasPrefix(d.Name(), ".") {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		metadata[filepath.ToSlash(rel)] = json.RawMessage(data)
+		return os.Remove(path)
+	}))
+	writeJSON(t, filepath.Join(dir, ".zmetadata"), map[string]any{"metadata": metadata, "zarr_consolidated_format": 1})
+
+	g, err := zarr.Open(dir)
+	require.NoError(t, err)
+	assert.Len(t, g.Arrays(), 4)
+
+	r, err := zarr.NewReader(g, "t2m")
+	require.NoError(t, err)
+	v, err := r.ReadValue([]int{0}, 5)
+	require.NoError(t, err)
+	assert.Equal(t, float64(11), v)
+}
+
+func TestErrors(t *testing.T) {
+	_, err := zarr.Open(t.TempDir())
+	assert.ErrorIs(t, err, zarr.ErrNotZarr)
+
+	dir := t.TempDir()
+	writeJSON(t, filepath.Join(dir, ".zgroup"), map[string]any{"zarr_format": 2})
+	writeArray(t, dir, "blosc", map[string]any{
+		"shape": []int{1}, "chunks": []int{1}, "dtype": "<f4", "compressor": map[string]any{"id": "blosc"},
+	}, map[string]any{}, nil)
+	// 解压后远大于块大小的数据块
+	writeArray(t, dir, "bomb", map[string]any{
+		"shape": []int{2}, "chunks": []int{2}, "dtype": "<f4", "compressor": map[string]any{"id": "zlib"},
+	}, map[string]any{}, map[string][]byte{"0": make([]byte, 1<<20)})
+	writeArray(t, dir, "complex", map[string]any{
+		"shape": []int{1}, "chunks": []int{1}, "dtype": "<c8", "compressor": nil,
+	}, map[string]any{}, nil)
+
+	g, err := zarr.Open(dir)
+	require.NoError(t, err)
+
+	_, err = g.Array("blosc")
+	assert.ErrorIs(t, err, zarr.ErrUnsupported)
+	_, err = g.Array("complex")
+	assert.ErrorIs(t, err, zarr.ErrUnsupported)
+
+	bomb, err := g.Array("bomb")
+	require.NoError(t, err)
+	_, err = bomb.Value([]int{0})
+	assert.ErrorContains(t, err, "exceeds 8 bytes")
+	_, err = g.Array("missing")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}